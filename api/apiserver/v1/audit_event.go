@@ -0,0 +1,99 @@
+package v1
+
+import (
+	"encoding/json"
+	"time"
+
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"gorm.io/gorm"
+)
+
+// AuditChange is the redacted value of a field before and after a mutating request.
+type AuditChange struct {
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// AuditEvent records a mutating request against the iam apiserver. It is also used as gorm model.
+type AuditEvent struct {
+	ID uint64 `json:"id,omitempty" gorm:"primary_key;AUTO_INCREMENT;column:id"`
+
+	// Time the request was received.
+	Timestamp time.Time `json:"timestamp" gorm:"column:timestamp"`
+
+	// Level the event was recorded at, see audit.Level.
+	Level string `json:"level" gorm:"column:level"`
+
+	// RequestID is the X-Request-ID of the request.
+	RequestID string `json:"requestID" gorm:"column:requestID"`
+
+	// Username is the identity the request was handled as.
+	Username string `json:"username" gorm:"column:username"`
+
+	// Operator is the authenticated user when it differs from Username, e.g. an
+	// impersonating administrator or the owner of a service account.
+	Operator string `json:"operator,omitempty" gorm:"column:operator"`
+
+	ClientIP string `json:"clientIP" gorm:"column:clientIP"`
+
+	// Verb is one of create, update, patch and delete.
+	Verb string `json:"verb" gorm:"column:verb"`
+
+	// Kind of the resource, e.g. users, secrets or policies.
+	Kind string `json:"kind" gorm:"column:kind"`
+
+	// Name of the resource, comma separated for collection requests.
+	Name string `json:"name,omitempty" gorm:"column:name"`
+
+	// Owner of a per-user resource like secrets and policies.
+	Owner string `json:"owner,omitempty" gorm:"column:owner"`
+
+	// Subresource the request was sent to, e.g. change-password or members.
+	Subresource string `json:"subresource,omitempty" gorm:"column:subresource"`
+
+	// HTTPStatus and Code are the http status and the business code of the response.
+	HTTPStatus int    `json:"httpStatus"        gorm:"column:httpStatus"`
+	Code       int    `json:"code"              gorm:"column:code"`
+	Message    string `json:"message,omitempty" gorm:"column:message"`
+
+	// Diff holds the changed fields keyed by their dotted json path, only recorded at diff level.
+	Diff       map[string]AuditChange `json:"diff,omitempty" gorm:"-"`
+	DiffShadow string                 `json:"-"              gorm:"column:diffShadow"`
+}
+
+// AuditEventList is the whole list of all audit events which have been stored in stroage.
+type AuditEventList struct {
+	// Standard list metadata.
+	metav1.ListMeta `json:",inline"`
+
+	// List of audit events.
+	Items []*AuditEvent `json:"items"`
+}
+
+// TableName maps to mysql table name.
+func (e *AuditEvent) TableName() string {
+	return "audit_event"
+}
+
+// BeforeCreate run before create database record.
+func (e *AuditEvent) BeforeCreate(tx *gorm.DB) (err error) {
+	if len(e.Diff) == 0 {
+		e.DiffShadow = ""
+
+		return nil
+	}
+
+	diff, err := json.Marshal(e.Diff)
+	e.DiffShadow = string(diff)
+
+	return err
+}
+
+// AfterFind run after find to unmarshal a diff shadow string into the diff map.
+func (e *AuditEvent) AfterFind(tx *gorm.DB) (err error) {
+	if e.DiffShadow == "" {
+		return nil
+	}
+
+	return json.Unmarshal([]byte(e.DiffShadow), &e.Diff)
+}
@@ -0,0 +1,87 @@
+package v1
+
+import (
+	"encoding/json"
+	"time"
+
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/pkg/idutil"
+	"gorm.io/gorm"
+)
+
+// GroupSubjectPrefix prefixes the name of a group when it is used as a ladon subject.
+const GroupSubjectPrefix = "group:"
+
+// Group represents a group restful resource, a named set of users which policy
+// subjects can refer to as `group:<name>`. It is also used as gorm model.
+type Group struct {
+	// Standard object's metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Description string `json:"description" gorm:"column:description" validate:"description"`
+
+	// Usernames of the group members, will not be stored in the group table.
+	Members []string `json:"members,omitempty" gorm:"-" validate:"omitempty"`
+}
+
+// GroupList is the whole list of all groups which have been stored in stroage.
+type GroupList struct {
+	// Standard list metadata.
+	metav1.ListMeta `json:",inline"`
+
+	// List of groups.
+	Items []*Group `json:"items"`
+}
+
+// GroupMember records the membership of an user in a group. It is only used as gorm model.
+type GroupMember struct {
+	ID        uint64    `json:"-" gorm:"primary_key;AUTO_INCREMENT;column:id"`
+	GroupName string    `json:"-" gorm:"column:groupName"`
+	Username  string    `json:"-" gorm:"column:username"`
+	CreatedAt time.Time `json:"-" gorm:"column:createdAt"`
+}
+
+// GroupSubject returns the ladon subject matching the members of group name.
+func GroupSubject(name string) string {
+	return GroupSubjectPrefix + name
+}
+
+// TableName maps to mysql table name.
+func (g *Group) TableName() string {
+	return "user_group"
+}
+
+// TableName maps to mysql table name.
+func (m *GroupMember) TableName() string {
+	return "group_member"
+}
+
+// BeforeCreate run before create database record.
+func (g *Group) BeforeCreate(tx *gorm.DB) (err error) {
+	g.ExtendShadow = g.Extend.String()
+
+	return
+}
+
+// AfterCreate run after create database record.
+func (g *Group) AfterCreate(tx *gorm.DB) (err error) {
+	g.InstanceID = idutil.GetInstanceID(g.ID, "group-")
+
+	return tx.Save(g).Error
+}
+
+// BeforeUpdate run before update database record.
+func (g *Group) BeforeUpdate(tx *gorm.DB) (err error) {
+	g.ExtendShadow = g.Extend.String()
+
+	return err
+}
+
+// AfterFind run after find to unmarshal a extend shadown string into metav1.Extend struct.
+func (g *Group) AfterFind(tx *gorm.DB) (err error) {
+	if err := json.Unmarshal([]byte(g.ExtendShadow), &g.Extend); err != nil {
+		return err
+	}
+
+	return nil
+}
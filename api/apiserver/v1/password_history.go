@@ -0,0 +1,16 @@
+package v1
+
+import "time"
+
+// PasswordHistory records a password hash previously used by a user. It is only used as gorm model.
+type PasswordHistory struct {
+	ID        uint64    `json:"-" gorm:"primary_key;AUTO_INCREMENT;column:id"`
+	Username  string    `json:"-" gorm:"column:username"`
+	Password  string    `json:"-" gorm:"column:password"`
+	CreatedAt time.Time `json:"-" gorm:"column:createdAt"`
+}
+
+// TableName maps to mysql table name.
+func (p *PasswordHistory) TableName() string {
+	return "password_history"
+}
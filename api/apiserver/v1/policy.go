@@ -38,6 +38,24 @@ type PolicyList struct {
 	Items []*Policy `json:"items"`
 }
 
+// PolicyApplyOptions is the options of applying the policy manifests of an user.
+type PolicyApplyOptions struct {
+	// DryRun only reports the changes without making them.
+	DryRun bool `json:"dryRun" form:"dryRun"`
+
+	// AllowEmpty allows applying no policies, which deletes all policies of the user.
+	AllowEmpty bool `json:"allowEmpty" form:"allowEmpty"`
+}
+
+// PolicyApplyResult is the result of applying the policy manifests of an user.
+type PolicyApplyResult struct {
+	Username string `json:"username"`
+	DryRun   bool   `json:"dryRun"`
+
+	// Changes of the policies, unchanged policies are not listed.
+	Changes []SnapshotChange `json:"changes"`
+}
+
 // TableName maps to mysql table name.
 func (p *Policy) TableName() string {
 	return "policy"
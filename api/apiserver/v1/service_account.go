@@ -0,0 +1,95 @@
+package v1
+
+import (
+	"encoding/json"
+	"strings"
+
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/pkg/idutil"
+	"gorm.io/gorm"
+)
+
+// UserOwnerPrefix prefixes the name of an user owning a service account.
+const UserOwnerPrefix = "user:"
+
+// ServiceAccount represents a service account restful resource, a non-human identity
+// used by bots and pipelines. It is owned by an user (`user:<name>`) or a group
+// (`group:<name>`), can not log in with a password and authenticates with its secrets.
+// Service accounts share the user table, so they can own secrets and policies like
+// users do. It is also used as gorm model.
+type ServiceAccount struct {
+	// Standard object's metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Owner of the service account, `user:<name>` or `group:<name>`.
+	// Required: true
+	Owner string `json:"owner" gorm:"column:owner" validate:"required"`
+
+	Description string `json:"description" gorm:"column:description" validate:"description"`
+
+	// Type is always UserTypeServiceAccount, it is kept in the user table only.
+	Type string `json:"-" gorm:"column:type" validate:"omitempty"`
+}
+
+// ServiceAccountList is the whole list of all service accounts which have been stored in stroage.
+type ServiceAccountList struct {
+	// Standard list metadata.
+	metav1.ListMeta `json:",inline"`
+
+	// List of service accounts.
+	Items []*ServiceAccount `json:"items"`
+}
+
+// UserOwner returns the owner string of a service account owned by user name.
+func UserOwner(name string) string {
+	return UserOwnerPrefix + name
+}
+
+// ParseOwner splits the owner of a service account into the owner kind prefix
+// (UserOwnerPrefix or GroupSubjectPrefix) and the owner name.
+func ParseOwner(owner string) (prefix string, name string) {
+	for _, prefix := range []string{UserOwnerPrefix, GroupSubjectPrefix} {
+		if strings.HasPrefix(owner, prefix) {
+			return prefix, strings.TrimPrefix(owner, prefix)
+		}
+	}
+
+	return "", owner
+}
+
+// TableName maps to mysql table name.
+func (s *ServiceAccount) TableName() string {
+	return "user"
+}
+
+// BeforeCreate run before create database record.
+func (s *ServiceAccount) BeforeCreate(tx *gorm.DB) (err error) {
+	s.Type = UserTypeServiceAccount
+	s.ExtendShadow = s.Extend.String()
+
+	return
+}
+
+// AfterCreate run after create database record.
+func (s *ServiceAccount) AfterCreate(tx *gorm.DB) (err error) {
+	s.InstanceID = idutil.GetInstanceID(s.ID, "sa-")
+
+	return tx.Save(s).Error
+}
+
+// BeforeUpdate run before update database record.
+func (s *ServiceAccount) BeforeUpdate(tx *gorm.DB) (err error) {
+	s.Type = UserTypeServiceAccount
+	s.ExtendShadow = s.Extend.String()
+
+	return err
+}
+
+// AfterFind run after find to unmarshal a extend shadown string into metav1.Extend struct.
+func (s *ServiceAccount) AfterFind(tx *gorm.DB) (err error) {
+	if err := json.Unmarshal([]byte(s.ExtendShadow), &s.Extend); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,239 @@
+package v1
+
+import (
+	"encoding/json"
+	"time"
+
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/pkg/idutil"
+	"gorm.io/gorm"
+)
+
+// Version and kind of snapshot bundles.
+const (
+	SnapshotAPIVersion = "iam.snapshot/v1"
+	SnapshotKind       = "Snapshot"
+)
+
+// Import modes of a snapshot.
+const (
+	// SnapshotModeCreateOnly only creates missing objects, existing objects are left untouched.
+	SnapshotModeCreateOnly = "create-only"
+
+	// SnapshotModeUpsert creates missing objects and updates existing ones.
+	SnapshotModeUpsert = "upsert"
+
+	// SnapshotModeReplace upserts and deletes the objects missing in the snapshot.
+	SnapshotModeReplace = "replace"
+)
+
+// Actions of a snapshot import.
+const (
+	SnapshotActionCreate = "create"
+	SnapshotActionUpdate = "update"
+	SnapshotActionDelete = "delete"
+
+	// SnapshotActionSkip is reported for existing objects which differ in create-only mode.
+	SnapshotActionSkip = "skip"
+)
+
+// Snapshot is a versioned and consistent bundle of all users, secrets and policies,
+// used to back up iam and to move it between environments.
+type Snapshot struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Time the snapshot was taken.
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Encryption of the secret keys, nil when they are in plain text.
+	Encryption *SnapshotEncryption `json:"encryption,omitempty"`
+
+	Users    []*SnapshotUser   `json:"users"`
+	Secrets  []*SnapshotSecret `json:"secrets"`
+	Policies []*SnapshotPolicy `json:"policies"`
+}
+
+// SnapshotEncryption describes how the secret keys of a snapshot are encrypted with a passphrase.
+type SnapshotEncryption struct {
+	// Cipher the secret keys are encrypted with, aes-256-gcm.
+	Cipher string `json:"cipher"`
+
+	// KDF derives the key of the cipher from the passphrase, scrypt.
+	KDF string `json:"kdf"`
+
+	// Salt of the key derivation.
+	Salt []byte `json:"salt"`
+}
+
+// SnapshotUser is an user or a service account in a snapshot, the password is kept hashed.
+// It is also used as gorm model of the user table.
+type SnapshotUser struct {
+	ID         uint64 `json:"-" gorm:"primary_key;AUTO_INCREMENT;column:id"`
+	InstanceID string `json:"-" gorm:"column:instanceID"`
+
+	Name              string        `json:"name"              gorm:"column:name"`
+	Type              string        `json:"type"              gorm:"column:type"`
+	Owner             string        `json:"owner,omitempty"   gorm:"column:owner"`
+	Nickname          string        `json:"nickname"          gorm:"column:nickname"`
+	Password          string        `json:"password"          gorm:"column:password"`
+	PasswordChangedAt time.Time     `json:"passwordChangedAt" gorm:"column:passwordChangedAt"`
+	Email             string        `json:"email"             gorm:"column:email"`
+	EmailVerified     bool          `json:"emailVerified"     gorm:"column:emailVerified"`
+	Phone             string        `json:"phone"             gorm:"column:phone"`
+	IsAdmin           int           `json:"isAdmin"           gorm:"column:isAdmin"`
+	Description       string        `json:"description"       gorm:"column:description"`
+	Extend            metav1.Extend `json:"extend,omitempty"  gorm:"-"`
+	ExtendShadow      string        `json:"-"                 gorm:"column:extendShadow"`
+}
+
+// SnapshotSecret is a secret in a snapshot. It is also used as gorm model of the secret table.
+type SnapshotSecret struct {
+	ID         uint64 `json:"-" gorm:"primary_key;AUTO_INCREMENT;column:id"`
+	InstanceID string `json:"-" gorm:"column:instanceID"`
+
+	Username     string        `json:"username"         gorm:"column:username"`
+	Name         string        `json:"name"             gorm:"column:name"`
+	SecretID     string        `json:"secretID"         gorm:"column:secretID"`
+	SecretKey    string        `json:"secretKey"        gorm:"column:secretKey"`
+	Expires      int64         `json:"expires"          gorm:"column:expires"`
+	Description  string        `json:"description"      gorm:"column:description"`
+	Extend       metav1.Extend `json:"extend,omitempty" gorm:"-"`
+	ExtendShadow string        `json:"-"                gorm:"column:extendShadow"`
+}
+
+// SnapshotPolicy is a policy in a snapshot. It is also used as gorm model of the policy table.
+type SnapshotPolicy struct {
+	ID         uint64 `json:"-" gorm:"primary_key;AUTO_INCREMENT;column:id"`
+	InstanceID string `json:"-" gorm:"column:instanceID"`
+
+	Username     string        `json:"username"         gorm:"column:username"`
+	Name         string        `json:"name"             gorm:"column:name"`
+	Policy       AuthzPolicy   `json:"policy"           gorm:"-"`
+	PolicyShadow string        `json:"-"                gorm:"column:policyShadow"`
+	Extend       metav1.Extend `json:"extend,omitempty" gorm:"-"`
+	ExtendShadow string        `json:"-"                gorm:"column:extendShadow"`
+}
+
+// SnapshotChange is a change of an object made, or to be made in a dry run, by a snapshot import.
+type SnapshotChange struct {
+	// Kind of the object, users, secrets or policies.
+	Kind string `json:"kind"`
+
+	// Name of users, `<username>/<name>` of secrets and policies.
+	Name string `json:"name"`
+
+	// Action is one of create, update, delete and skip.
+	Action string `json:"action"`
+
+	// Fields which differ, only set for updates and skips.
+	Fields []string `json:"fields,omitempty"`
+}
+
+// SnapshotImportOptions are the options of a snapshot import.
+type SnapshotImportOptions struct {
+	// Mode is one of create-only, upsert and replace.
+	Mode string `json:"mode" form:"mode"`
+
+	// DryRun only reports the changes without making them.
+	DryRun bool `json:"dryRun" form:"dryRun"`
+
+	// Passphrase the secret keys are encrypted with.
+	Passphrase string `json:"-" form:"-"`
+}
+
+// SnapshotImportResult is the result of a snapshot import.
+type SnapshotImportResult struct {
+	Mode   string `json:"mode"`
+	DryRun bool   `json:"dryRun"`
+
+	// Changes of the import, unchanged objects are not listed.
+	Changes []SnapshotChange `json:"changes"`
+}
+
+// TableName maps to mysql table name.
+func (u *SnapshotUser) TableName() string {
+	return "user"
+}
+
+// BeforeSave run before create or update database record.
+func (u *SnapshotUser) BeforeSave(tx *gorm.DB) (err error) {
+	if u.PasswordChangedAt.IsZero() {
+		u.PasswordChangedAt = time.Now()
+	}
+	u.ExtendShadow = u.Extend.String()
+
+	return
+}
+
+// AfterCreate run after create database record.
+func (u *SnapshotUser) AfterCreate(tx *gorm.DB) (err error) {
+	u.InstanceID = idutil.GetInstanceID(u.ID, "user-")
+
+	return tx.Model(u).Update("instanceID", u.InstanceID).Error
+}
+
+// AfterFind run after find to unmarshal a extend shadown string into metav1.Extend struct.
+func (u *SnapshotUser) AfterFind(tx *gorm.DB) (err error) {
+	return unmarshalExtend(u.ExtendShadow, &u.Extend)
+}
+
+// TableName maps to mysql table name.
+func (s *SnapshotSecret) TableName() string {
+	return "secret"
+}
+
+// BeforeSave run before create or update database record.
+func (s *SnapshotSecret) BeforeSave(tx *gorm.DB) (err error) {
+	s.ExtendShadow = s.Extend.String()
+
+	return
+}
+
+// AfterCreate run after create database record.
+func (s *SnapshotSecret) AfterCreate(tx *gorm.DB) (err error) {
+	s.InstanceID = idutil.GetInstanceID(s.ID, "secret-")
+
+	return tx.Model(s).Update("instanceID", s.InstanceID).Error
+}
+
+// AfterFind run after find to unmarshal a extend shadown string into metav1.Extend struct.
+func (s *SnapshotSecret) AfterFind(tx *gorm.DB) (err error) {
+	return unmarshalExtend(s.ExtendShadow, &s.Extend)
+}
+
+// TableName maps to mysql table name.
+func (p *SnapshotPolicy) TableName() string {
+	return "policy"
+}
+
+// BeforeSave run before create or update database record.
+func (p *SnapshotPolicy) BeforeSave(tx *gorm.DB) (err error) {
+	p.PolicyShadow = p.Policy.String()
+	p.ExtendShadow = p.Extend.String()
+
+	return
+}
+
+// AfterCreate run after create database record.
+func (p *SnapshotPolicy) AfterCreate(tx *gorm.DB) (err error) {
+	p.InstanceID = idutil.GetInstanceID(p.ID, "policy-")
+
+	return tx.Model(p).Update("instanceID", p.InstanceID).Error
+}
+
+// AfterFind run after find to unmarshal a policy string into ladon.DefaultPolicy struct.
+func (p *SnapshotPolicy) AfterFind(tx *gorm.DB) (err error) {
+	if err := json.Unmarshal([]byte(p.PolicyShadow), &p.Policy); err != nil {
+		return err
+	}
+
+	return unmarshalExtend(p.ExtendShadow, &p.Extend)
+}
+
+func unmarshalExtend(shadow string, extend *metav1.Extend) error {
+	if shadow == "" {
+		return nil
+	}
+
+	return json.Unmarshal([]byte(shadow), extend)
+}
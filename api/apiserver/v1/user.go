@@ -2,6 +2,7 @@ package v1
 
 import (
 	"encoding/json"
+	"time"
 
 	metav1 "github.com/rose839/IAM/api/meta/v1"
 	"github.com/rose839/IAM/pkg/auth"
@@ -9,6 +10,15 @@ import (
 	"gorm.io/gorm"
 )
 
+// Types of the identities stored in the user table.
+const (
+	// UserTypeHuman is a person who logs in with a password.
+	UserTypeHuman = "user"
+
+	// UserTypeServiceAccount is a non-human identity which only authenticates with secrets.
+	UserTypeServiceAccount = "serviceaccount"
+)
+
 // User represents a user restful resource. It is also used as gorm model.
 type User struct {
 	// Standard object's metadata.
@@ -23,11 +33,21 @@ type User struct {
 	// Required: true
 	Email string `json:"email" gorm:"column:email" validate:"required,email,min=1,max=100"`
 
+	EmailVerified bool `json:"emailVerified" gorm:"column:emailVerified" validate:"omitempty"`
+
 	Phone string `json:"phone" gorm:"column:phone" validate:"omitempty"`
 
 	IsAdmin int `json:"isAdmin,omitempty" gorm:"column:isAdmin" validate:"omitempty"`
 
 	TotalPolicy int64 `json:"totalPolicy" gorm:"-" validate:"omitempty"`
+
+	PasswordChangedAt time.Time `json:"passwordChangedAt,omitempty" gorm:"column:passwordChangedAt" validate:"omitempty"`
+
+	// Type tells people and service accounts apart, it is set by the server.
+	Type string `json:"type" gorm:"column:type" validate:"omitempty"`
+
+	// Owner of a service account, empty for people.
+	Owner string `json:"owner,omitempty" gorm:"column:owner" validate:"omitempty"`
 }
 
 // UserList is the whole list of all users which have been stored in stroage.
@@ -50,9 +70,28 @@ func (u *User) Compare(pwd string) (err error) {
 	return
 }
 
+// IsServiceAccount reports whether the user is a service account, which can not log in with a password.
+func (u *User) IsServiceAccount() bool {
+	return u.Type == UserTypeServiceAccount
+}
+
+// PasswordExpired reports whether the password is older than maxAge, a zero maxAge never expires.
+func (u *User) PasswordExpired(maxAge time.Duration) bool {
+	if maxAge <= 0 || u.PasswordChangedAt.IsZero() {
+		return false
+	}
+
+	return time.Since(u.PasswordChangedAt) > maxAge
+}
+
 // BeforeCreate run before create database record.
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
-	u.Password, err = auth.Encrypt(u.Password)
+	if u.Type == "" {
+		u.Type = UserTypeHuman
+	}
+	if !u.IsServiceAccount() {
+		u.Password, err = auth.Encrypt(u.Password)
+	}
 	u.ExtendShadow = u.Extend.String()
 	return
 }
@@ -65,7 +104,11 @@ func (u *User) AfterCreate(tx *gorm.DB) (err error) {
 
 // BeforeUpdate run before update database record.
 func (u *User) BeforeUpdate(tx *gorm.DB) (err error) {
-	u.Password, err = auth.Encrypt(u.Password)
+	// users loaded from database carry the hash, only encrypt a new plain text password.
+	// Service accounts have no password at all.
+	if !u.IsServiceAccount() && !auth.IsEncrypted(u.Password) {
+		u.Password, err = auth.Encrypt(u.Password)
+	}
 	u.ExtendShadow = u.Extend.String()
 
 	return
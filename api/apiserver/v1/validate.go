@@ -1,6 +1,10 @@
 package v1
 
 import (
+	"net/url"
+	"path"
+
+	"github.com/rose839/IAM/pkg/auth"
 	"github.com/rose839/IAM/pkg/validation"
 	"github.com/rose839/IAM/pkg/validation/field"
 )
@@ -9,7 +13,7 @@ func (u *User) Validate() field.ErrorList {
 	val := validation.NewValidator(u)
 	allErrs := val.Validate()
 
-	if err := validation.IsValidPassword(u.Password); err != nil {
+	if err := validation.IsValidUserPassword(u.Name, u.Password); err != nil {
 		allErrs = append(allErrs, field.Invalid(field.NewPath("password"), err.Error(), ""))
 	}
 
@@ -37,3 +41,95 @@ func (p *Policy) Validate() field.ErrorList {
 
 	return val.Validate()
 }
+
+// Validate validates that a group object is valid.
+func (g *Group) Validate() field.ErrorList {
+	val := validation.NewValidator(g)
+
+	return val.Validate()
+}
+
+// Validate validates that a service account object is valid.
+func (s *ServiceAccount) Validate() field.ErrorList {
+	val := validation.NewValidator(s)
+	allErrs := val.Validate()
+
+	if prefix, name := ParseOwner(s.Owner); prefix == "" || name == "" {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("owner"), s.Owner, "must be user:<name> or group:<name>"))
+	}
+
+	return allErrs
+}
+
+// Validate validates that a webhook object is valid.
+func (w *Webhook) Validate() field.ErrorList {
+	val := validation.NewValidator(w)
+	allErrs := val.Validate()
+
+	if u, err := url.Parse(w.URL); err == nil && u.Scheme != "http" && u.Scheme != "https" {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("url"), w.URL, "must be a http or https url"))
+	}
+
+	for i, event := range w.Events {
+		if _, err := path.Match(event, ""); err != nil || event == "" {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("events").Index(i), event, "must be a valid event pattern"))
+		}
+	}
+
+	return allErrs
+}
+
+// Validate validates that a snapshot is valid: it has a known version, its objects are
+// named and unique, and the passwords of its users are bcrypt hashes, so that a plain
+// text password can not be imported and stored as is.
+func (s *Snapshot) Validate() field.ErrorList {
+	var allErrs field.ErrorList
+
+	if s.APIVersion != SnapshotAPIVersion {
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("apiversion"), s.APIVersion, []string{SnapshotAPIVersion}))
+	}
+	if s.Kind != SnapshotKind {
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("kind"), s.Kind, []string{SnapshotKind}))
+	}
+
+	users := map[string]bool{}
+	for i, u := range s.Users {
+		p := field.NewPath("users").Index(i)
+		if u.Name == "" {
+			allErrs = append(allErrs, field.Required(p.Child("name"), ""))
+		} else if users[u.Name] {
+			allErrs = append(allErrs, field.Duplicate(p.Child("name"), u.Name))
+		}
+		users[u.Name] = true
+
+		if u.Type != UserTypeServiceAccount && !auth.IsEncrypted(u.Password) {
+			allErrs = append(allErrs, field.Forbidden(p.Child("password"), "must be a bcrypt hash"))
+		}
+	}
+
+	secrets := map[string]bool{}
+	for i, secret := range s.Secrets {
+		p := field.NewPath("secrets").Index(i)
+		key := secret.Username + "/" + secret.Name
+		if secret.Username == "" || secret.Name == "" {
+			allErrs = append(allErrs, field.Required(p, "username and name are required"))
+		} else if secrets[key] {
+			allErrs = append(allErrs, field.Duplicate(p, key))
+		}
+		secrets[key] = true
+	}
+
+	policies := map[string]bool{}
+	for i, pol := range s.Policies {
+		p := field.NewPath("policies").Index(i)
+		key := pol.Username + "/" + pol.Name
+		if pol.Username == "" || pol.Name == "" {
+			allErrs = append(allErrs, field.Required(p, "username and name are required"))
+		} else if policies[key] {
+			allErrs = append(allErrs, field.Duplicate(p, key))
+		}
+		policies[key] = true
+	}
+
+	return allErrs
+}
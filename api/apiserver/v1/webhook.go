@@ -0,0 +1,146 @@
+package v1
+
+import (
+	"encoding/json"
+	"time"
+
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/pkg/idutil"
+	"gorm.io/gorm"
+)
+
+// Webhook delivery statuses.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook represents a webhook restful resource, an endpoint which is notified when
+// resources change. It is also used as gorm model.
+type Webhook struct {
+	// Standard object's metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// URL the events are posted to.
+	// Required: true
+	URL string `json:"url" gorm:"column:url" validate:"required,url"`
+
+	// Events the webhook is subscribed to, `<kind>.<verb>` or `<kind>.<subresource>.<verb>`
+	// with shell patterns, e.g. users.create, policies.* or *.
+	// Required: true
+	Events []string `json:"events" gorm:"-" validate:"required,min=1"`
+
+	// Secret used to sign the payloads with HMAC-SHA256, generated when empty. It is
+	// only returned when the webhook is created.
+	Secret string `json:"secret,omitempty" gorm:"column:secret" validate:"omitempty,min=16,max=255"`
+
+	// The events string, just a string format of Events. DO NOT modify directly.
+	EventsShadow string `json:"-" gorm:"column:eventsShadow" validate:"omitempty"`
+}
+
+// WebhookList is the whole list of all webhooks which have been stored in stroage.
+type WebhookList struct {
+	// Standard list metadata.
+	metav1.ListMeta `json:",inline"`
+
+	// List of webhooks.
+	Items []*Webhook `json:"items"`
+}
+
+// WebhookPayload is the body posted to a webhook.
+type WebhookPayload struct {
+	// Event type, e.g. policies.update.
+	Event string `json:"event"`
+
+	// Data is the audit event of the request which changed the resource.
+	Data *AuditEvent `json:"data"`
+}
+
+// WebhookDelivery records the delivery of an event to a webhook. It is also used as gorm model.
+type WebhookDelivery struct {
+	ID uint64 `json:"id" gorm:"primary_key;AUTO_INCREMENT;column:id"`
+
+	// Webhook is the name of the webhook the event is delivered to.
+	Webhook string `json:"webhook" gorm:"column:webhook"`
+
+	Event     string `json:"event"     gorm:"column:event"`
+	RequestID string `json:"requestID" gorm:"column:requestID"`
+
+	// Payload is the posted body.
+	Payload string `json:"payload" gorm:"column:payload"`
+
+	// Status is one of pending, succeeded and failed.
+	Status string `json:"status" gorm:"column:status"`
+
+	// Attempts is the number of the delivery attempts so far.
+	Attempts int `json:"attempts" gorm:"column:attempts"`
+
+	// ResponseStatus and Error describe the last attempt.
+	ResponseStatus int    `json:"responseStatus,omitempty" gorm:"column:responseStatus"`
+	Error          string `json:"error,omitempty"          gorm:"column:error"`
+
+	CreatedAt time.Time `json:"createdAt" gorm:"column:createdAt"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"column:updatedAt"`
+}
+
+// WebhookDeliveryList is the whole list of the deliveries of a webhook.
+type WebhookDeliveryList struct {
+	// Standard list metadata.
+	metav1.ListMeta `json:",inline"`
+
+	// List of webhook deliveries.
+	Items []*WebhookDelivery `json:"items"`
+}
+
+// TableName maps to mysql table name.
+func (w *Webhook) TableName() string {
+	return "webhook"
+}
+
+// TableName maps to mysql table name.
+func (d *WebhookDelivery) TableName() string {
+	return "webhook_delivery"
+}
+
+// BeforeCreate run before create database record.
+func (w *Webhook) BeforeCreate(tx *gorm.DB) (err error) {
+	return w.shadow()
+}
+
+// AfterCreate run after create database record.
+func (w *Webhook) AfterCreate(tx *gorm.DB) (err error) {
+	w.InstanceID = idutil.GetInstanceID(w.ID, "webhook-")
+
+	return tx.Save(w).Error
+}
+
+// BeforeUpdate run before update database record.
+func (w *Webhook) BeforeUpdate(tx *gorm.DB) (err error) {
+	return w.shadow()
+}
+
+// AfterFind run after find to unmarshal the shadow strings into Events and metav1.Extend struct.
+func (w *Webhook) AfterFind(tx *gorm.DB) (err error) {
+	if err := json.Unmarshal([]byte(w.EventsShadow), &w.Events); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal([]byte(w.ExtendShadow), &w.Extend); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (w *Webhook) shadow() error {
+	events, err := json.Marshal(w.Events)
+	if err != nil {
+		return err
+	}
+
+	w.EventsShadow = string(events)
+	w.ExtendShadow = w.Extend.String()
+
+	return nil
+}
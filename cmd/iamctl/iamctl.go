@@ -0,0 +1,9 @@
+package main
+
+import (
+	"github.com/rose839/IAM/internal/iamctl"
+)
+
+func main() {
+	iamctl.NewApp("iamctl").Run()
+}
@@ -1,6 +1,55 @@
 CREATE DATABASE IF NOT EXISTS `iam`;
 USE `iam`;
 
+DROP TABLE IF EXISTS `audit_event`;
+CREATE TABLE `audit_event` (
+    `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `timestamp` timestamp(3) NOT NULL DEFAULT current_timestamp(3),
+    `level` varchar(16) NOT NULL,
+    `requestID` varchar(64) NOT NULL DEFAULT '',
+    `username` varchar(255) NOT NULL DEFAULT '',
+    `operator` varchar(255) NOT NULL DEFAULT '',
+    `clientIP` varchar(64) NOT NULL DEFAULT '',
+    `verb` varchar(16) NOT NULL,
+    `kind` varchar(64) NOT NULL,
+    `name` varchar(1024) NOT NULL DEFAULT '',
+    `owner` varchar(255) NOT NULL DEFAULT '',
+    `subresource` varchar(64) NOT NULL DEFAULT '',
+    `httpStatus` int(11) NOT NULL DEFAULT 0,
+    `code` int(11) NOT NULL DEFAULT 0,
+    `message` varchar(1024) NOT NULL DEFAULT '',
+    `diffShadow` longtext DEFAULT NULL,
+    PRIMARY KEY (`id`),
+    KEY `idx_timestamp` (`timestamp`),
+    KEY `idx_username` (`username`),
+    KEY `idx_kind_name` (`kind`, `name`(255)),
+    KEY `idx_request_id` (`requestID`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+DROP TABLE IF EXISTS `group_member`;
+CREATE TABLE `group_member` (
+    `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `groupName` varchar(64) NOT NULL,
+    `username` varchar(45) NOT NULL,
+    `createdAt` timestamp NOT NULL DEFAULT current_timestamp(),
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uniq_groupname_username` (`groupName`, `username`),
+    KEY `fk_group_member_user_idx` (`username`),
+    CONSTRAINT `fk_group_member_group` FOREIGN KEY (`groupName`) REFERENCES `user_group` (`name`) ON DELETE CASCADE ON UPDATE NO ACTION,
+    CONSTRAINT `fk_group_member_user` FOREIGN KEY (`username`) REFERENCES `user` (`name`) ON DELETE CASCADE ON UPDATE NO ACTION
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+DROP TABLE IF EXISTS `password_history`;
+CREATE TABLE `password_history` (
+    `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `username` varchar(45) NOT NULL,
+    `password` varchar(255) NOT NULL,
+    `createdAt` timestamp NOT NULL DEFAULT current_timestamp(),
+    PRIMARY KEY (`id`),
+    KEY `fk_password_history_user_idx` (`username`),
+    CONSTRAINT `fk_password_history_user` FOREIGN KEY (`username`) REFERENCES `user` (`name`) ON DELETE CASCADE ON UPDATE NO ACTION
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
 DROP TABLE IF EXISTS `policy`;
 CREATE TABLE `policy` (
     `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
@@ -58,15 +107,69 @@ CREATE TABLE `user` (
   `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
   `instanceID` varchar(20) DEFAULT NULL,
   `name` varchar(45) NOT NULL,
-  `nickname` varchar(30) NOT NULL,
-  `password` varchar(255) NOT NULL,
-  `email` varchar(256) NOT NULL,
+  `nickname` varchar(30) NOT NULL DEFAULT '',
+  `password` varchar(255) NOT NULL DEFAULT '',
+  `email` varchar(256) NOT NULL DEFAULT '',
+  `emailVerified` tinyint(1) unsigned NOT NULL DEFAULT 0,
   `phone` varchar(20) DEFAULT NULL,
   `isAdmin` tinyint(1) unsigned NOT NULL DEFAULT 0 COMMENT '1: administrator\\\\n0: non-administrator',
+  `passwordChangedAt` timestamp NOT NULL DEFAULT current_timestamp(),
+  `type` varchar(16) NOT NULL DEFAULT 'user' COMMENT 'user: person\\\\nserviceaccount: non-human identity without password',
+  `owner` varchar(128) NOT NULL DEFAULT '' COMMENT 'user:<name> or group:<name>, only set for service accounts',
+  `description` varchar(255) NOT NULL DEFAULT '',
   `extendShadow` longtext DEFAULT NULL,
   `createdAt` timestamp NOT NULL DEFAULT current_timestamp(),
   `updatedAt` timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
   PRIMARY KEY (`id`),
   UNIQUE KEY `idx_name` (`name`),
-  UNIQUE KEY `instanceID_UNIQUE` (`instanceID`)
-) ENGINE=InnoDB AUTO_INCREMENT=38 DEFAULT CHARSET=utf8;
\ No newline at end of file
+  UNIQUE KEY `instanceID_UNIQUE` (`instanceID`),
+  KEY `idx_owner` (`owner`)
+) ENGINE=InnoDB AUTO_INCREMENT=38 DEFAULT CHARSET=utf8;
+
+DROP TABLE IF EXISTS `user_group`;
+CREATE TABLE `user_group` (
+    `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `instanceID` varchar(20) DEFAULT NULL,
+    `name` varchar(64) NOT NULL,
+    `description` varchar(255) NOT NULL DEFAULT '',
+    `extendShadow` longtext DEFAULT NULL,
+    `createdAt` timestamp NOT NULL DEFAULT current_timestamp(),
+    `updatedAt` timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_name` (`name`),
+    UNIQUE KEY `instanceID_UNIQUE` (`instanceID`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+DROP TABLE IF EXISTS `webhook`;
+CREATE TABLE `webhook` (
+    `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `instanceID` varchar(20) DEFAULT NULL,
+    `name` varchar(64) NOT NULL,
+    `url` varchar(2048) NOT NULL,
+    `eventsShadow` longtext DEFAULT NULL,
+    `secret` varchar(255) NOT NULL,
+    `extendShadow` longtext DEFAULT NULL,
+    `createdAt` timestamp NOT NULL DEFAULT current_timestamp(),
+    `updatedAt` timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_name` (`name`),
+    UNIQUE KEY `instanceID_UNIQUE` (`instanceID`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+DROP TABLE IF EXISTS `webhook_delivery`;
+CREATE TABLE `webhook_delivery` (
+    `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `webhook` varchar(64) NOT NULL,
+    `event` varchar(128) NOT NULL,
+    `requestID` varchar(64) NOT NULL DEFAULT '',
+    `payload` longtext DEFAULT NULL,
+    `status` varchar(16) NOT NULL,
+    `attempts` int(11) NOT NULL DEFAULT 0,
+    `responseStatus` int(11) NOT NULL DEFAULT 0,
+    `error` varchar(1024) NOT NULL DEFAULT '',
+    `createdAt` timestamp NOT NULL DEFAULT current_timestamp(),
+    `updatedAt` timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+    PRIMARY KEY (`id`),
+    KEY `idx_webhook_status` (`webhook`, `status`),
+    CONSTRAINT `fk_webhook_delivery_webhook` FOREIGN KEY (`webhook`) REFERENCES `webhook` (`name`) ON DELETE CASCADE ON UPDATE NO ACTION
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
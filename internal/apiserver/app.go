@@ -21,6 +21,8 @@ func NewApp(basename string) *app.App {
 		app.WithDescription(commandDesc),
 		app.WithDefaultValidArgs(),
 		app.WithRunFunc(run(opts)),
+		app.WithCommands(newExportCommand(), newImportCommand(), newPolicyCommand(),
+			app.NewConfigCommand(basename, options.NewOptions())),
 	)
 
 	return application
@@ -0,0 +1,223 @@
+// Package audit records every mutating request against the iam apiserver: who did
+// it, on which resource, with which outcome and, depending on the configured level,
+// a redacted diff of the resource. Events are written to pluggable sinks.
+package audit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	"github.com/rose839/IAM/internal/apiserver/store"
+	"github.com/rose839/IAM/internal/pkg/metrics"
+	genericoptions "github.com/rose839/IAM/internal/pkg/options"
+	"github.com/rose839/IAM/pkg/log"
+)
+
+// Level defines how much of a request is recorded.
+type Level string
+
+const (
+	// LevelNone does not record the request.
+	LevelNone Level = "none"
+
+	// LevelMetadata records who did what with which outcome.
+	LevelMetadata Level = "metadata"
+
+	// LevelDiff also records the redacted changes of the resource.
+	LevelDiff Level = "diff"
+)
+
+// Sink receives audit events.
+type Sink interface {
+	Write(event *v1.AuditEvent) error
+	Close() error
+}
+
+// Getter returns the current state of the resource name of kind, owner is set for
+// resources which belong to an user like secrets and policies.
+type Getter func(ctx context.Context, owner, name string) (interface{}, error)
+
+// Auditor records audit events of mutating requests to its sinks.
+type Auditor struct {
+	opts        *genericoptions.AuditOptions
+	sinks       []Sink
+	subscribers []Sink
+	getters     map[string]Getter
+	mu          sync.RWMutex
+	stopCh      chan struct{}
+}
+
+var (
+	auditor *Auditor
+	once    sync.Once
+)
+
+// GetAuditorOr return auditor instance with given options.
+func GetAuditorOr(opts *genericoptions.AuditOptions) (*Auditor, error) {
+	if opts != nil {
+		once.Do(func() {
+			auditor = &Auditor{
+				opts:    opts,
+				sinks:   newSinks(opts),
+				getters: make(map[string]Getter),
+				stopCh:  make(chan struct{}),
+			}
+		})
+	}
+
+	if auditor == nil {
+		return nil, fmt.Errorf("got nil auditor")
+	}
+
+	return auditor, nil
+}
+
+// Register sets the getter used to load resources of kind, resources without a
+// getter are recorded without diff.
+func (a *Auditor) Register(kind string, getter Getter) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.getters[kind] = getter
+}
+
+// AddSink adds a sink events are written to in addition to the configured ones.
+func (a *Auditor) AddSink(sink Sink) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.sinks = append(a.sinks, sink)
+}
+
+// Subscribe adds a subscriber which receives the events of all mutating requests, whether
+// they are recorded or not: auditing may be disabled for their kind or altogether. The
+// subscribers are not closed by the auditor.
+func (a *Auditor) Subscribe(subscriber Sink) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.subscribers = append(a.subscribers, subscriber)
+}
+
+func (a *Auditor) subscribed() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return len(a.subscribers) != 0
+}
+
+// publish writes event to all subscribers, failing subscribers are logged and skipped.
+func (a *Auditor) publish(event *v1.AuditEvent) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	for _, subscriber := range a.subscribers {
+		if err := subscriber.Write(event); err != nil {
+			log.Errorf("Publish event of request `%s` failed: %s", event.RequestID, err.Error())
+		}
+	}
+}
+
+// Record writes event to all sinks, failing sinks are logged, counted and skipped.
+func (a *Auditor) Record(event *v1.AuditEvent) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	for _, sink := range a.sinks {
+		err := sink.Write(event)
+		metrics.AuditWrites.WithLabelValues(metrics.Result(err)).Inc()
+		if err != nil {
+			log.Errorf("Write audit event of request `%s` failed: %s", event.RequestID, err.Error())
+		}
+	}
+}
+
+// StartPruner periodically deletes the audit events older than the retention from
+// the store, until the auditor is closed.
+func (a *Auditor) StartPruner() {
+	if !a.opts.Enabled || a.opts.Retention <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(a.opts.PruneInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				a.prune()
+			case <-a.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (a *Auditor) prune() {
+	before := time.Now().Add(-a.opts.Retention)
+	count, err := store.Client().AuditEvents().Prune(context.Background(), before)
+	if err != nil {
+		log.Errorf("Prune audit events before %s failed: %s", before.Format(time.RFC3339), err.Error())
+
+		return
+	}
+
+	if count > 0 {
+		log.Infof("Pruned %d audit events recorded before %s", count, before.Format(time.RFC3339))
+	}
+}
+
+// Close stops the pruner and closes all sinks, closing an already closed auditor does nothing.
+func (a *Auditor) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	select {
+	case <-a.stopCh:
+		return nil
+	default:
+		close(a.stopCh)
+	}
+
+	for _, sink := range a.sinks {
+		if err := sink.Close(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LevelFor returns the audit level of resources of kind.
+func (a *Auditor) LevelFor(kind string) Level {
+	if !a.opts.Enabled {
+		return LevelNone
+	}
+
+	if level, ok := a.opts.ResourceLevels[kind]; ok {
+		return Level(level)
+	}
+
+	return Level(a.opts.Level)
+}
+
+func (a *Auditor) get(ctx context.Context, kind, owner, name string) interface{} {
+	a.mu.RLock()
+	getter, ok := a.getters[kind]
+	a.mu.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	obj, err := getter(ctx, owner, name)
+	if err != nil {
+		return nil
+	}
+
+	return obj
+}
@@ -0,0 +1,37 @@
+package audit
+
+import (
+	"testing"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	genericoptions "github.com/rose839/IAM/internal/pkg/options"
+)
+
+// closeCounter is a sink counting how many times it is closed.
+type closeCounter struct {
+	closed int
+}
+
+func (s *closeCounter) Write(*v1.AuditEvent) error {
+	return nil
+}
+
+func (s *closeCounter) Close() error {
+	s.closed++
+
+	return nil
+}
+
+func TestAuditor_Close(t *testing.T) {
+	sink := &closeCounter{}
+	a := &Auditor{opts: genericoptions.NewAuditOptions(), sinks: []Sink{sink}, stopCh: make(chan struct{})}
+
+	for i := 0; i < 2; i++ {
+		if err := a.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	}
+	if sink.closed != 1 {
+		t.Errorf("sink closed %d times, want once", sink.closed)
+	}
+}
@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	"github.com/rose839/IAM/pkg/sets"
+)
+
+// redacted replaces the values of sensitive fields in diffs.
+const redacted = "******"
+
+// sensitiveFields are the json field names whose values never show up in audit events.
+var sensitiveFields = sets.NewString("password", "secretKey", "token", "secret")
+
+// Diff returns the fields which differ between the json representation of before
+// and after, keyed by dotted json path. Nested objects are compared field by field,
+// arrays as a whole. Values of sensitive fields are redacted.
+func Diff(before, after interface{}) map[string]v1.AuditChange {
+	b, a := flatten(before), flatten(after)
+
+	diff := make(map[string]v1.AuditChange)
+	for path := range sets.StringKeySet(b).Union(sets.StringKeySet(a)) {
+		if reflect.DeepEqual(b[path], a[path]) {
+			continue
+		}
+
+		change := v1.AuditChange{Before: b[path], After: a[path]}
+		if isSensitive(path) {
+			change = v1.AuditChange{Before: redact(b[path]), After: redact(a[path])}
+		}
+
+		diff[path] = change
+	}
+
+	if len(diff) == 0 {
+		return nil
+	}
+
+	return diff
+}
+
+// flatten converts obj into its json fields keyed by dotted path.
+func flatten(obj interface{}) map[string]interface{} {
+	ret := make(map[string]interface{})
+	if obj == nil {
+		return ret
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return ret
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return ret
+	}
+
+	walk("", value, ret)
+
+	return ret
+}
+
+func walk(prefix string, value interface{}, ret map[string]interface{}) {
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		if prefix != "" {
+			ret[prefix] = value
+		}
+
+		return
+	}
+
+	for key, field := range fields {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		walk(path, field, ret)
+	}
+}
+
+func isSensitive(path string) bool {
+	return sensitiveFields.Has(path[strings.LastIndex(path, ".")+1:])
+}
+
+func redact(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	return redacted
+}
@@ -0,0 +1,85 @@
+package audit
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+)
+
+func TestDiff(t *testing.T) {
+	before := &v1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "colin"},
+		Nickname:   "colin",
+		Password:   "$2a$10$old",
+		Email:      "colin@foxmail.com",
+	}
+	after := &v1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "colin"},
+		Nickname:   "colin",
+		Password:   "$2a$10$new",
+		Email:      "colin@qq.com",
+	}
+
+	tests := []struct {
+		name   string
+		before interface{}
+		after  interface{}
+		want   map[string]v1.AuditChange
+		// subset only checks the wanted paths.
+		subset bool
+	}{
+		{
+			name:   "changed fields with redacted password",
+			before: before,
+			after:  after,
+			want: map[string]v1.AuditChange{
+				"email":    {Before: "colin@foxmail.com", After: "colin@qq.com"},
+				"password": {Before: redacted, After: redacted},
+			},
+		},
+		{
+			name:   "unchanged",
+			before: before,
+			after:  before,
+			want:   nil,
+		},
+		{
+			name:   "nested fields of a deleted secret",
+			before: &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "ci"}, SecretKey: "key"},
+			after:  nil,
+			want: map[string]v1.AuditChange{
+				"metadata.name": {Before: "ci"},
+				"secretKey":     {Before: redacted},
+			},
+			subset: true,
+		},
+		{
+			name:   "rotated webhook secret",
+			before: &v1.Webhook{ObjectMeta: metav1.ObjectMeta{Name: "ci"}, Secret: "0123456789abcdef"},
+			after:  &v1.Webhook{ObjectMeta: metav1.ObjectMeta{Name: "ci"}, Secret: "fedcba9876543210"},
+			want: map[string]v1.AuditChange{
+				"secret": {Before: redacted, After: redacted},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Diff(tt.before, tt.after)
+			if tt.subset {
+				for path, change := range tt.want {
+					if !reflect.DeepEqual(got[path], change) {
+						t.Errorf("Diff()[%s] = %v, want %v", path, got[path], change)
+					}
+				}
+
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/internal/pkg/middleware"
+	"github.com/rose839/IAM/pkg/core"
+	"github.com/rose839/IAM/pkg/sets"
+)
+
+// verbs maps the mutating http methods to audit verbs.
+var verbs = map[string]string{
+	http.MethodPost:   "create",
+	http.MethodPut:    "update",
+	http.MethodPatch:  "patch",
+	http.MethodDelete: "delete",
+}
+
+// userResources are the resource kinds which are named per user.
+var userResources = sets.NewString("secrets", "policies")
+
+// target is the resource a request is sent to.
+type target struct {
+	kind        string
+	name        string
+	owner       string
+	subresource string
+}
+
+// Handler returns a gin middleware recording mutating requests and publishing them to
+// the subscribers. It must be installed after the authentication and impersonation
+// middlewares, requests of routes without authentication are recorded without username.
+func (a *Auditor) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		verb, ok := verbs[c.Request.Method]
+		if !ok {
+			c.Next()
+
+			return
+		}
+
+		// routes are parsed up front, nested routes rewrite their parameters.
+		t := parseTarget(c)
+		level := a.LevelFor(t.kind)
+		if t.kind == "" || (level == LevelNone && !a.subscribed()) {
+			c.Next()
+
+			return
+		}
+
+		// the subscribers get the metadata of requests which are not recorded.
+		eventLevel := level
+		if level == LevelNone {
+			eventLevel = LevelMetadata
+		}
+
+		event := &v1.AuditEvent{
+			Timestamp:   time.Now(),
+			Level:       string(eventLevel),
+			RequestID:   c.GetString(middleware.RequestIDKey),
+			ClientIP:    c.ClientIP(),
+			Verb:        verb,
+			Kind:        t.kind,
+			Name:        t.name,
+			Owner:       t.owner,
+			Subresource: t.subresource,
+		}
+
+		var before interface{}
+		if level == LevelDiff && verb != "create" && isSingle(t.name) {
+			before = a.get(c, t.kind, t.owner, t.name)
+		}
+
+		writer := &bodyWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = writer
+
+		c.Next()
+
+		event.Username = c.GetString(middleware.UsernameKey)
+		event.Operator = c.GetString(middleware.OperatorKey)
+		event.HTTPStatus = writer.Status()
+		event.Code, event.Message = outcome(writer.Status(), writer.body.Bytes())
+
+		if event.Name == "" && event.HTTPStatus == http.StatusOK {
+			event.Name = createdName(writer.body.Bytes())
+		}
+
+		if level == LevelDiff && event.HTTPStatus == http.StatusOK {
+			var after interface{}
+			if verb != "delete" && isSingle(event.Name) {
+				after = a.get(c, t.kind, t.owner, event.Name)
+			}
+
+			event.Diff = Diff(before, after)
+		}
+
+		if level != LevelNone {
+			a.Record(event)
+		}
+		a.publish(event)
+	}
+}
+
+// parseTarget extracts the resource from the route of the request, e.g.
+// /v1/users/:name/change-password or /v1/serviceaccounts/:name/secrets/:resource.
+func parseTarget(c *gin.Context) target {
+	segments := strings.Split(strings.TrimPrefix(c.FullPath(), "/v1/"), "/")
+	t := target{kind: segments[0]}
+
+	if len(segments) > 1 && strings.HasPrefix(segments[1], ":") {
+		t.name = c.Param(segments[1][1:])
+	}
+
+	if len(segments) > 2 {
+		if t.kind == "serviceaccounts" && userResources.Has(segments[2]) {
+			t.owner, t.kind, t.name = t.name, segments[2], ""
+			if len(segments) > 3 {
+				t.name = c.Param(strings.TrimPrefix(segments[3], ":"))
+			}
+		} else {
+			t.subresource = segments[2]
+		}
+	}
+
+	if userResources.Has(t.kind) && t.owner == "" {
+		t.owner = c.GetString(middleware.UsernameKey)
+	}
+
+	// collection requests name their resources in the query.
+	if t.name == "" {
+		t.name = strings.Join(c.QueryArray("name"), ",")
+	}
+
+	return t
+}
+
+func isSingle(name string) bool {
+	return name != "" && !strings.Contains(name, ",")
+}
+
+// outcome returns the business code and error message of a response written by core.WriteResponse.
+func outcome(status int, body []byte) (int, string) {
+	if status == http.StatusOK {
+		return code.ErrSuccess, ""
+	}
+
+	var resp core.ErrResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return 0, http.StatusText(status)
+	}
+
+	return resp.Code, resp.Message
+}
+
+// createdName returns the name of the resource returned by a create request.
+func createdName(body []byte) string {
+	var resp struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return ""
+	}
+
+	return resp.Metadata.Name
+}
+
+// bodyWriter keeps a copy of the response body.
+type bodyWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *bodyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+
+	return w.ResponseWriter.WriteString(s)
+}
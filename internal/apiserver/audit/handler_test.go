@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	genericoptions "github.com/rose839/IAM/internal/pkg/options"
+)
+
+// recorder is a sink keeping the written events.
+type recorder struct {
+	events []*v1.AuditEvent
+}
+
+func (r *recorder) Write(event *v1.AuditEvent) error {
+	r.events = append(r.events, event)
+
+	return nil
+}
+
+func (r *recorder) Close() error {
+	return nil
+}
+
+func TestHandler_Subscribers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		enabled        bool
+		resourceLevels map[string]string
+		wantRecorded   int
+	}{
+		{name: "enabled", enabled: true, wantRecorded: 1},
+		{name: "disabled", enabled: false, wantRecorded: 0},
+		{name: "kind not recorded", enabled: true, resourceLevels: map[string]string{"users": "none"}, wantRecorded: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := genericoptions.NewAuditOptions()
+			opts.Enabled = tt.enabled
+			opts.ResourceLevels = tt.resourceLevels
+
+			sink, subscriber := &recorder{}, &recorder{}
+			a := &Auditor{opts: opts, sinks: []Sink{sink}, getters: make(map[string]Getter)}
+			a.Subscribe(subscriber)
+
+			engine := gin.New()
+			engine.POST("/v1/users/:name", a.Handler(), func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{})
+			})
+
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/users/colin", nil))
+
+			if len(sink.events) != tt.wantRecorded {
+				t.Errorf("recorded %d events, want %d", len(sink.events), tt.wantRecorded)
+			}
+
+			if len(subscriber.events) != 1 {
+				t.Fatalf("published %d events, want 1", len(subscriber.events))
+			}
+
+			event := subscriber.events[0]
+			if event.Kind != "users" || event.Name != "colin" || event.Verb != "create" || event.HTTPStatus != http.StatusOK {
+				t.Errorf("published event = %+v", event)
+			}
+		})
+	}
+}
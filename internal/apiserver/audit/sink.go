@@ -0,0 +1,111 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	"github.com/rose839/IAM/internal/apiserver/store"
+	genericoptions "github.com/rose839/IAM/internal/pkg/options"
+	"github.com/rose839/IAM/pkg/storage"
+)
+
+// newSinks creates the sinks enabled by options, unknown sinks are rejected by options validation.
+func newSinks(opts *genericoptions.AuditOptions) []Sink {
+	sinks := make([]Sink, 0, len(opts.Sinks))
+	for _, name := range opts.Sinks {
+		switch name {
+		case "file":
+			sinks = append(sinks, NewFileSink(opts))
+		case "redis":
+			sinks = append(sinks, NewRedisSink(opts.RedisKey))
+		case "mysql":
+			sinks = append(sinks, NewStoreSink())
+		}
+	}
+
+	return sinks
+}
+
+type fileSink struct {
+	logger *lumberjack.Logger
+}
+
+// NewFileSink creates a sink which appends events as json lines to the audit
+// file, the file is rotated by size and the rotated files are pruned by count and age.
+func NewFileSink(opts *genericoptions.AuditOptions) Sink {
+	return &fileSink{
+		logger: &lumberjack.Logger{
+			Filename:   opts.File,
+			MaxSize:    opts.MaxSize,
+			MaxBackups: opts.MaxBackups,
+			MaxAge:     opts.MaxAge,
+			Compress:   opts.Compress,
+			LocalTime:  true,
+		},
+	}
+}
+
+// Write appends the event to the audit file, lumberjack serializes concurrent writes.
+func (s *fileSink) Write(event *v1.AuditEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.logger.Write(append(line, '\n'))
+
+	return err
+}
+
+func (s *fileSink) Close() error {
+	return s.logger.Close()
+}
+
+type redisSink struct {
+	key   string
+	store *storage.RedisCluster
+}
+
+// NewRedisSink creates a sink which pushes events to the redis list key, from
+// where a shipper can drain them.
+func NewRedisSink(key string) Sink {
+	return &redisSink{
+		key:   key,
+		store: &storage.RedisCluster{},
+	}
+}
+
+func (s *redisSink) Write(event *v1.AuditEvent) error {
+	if !storage.Connected() {
+		return storage.ErrRedisIsDown
+	}
+
+	value, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return s.store.AppendToList(s.key, string(value))
+}
+
+func (s *redisSink) Close() error {
+	return nil
+}
+
+type storeSink struct{}
+
+// NewStoreSink creates a sink which saves events in the store, where they can be queried.
+func NewStoreSink() Sink {
+	return &storeSink{}
+}
+
+func (s *storeSink) Write(event *v1.AuditEvent) error {
+	return store.Client().AuditEvents().Create(context.Background(), event)
+}
+
+func (s *storeSink) Close() error {
+	return nil
+}
@@ -1,19 +1,26 @@
 package apiserver
 
 import (
-	"context"
 	"encoding/base64"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
 	v1 "github.com/rose839/IAM/api/apiserver/v1"
 	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/apiserver/lockout"
 	"github.com/rose839/IAM/internal/apiserver/store"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/internal/pkg/metrics"
 	"github.com/rose839/IAM/internal/pkg/middleware"
 	"github.com/rose839/IAM/internal/pkg/middleware/auth"
+	genericoptions "github.com/rose839/IAM/internal/pkg/options"
+	"github.com/rose839/IAM/pkg/core"
+	"github.com/rose839/IAM/pkg/errors"
+	"github.com/rose839/IAM/pkg/validation"
 	"github.com/spf13/viper"
 )
 
@@ -31,29 +38,68 @@ type loginInfo struct {
 }
 
 func newBasicAuth() middleware.AuthStrategy {
-	return auth.NewBasicStrategy(func(username string, password string) bool {
-		// fetch user from database
-		user, err := store.Client().Users().Get(context.TODO(), username, metav1.GetOptions{})
-		if err != nil {
-			return false
+	return auth.NewBasicStrategy(func(c *gin.Context, username string, password string) error {
+		// refuse locked out users and clients before touching the password.
+		guard, _ := lockout.GetGuardOr(nil)
+		if err := guard.Check(username, c.ClientIP()); err != nil {
+			metrics.Logins.WithLabelValues(metrics.StrategyBasic, metrics.ResultLocked).Inc()
+
+			return err
+		}
+
+		// fetch user from database, service accounts can not log in with a password.
+		user, err := store.Client().Users().Get(c, username, metav1.GetOptions{})
+		if err != nil || user.IsServiceAccount() {
+			guard.Fail(c, username, c.ClientIP())
+			metrics.Logins.WithLabelValues(metrics.StrategyBasic, metrics.ResultFailure).Inc()
+
+			return errors.WithCode(code.ErrSignatureInvalid, "Authorization header format is wrong.")
 		}
 
 		// Compare the login password with the user password.
 		if err := user.Compare(password); err != nil {
-			return false
+			guard.Fail(c, username, c.ClientIP())
+			metrics.Logins.WithLabelValues(metrics.StrategyBasic, metrics.ResultFailure).Inc()
+
+			return errors.WithCode(code.ErrSignatureInvalid, "Authorization header format is wrong.")
 		}
 
-		return true
+		guard.Succeed(username)
+
+		// an expired password can only be used to change itself.
+		if user.PasswordExpired(validation.GetPasswordPolicy().MaxAge) &&
+			c.FullPath() != "/v1/users/:name/change-password" {
+			metrics.Logins.WithLabelValues(metrics.StrategyBasic, metrics.ResultExpired).Inc()
+
+			return errors.WithCode(code.ErrPasswordExpired, "Password of user `%s` has expired.", username)
+		}
+
+		metrics.Logins.WithLabelValues(metrics.StrategyBasic, metrics.ResultSuccess).Inc()
+
+		return nil
 	})
 }
 
+// tokenIssuer holds the *auth.JWTStrategy issuing and refreshing the tokens, which is
+// replaced when the timeouts of the tokens are changed on reload.
+var tokenIssuer atomic.Value
+
 func newJWTAuth() middleware.AuthStrategy {
+	return newJWTStrategy(&genericoptions.JwtOptions{
+		Realm:      viper.GetString("jwt.Realm"),
+		Key:        viper.GetString("jwt.key"),
+		Timeout:    viper.GetDuration("jwt.timeout"),
+		MaxRefresh: viper.GetDuration("jwt.max-refresh"),
+	})
+}
+
+func newJWTStrategy(opts *genericoptions.JwtOptions) middleware.AuthStrategy {
 	ginjwt, _ := jwt.New(&jwt.GinJWTMiddleware{
-		Realm:            viper.GetString("jwt.Realm"),
+		Realm:            opts.Realm,
 		SigningAlgorithm: "HS256",
-		Key:              []byte(viper.GetString("jwt.key")),
-		Timeout:          viper.GetDuration("jwt.timeout"),
-		MaxRefresh:       viper.GetDuration("jwt.max-refresh"),
+		Key:              []byte(opts.Key),
+		Timeout:          opts.Timeout,
+		MaxRefresh:       opts.MaxRefresh,
 		Authenticator:    authenticator(),
 		LoginResponse:    loginResponse(),
 		LogoutResponse: func(c *gin.Context, code int) {
@@ -67,8 +113,17 @@ func newJWTAuth() middleware.AuthStrategy {
 			return claims[jwt.IdentityKey]
 		},
 		Authorizator: authorizator(),
-		Unauthorized: func(c *gin.Context, code int, message string) {
-			c.JSON(code, gin.H{
+		Unauthorized: func(c *gin.Context, status int, message string) {
+			// gin-jwt only passes the message along, errors carrying a business code
+			// are recorded on the context by the authenticator.
+			if err := c.Errors.Last(); err != nil &&
+				(errors.IsCode(err.Err, code.ErrAccountLocked) || errors.IsCode(err.Err, code.ErrPasswordExpired)) {
+				core.WriteResponse(c, err.Err, nil)
+
+				return
+			}
+
+			c.JSON(status, gin.H{
 				"message": message,
 			})
 		},
@@ -81,6 +136,40 @@ func newJWTAuth() middleware.AuthStrategy {
 	return auth.NewJWTStrategy(*ginjwt)
 }
 
+// installTokenIssuer makes the given strategy issue and refresh the tokens.
+func installTokenIssuer(strategy auth.JWTStrategy) {
+	tokenIssuer.Store(&strategy)
+}
+
+// setTokenTimeouts changes the timeout and the max refresh time of the tokens issued from now on.
+func setTokenTimeouts(timeout, maxRefresh time.Duration) {
+	current, _ := tokenIssuer.Load().(*auth.JWTStrategy)
+	if current == nil {
+		return
+	}
+
+	strategy, _ := newJWTStrategy(&genericoptions.JwtOptions{
+		Realm:      current.Realm,
+		Key:        string(current.Key),
+		Timeout:    timeout,
+		MaxRefresh: maxRefresh,
+	}).(auth.JWTStrategy)
+	installTokenIssuer(strategy)
+}
+
+// loginHandler, refreshHandler and logoutHandler delegate to the current token issuer.
+func loginHandler(c *gin.Context) {
+	tokenIssuer.Load().(*auth.JWTStrategy).LoginHandler(c)
+}
+
+func refreshHandler(c *gin.Context) {
+	tokenIssuer.Load().(*auth.JWTStrategy).RefreshHandler(c)
+}
+
+func logoutHandler(c *gin.Context) {
+	tokenIssuer.Load().(*auth.JWTStrategy).LogoutHandler(c)
+}
+
 func newAutoAuth() middleware.AuthStrategy {
 	return auth.NewAutoStrategy(newBasicAuth().(auth.BasicStrategy), newJWTAuth().(auth.JWTStrategy))
 }
@@ -132,26 +221,58 @@ func authenticator() func(c *gin.Context) (interface{}, error) {
 			login, err = parseWithBody(c)
 		}
 		if err != nil {
+			metrics.Logins.WithLabelValues(metrics.StrategyJWT, metrics.ResultFailure).Inc()
+
 			return "", jwt.ErrFailedAuthentication
 		}
 
-		// Get the user information by username.
+		// refuse locked out users and clients before touching the password.
+		guard, _ := lockout.GetGuardOr(nil)
+		if err := guard.Check(login.Username, c.ClientIP()); err != nil {
+			_ = c.Error(err)
+			metrics.Logins.WithLabelValues(metrics.StrategyJWT, metrics.ResultLocked).Inc()
+
+			return "", err
+		}
+
+		// Get the user information by username, service accounts can not log in with a password.
 		user, err := store.Client().Users().Get(c, login.Username, metav1.GetOptions{})
-		if err != nil {
+		if err != nil || user.IsServiceAccount() {
+			guard.Fail(c, login.Username, c.ClientIP())
+			metrics.Logins.WithLabelValues(metrics.StrategyJWT, metrics.ResultFailure).Inc()
+
 			return "", jwt.ErrFailedAuthentication
 		}
 
 		// Compare the login password with the user password.
 		if err := user.Compare(login.Password); err != nil {
+			guard.Fail(c, login.Username, c.ClientIP())
+			metrics.Logins.WithLabelValues(metrics.StrategyJWT, metrics.ResultFailure).Inc()
+
 			return "", jwt.ErrFailedAuthentication
 		}
 
+		guard.Succeed(login.Username)
+
+		// force the user to change an expired password before issuing a token.
+		if user.PasswordExpired(validation.GetPasswordPolicy().MaxAge) {
+			err := errors.WithCode(code.ErrPasswordExpired, "Password of user `%s` has expired.", login.Username)
+			_ = c.Error(err)
+			metrics.Logins.WithLabelValues(metrics.StrategyJWT, metrics.ResultExpired).Inc()
+
+			return "", err
+		}
+
+		metrics.Logins.WithLabelValues(metrics.StrategyJWT, metrics.ResultSuccess).Inc()
+
 		return user, nil
 	}
 }
 
 func loginResponse() func(c *gin.Context, code int, token string, expire time.Time) {
 	return func(c *gin.Context, code int, token string, expire time.Time) {
+		metrics.Tokens.WithLabelValues(metrics.TokenIssue).Inc()
+
 		c.JSON(http.StatusOK, gin.H{
 			"token":  token,
 			"expire": expire.Format(time.RFC3339),
@@ -161,6 +282,8 @@ func loginResponse() func(c *gin.Context, code int, token string, expire time.Ti
 
 func refreshResponse() func(c *gin.Context, code int, token string, expire time.Time) {
 	return func(c *gin.Context, code int, token string, expire time.Time) {
+		metrics.Tokens.WithLabelValues(metrics.TokenRefresh).Inc()
+
 		c.JSON(http.StatusOK, gin.H{
 			"token":  token,
 			"expire": expire.Format(time.RFC3339),
@@ -0,0 +1,18 @@
+package auditevent
+
+import (
+	srvv1 "github.com/rose839/IAM/internal/apiserver/service/v1"
+	"github.com/rose839/IAM/internal/apiserver/store"
+)
+
+// AuditEventController create an audit event handler used to handle request for audit event resource.
+type AuditEventController struct {
+	srv srvv1.Service
+}
+
+// NewAuditEventController creates an audit event handler.
+func NewAuditEventController(store store.Factory) *AuditEventController {
+	return &AuditEventController{
+		srv: srvv1.NewService(store),
+	}
+}
@@ -0,0 +1,32 @@
+package auditevent
+
+import (
+	"github.com/gin-gonic/gin"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/core"
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+// List list the audit events in the storage, newest first. The field selector filters
+// by username, operator, kind, name, owner, subresource, verb, requestID and code, and
+// by time range with RFC3339 `since` and `until`, which only support `=`. A name also
+// matches the collection requests naming it, e.g.
+// fieldSelector=kind=policies,name=p1,verb=delete,since=2022-05-10T00:00:00Z.
+func (a *AuditEventController) List(c *gin.Context) {
+	var r metav1.ListOptions
+	if err := c.ShouldBindQuery(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	events, err := a.srv.AuditEvents().List(c, r)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, events)
+}
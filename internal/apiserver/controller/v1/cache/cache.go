@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sync"
 
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
 	metav1 "github.com/rose839/IAM/api/meta/v1"
 	pb "github.com/rose839/IAM/api/proto/apiserver/v1"
 	"github.com/rose839/IAM/internal/apiserver/store"
@@ -49,9 +50,24 @@ func (c *Cache) ListSecrets(ctx context.Context, r *pb.ListSecretsRequest) (*pb.
 		return nil, errors.WithCode(code.ErrDatabase, err.Error())
 	}
 
+	usernames := make([]string, 0, len(secrets.Items))
+	for _, secret := range secrets.Items {
+		usernames = append(usernames, secret.Username)
+	}
+
+	groups, err := c.store.Groups().ListByUsers(ctx, usernames)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	sas, err := c.store.ServiceAccounts().ListByNames(ctx, usernames)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
 	items := make([]*pb.SecretInfo, 0)
 	for _, secret := range secrets.Items {
-		items = append(items, &pb.SecretInfo{
+		info := &pb.SecretInfo{
 			SecretId:    secret.SecretID,
 			Username:    secret.Username,
 			SecretKey:   secret.SecretKey,
@@ -59,7 +75,15 @@ func (c *Cache) ListSecrets(ctx context.Context, r *pb.ListSecretsRequest) (*pb.
 			Description: secret.Description,
 			CreatedAt:   secret.CreatedAt.Format("2006-01-02 15:04:05"),
 			UpdatedAt:   secret.UpdatedAt.Format("2006-01-02 15:04:05"),
-		})
+			Groups:      groups[secret.Username],
+			OwnerType:   v1.UserTypeHuman,
+		}
+		if sa, ok := sas[secret.Username]; ok {
+			info.OwnerType = v1.UserTypeServiceAccount
+			info.Owner = sa.Owner
+		}
+
+		items = append(items, info)
 	}
 
 	return &pb.ListSecretsResponse{
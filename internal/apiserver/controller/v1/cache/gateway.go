@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/rose839/IAM/api/proto/apiserver/v1"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/internal/pkg/middleware"
+	"github.com/rose839/IAM/pkg/core"
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+// forwardedHeaders are the http headers forwarded to the grpc metadata of a call.
+var forwardedHeaders = []string{middleware.XRequestIDKey}
+
+// marshaler encodes responses like grpc-gateway does: lower camel case field names,
+// unpopulated fields included.
+var marshaler = protojson.MarshalOptions{EmitUnpopulated: true}
+
+// Gateway serves the cache service over http/json, to authenticated administrators only.
+// Calls run through the unary interceptors of the grpc server for request ids, logging,
+// metrics and recovery. The grpc callers are not rest api users, their names grant no
+// access to the gateway.
+type Gateway struct {
+	cache       *Cache
+	interceptor grpc.UnaryServerInterceptor
+}
+
+var (
+	gateway     *Gateway
+	gatewayOnce sync.Once
+)
+
+// GetGatewayOr return cache gateway instance with given cache server and interceptors.
+func GetGatewayOr(cache *Cache, interceptor grpc.UnaryServerInterceptor) (*Gateway, error) {
+	if cache != nil && interceptor != nil {
+		gatewayOnce.Do(func() {
+			gateway = &Gateway{cache: cache, interceptor: interceptor}
+		})
+	}
+
+	if gateway == nil {
+		return nil, fmt.Errorf("got nil cache gateway")
+	}
+
+	return gateway, nil
+}
+
+// listRequest holds the query parameters of the list calls.
+type listRequest struct {
+	Offset *int64 `form:"offset"`
+	Limit  *int64 `form:"limit"`
+}
+
+// ListSecrets returns all secrets, like the ListSecrets rpc.
+func (g *Gateway) ListSecrets(c *gin.Context) {
+	var r listRequest
+	if err := c.ShouldBindQuery(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	g.invoke(c, "/proto.Cache/ListSecrets", &pb.ListSecretsRequest{Offset: r.Offset, Limit: r.Limit},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.cache.ListSecrets(ctx, req.(*pb.ListSecretsRequest))
+		})
+}
+
+// ListPolicies returns all policies, like the ListPolicies rpc.
+func (g *Gateway) ListPolicies(c *gin.Context) {
+	var r listRequest
+	if err := c.ShouldBindQuery(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	g.invoke(c, "/proto.Cache/ListPolicies", &pb.ListPoliciesRequest{Offset: r.Offset, Limit: r.Limit},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.cache.ListPolicies(ctx, req.(*pb.ListPoliciesRequest))
+		})
+}
+
+// invoke runs the handler of method through the interceptors and writes the response.
+func (g *Gateway) invoke(c *gin.Context, method string, req proto.Message, handler grpc.UnaryHandler) {
+	info := &grpc.UnaryServerInfo{Server: g.cache, FullMethod: method}
+
+	resp, err := g.interceptor(incomingContext(c), req, info, handler)
+	if err != nil {
+		core.WriteResponse(c, gatewayError(err), nil)
+
+		return
+	}
+
+	data, err := marshaler.Marshal(resp.(proto.Message))
+	if err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrEncodingJSON, err.Error()), nil)
+
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
+
+// incomingContext returns the context of a grpc call carrying the forwarded headers as
+// metadata and the client address and tls state of the http request as peer.
+func incomingContext(c *gin.Context) context.Context {
+	md := metadata.MD{}
+	for _, header := range forwardedHeaders {
+		if value := c.GetHeader(header); value != "" {
+			md.Set(strings.ToLower(header), value)
+		}
+	}
+
+	p := &peer.Peer{Addr: remoteAddr(c.Request.RemoteAddr)}
+	if c.Request.TLS != nil {
+		p.AuthInfo = credentials.TLSInfo{State: *c.Request.TLS}
+	}
+
+	return peer.NewContext(metadata.NewIncomingContext(c, md), p)
+}
+
+func remoteAddr(addr string) net.Addr {
+	tcpAddr, err := net.ResolveTCPAddr("tcp", addr)
+	if err != nil {
+		return &net.TCPAddr{}
+	}
+
+	return tcpAddr
+}
+
+// gatewayError converts the grpc status errors of the interceptors into coded errors,
+// errors of the cache server are coded already.
+func gatewayError(err error) error {
+	s, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	switch s.Code() {
+	case codes.Unauthenticated:
+		return errors.WithCode(code.ErrTokenInvalid, s.Message())
+	case codes.PermissionDenied:
+		return errors.WithCode(code.ErrPermissionDenied, s.Message())
+	default:
+		return errors.WithCode(code.ErrUnknown, s.Message())
+	}
+}
@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	pb "github.com/rose839/IAM/api/proto/apiserver/v1"
+	"github.com/rose839/IAM/internal/apiserver/store"
+	"github.com/rose839/IAM/internal/apiserver/store/mysql/mysqltest"
+	"github.com/rose839/IAM/internal/pkg/interceptor"
+	"github.com/rose839/IAM/internal/pkg/middleware"
+)
+
+func TestGateway(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	factory := mysqltest.NewFactory(t)
+	store.SetClient(factory)
+	for _, user := range []*v1.User{
+		{ObjectMeta: metav1.ObjectMeta{Name: "admin"}, Password: "Admin@2021", Email: "admin@foxmail.com", IsAdmin: 1},
+		{ObjectMeta: metav1.ObjectMeta{Name: "colin"}, Password: "Admin@2021", Email: "colin@foxmail.com"},
+		// anyone may sign up a user named like a grpc caller.
+		{ObjectMeta: metav1.ObjectMeta{Name: "iam-authz-server"}, Password: "Admin@2021", Email: "authz@foxmail.com"},
+	} {
+		if err := factory.Users().Create(context.Background(), user, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("create user %s: %v", user.Name, err)
+		}
+	}
+
+	g := &Gateway{
+		cache:       &Cache{},
+		interceptor: interceptor.UnaryRecovery(),
+	}
+
+	engine := gin.New()
+	engine.GET("/v1/cache/secrets", middleware.HTTPSOnly(), func(c *gin.Context) {
+		c.Set(middleware.UsernameKey, c.GetHeader("X-User"))
+	}, middleware.Admin(), func(c *gin.Context) {
+		g.invoke(c, "/proto.Cache/ListSecrets", &pb.ListSecretsRequest{},
+			func(ctx context.Context, req interface{}) (interface{}, error) {
+				return &pb.ListSecretsResponse{
+					TotalCount: 1,
+					Items:      []*pb.SecretInfo{{SecretId: "id", Username: "colin"}},
+				}, nil
+			})
+	})
+
+	tests := []struct {
+		name       string
+		insecure   bool
+		user       string
+		wantStatus int
+	}{
+		{"administrator", false, "admin", http.StatusOK},
+		{"other user", false, "colin", http.StatusForbidden},
+		{"user named like a grpc caller", false, "iam-authz-server", http.StatusForbidden},
+		{"insecure server", true, "admin", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/cache/secrets", nil)
+			req.Header.Set("X-User", tt.user)
+			if !tt.insecure {
+				req.TLS = &tls.ConnectionState{}
+			}
+
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				TotalCount string `json:"totalCount"`
+				Items      []struct {
+					SecretID string   `json:"secretId"`
+					Username string   `json:"username"`
+					Groups   []string `json:"groups"`
+				} `json:"items"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("unmarshal body %s: %v", w.Body.String(), err)
+			}
+
+			if resp.TotalCount != "1" || len(resp.Items) != 1 || resp.Items[0].SecretID != "id" ||
+				resp.Items[0].Username != "colin" || resp.Items[0].Groups == nil {
+				t.Errorf("unexpected response %s", w.Body.String())
+			}
+		})
+	}
+}
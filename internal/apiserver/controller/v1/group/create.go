@@ -0,0 +1,35 @@
+package group
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/core"
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+// Create add new group with its initial members to the storage.
+func (g *GroupController) Create(c *gin.Context) {
+	var r v1.Group
+
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	if errs := r.Validate(); len(errs) != 0 {
+		core.WriteResponse(c, errors.WithCode(code.ErrValidation, errs.ToAggregate().Error()), nil)
+
+		return
+	}
+
+	if err := g.srv.Groups().Create(c, &r, metav1.CreateOptions{}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, r)
+}
@@ -0,0 +1,18 @@
+package group
+
+import (
+	"github.com/gin-gonic/gin"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/pkg/core"
+)
+
+// Delete delete a group by the group identifier.
+func (g *GroupController) Delete(c *gin.Context) {
+	if err := g.srv.Groups().Delete(c, c.Param("name"), metav1.DeleteOptions{Unscoped: true}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
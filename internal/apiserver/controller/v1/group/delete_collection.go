@@ -0,0 +1,18 @@
+package group
+
+import (
+	"github.com/gin-gonic/gin"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/pkg/core"
+)
+
+// DeleteCollection batch delete groups by group names.
+func (g *GroupController) DeleteCollection(c *gin.Context) {
+	if err := g.srv.Groups().DeleteCollection(c, c.QueryArray("name"), metav1.DeleteOptions{Unscoped: true}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
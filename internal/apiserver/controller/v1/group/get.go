@@ -0,0 +1,19 @@
+package group
+
+import (
+	"github.com/gin-gonic/gin"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/pkg/core"
+)
+
+// Get get a group and its members by the group identifier.
+func (g *GroupController) Get(c *gin.Context) {
+	group, err := g.srv.Groups().Get(c, c.Param("name"), metav1.GetOptions{})
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, group)
+}
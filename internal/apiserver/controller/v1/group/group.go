@@ -0,0 +1,18 @@
+package group
+
+import (
+	srvv1 "github.com/rose839/IAM/internal/apiserver/service/v1"
+	"github.com/rose839/IAM/internal/apiserver/store"
+)
+
+// GroupController create a group handler used to handle request for group resource.
+type GroupController struct {
+	srv srvv1.Service
+}
+
+// NewGroupController creates a group handler.
+func NewGroupController(store store.Factory) *GroupController {
+	return &GroupController{
+		srv: srvv1.NewService(store),
+	}
+}
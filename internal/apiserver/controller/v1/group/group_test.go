@@ -0,0 +1,105 @@
+package group
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/apiserver/store/mysql/mysqltest"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/core"
+)
+
+func newTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	factory := mysqltest.NewFactory(t)
+	user := &v1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "colin"},
+		Nickname:   "colin",
+		Password:   "Admin@2021",
+		Email:      "colin@foxmail.com",
+	}
+	if err := factory.Users().Create(context.Background(), user, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	g := NewGroupController(factory)
+	r := gin.New()
+	r.POST("/v1/groups", g.Create)
+	r.GET("/v1/groups/:name", g.Get)
+	r.POST("/v1/groups/:name/members", g.AddMembers)
+	r.DELETE("/v1/groups/:name/members/:member", g.RemoveMember)
+
+	return r
+}
+
+func serve(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestGroupController(t *testing.T) {
+	r := newTestRouter(t)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantCode   int
+	}{
+		{"create", http.MethodPost, "/v1/groups", `{"metadata":{"name":"dev"},"members":["colin"]}`, http.StatusOK, 0},
+		{"create bad body", http.MethodPost, "/v1/groups", `{"metadata":`, http.StatusBadRequest, code.ErrBind},
+		{"create unknown member", http.MethodPost, "/v1/groups", `{"metadata":{"name":"ops"},"members":["bob"]}`,
+			http.StatusNotFound, code.ErrUserNotFound},
+		{"get", http.MethodGet, "/v1/groups/dev", "", http.StatusOK, 0},
+		{"get rolled back group", http.MethodGet, "/v1/groups/ops", "", http.StatusNotFound, code.ErrGroupNotFound},
+		{"add members", http.MethodPost, "/v1/groups/dev/members", `{"members":["colin"]}`, http.StatusOK, 0},
+		{"add no members", http.MethodPost, "/v1/groups/dev/members", `{"members":[]}`,
+			http.StatusBadRequest, code.ErrBind},
+		{"add members of unknown group", http.MethodPost, "/v1/groups/ops/members", `{"members":["colin"]}`,
+			http.StatusNotFound, code.ErrGroupNotFound},
+		{"remove member", http.MethodDelete, "/v1/groups/dev/members/colin", "", http.StatusOK, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(r, tt.method, tt.path, tt.body)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			if tt.wantCode != 0 {
+				var resp core.ErrResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("unmarshal body %s: %v", w.Body.String(), err)
+				}
+				if resp.Code != tt.wantCode {
+					t.Errorf("code = %d, want %d", resp.Code, tt.wantCode)
+				}
+			}
+		})
+	}
+
+	// the member removed last is not listed any more.
+	var group v1.Group
+	if err := json.Unmarshal(serve(r, http.MethodGet, "/v1/groups/dev", "").Body.Bytes(), &group); err != nil {
+		t.Fatal(err)
+	}
+	if len(group.Members) != 0 {
+		t.Errorf("members = %v, want none", group.Members)
+	}
+}
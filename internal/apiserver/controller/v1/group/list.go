@@ -0,0 +1,28 @@
+package group
+
+import (
+	"github.com/gin-gonic/gin"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/core"
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+// List list the groups in the storage.
+func (g *GroupController) List(c *gin.Context) {
+	var r metav1.ListOptions
+	if err := c.ShouldBindQuery(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	groups, err := g.srv.Groups().List(c, r)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, groups)
+}
@@ -0,0 +1,52 @@
+package group
+
+import (
+	"github.com/gin-gonic/gin"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/core"
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+// AddMembersRequest defines the AddMembersRequest data format.
+type AddMembersRequest struct {
+	// Usernames of the users to add.
+	// Required: true
+	Members []string `json:"members" binding:"required,min=1"`
+}
+
+// AddMembers adds users to a group.
+func (g *GroupController) AddMembers(c *gin.Context) {
+	var r AddMembersRequest
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	name := c.Param("name")
+	if _, err := g.srv.Groups().Get(c, name, metav1.GetOptions{}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	if err := g.srv.Groups().AddMembers(c, name, r.Members); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
+
+// RemoveMember removes an user from a group.
+func (g *GroupController) RemoveMember(c *gin.Context) {
+	if err := g.srv.Groups().RemoveMembers(c, c.Param("name"), []string{c.Param("member")}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
@@ -0,0 +1,38 @@
+package policy
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/internal/pkg/middleware"
+	"github.com/rose839/IAM/pkg/core"
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+// Apply creates, updates and deletes the policies of the user in one transaction to
+// match the posted policy list and returns the changes, a dry run only reports them.
+// An empty list is refused unless allowEmpty is set.
+func (p *PolicyController) Apply(c *gin.Context) {
+	var opts v1.PolicyApplyOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	var r v1.PolicyList
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	result, err := p.srv.Policies().Apply(c, c.GetString(middleware.UsernameKey), r.Items, opts)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, result)
+}
@@ -0,0 +1,54 @@
+package serviceaccount
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/internal/pkg/middleware"
+	"github.com/rose839/IAM/pkg/core"
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+// Create add new service account to the storage, it is owned by the current user
+// unless another owner is given.
+func (s *ServiceAccountController) Create(c *gin.Context) {
+	var r v1.ServiceAccount
+
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	if r.Owner == "" {
+		r.Owner = v1.UserOwner(c.GetString(middleware.UsernameKey))
+	}
+
+	if errs := r.Validate(); len(errs) != 0 {
+		core.WriteResponse(c, errors.WithCode(code.ErrValidation, errs.ToAggregate().Error()), nil)
+
+		return
+	}
+
+	owners, err := s.owners(c)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	if !canOwn(owners, r.Owner) {
+		core.WriteResponse(c, errors.WithCode(code.ErrPermissionDenied, "Can not create service account for `%s`.", r.Owner), nil)
+
+		return
+	}
+
+	if err := s.srv.ServiceAccounts().Create(c, &r, metav1.CreateOptions{}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, r)
+}
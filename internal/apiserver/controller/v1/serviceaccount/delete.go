@@ -0,0 +1,18 @@
+package serviceaccount
+
+import (
+	"github.com/gin-gonic/gin"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/pkg/core"
+)
+
+// Delete delete a service account and the secrets and policies it owns by the service account identifier.
+func (s *ServiceAccountController) Delete(c *gin.Context) {
+	if err := s.srv.ServiceAccounts().Delete(c, c.Param("name"), metav1.DeleteOptions{Unscoped: true}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
@@ -0,0 +1,19 @@
+package serviceaccount
+
+import (
+	"github.com/gin-gonic/gin"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/pkg/core"
+)
+
+// Get get a service account by the service account identifier.
+func (s *ServiceAccountController) Get(c *gin.Context) {
+	sa, err := s.srv.ServiceAccounts().Get(c, c.Param("name"), metav1.GetOptions{})
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, sa)
+}
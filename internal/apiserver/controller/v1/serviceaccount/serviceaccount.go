@@ -0,0 +1,52 @@
+package serviceaccount
+
+import (
+	"github.com/gin-gonic/gin"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	srvv1 "github.com/rose839/IAM/internal/apiserver/service/v1"
+	"github.com/rose839/IAM/internal/apiserver/store"
+	"github.com/rose839/IAM/internal/pkg/middleware"
+)
+
+// ServiceAccountController create a service account handler used to handle request for service account resource.
+type ServiceAccountController struct {
+	srv srvv1.Service
+}
+
+// NewServiceAccountController creates a service account handler.
+func NewServiceAccountController(store store.Factory) *ServiceAccountController {
+	return &ServiceAccountController{
+		srv: srvv1.NewService(store),
+	}
+}
+
+// owners returns the owners whose service accounts the current user can manage,
+// nil means all of them.
+func (s *ServiceAccountController) owners(c *gin.Context) ([]string, error) {
+	username := c.GetString(middleware.UsernameKey)
+	user, err := s.srv.Users().Get(c, username, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsAdmin == 1 {
+		return nil, nil
+	}
+
+	return s.srv.ServiceAccounts().Owners(c, username)
+}
+
+// canOwn reports whether owner is one of owners, a nil owners allows everything.
+func canOwn(owners []string, owner string) bool {
+	if owners == nil {
+		return true
+	}
+
+	for _, o := range owners {
+		if o == owner {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,63 @@
+package serviceaccount
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/core"
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+// Update update a service account info by the service account identifier. The owner
+// can only be handed over to an owner the current user belongs to.
+func (s *ServiceAccountController) Update(c *gin.Context) {
+	var r v1.ServiceAccount
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	sa, err := s.srv.ServiceAccounts().Get(c, c.Param("name"), metav1.GetOptions{})
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	if r.Owner != "" && r.Owner != sa.Owner {
+		owners, err := s.owners(c)
+		if err != nil {
+			core.WriteResponse(c, err, nil)
+
+			return
+		}
+
+		if !canOwn(owners, r.Owner) {
+			core.WriteResponse(c, errors.WithCode(code.ErrPermissionDenied, "Can not hand over to `%s`.", r.Owner), nil)
+
+			return
+		}
+
+		sa.Owner = r.Owner
+	}
+
+	// only update owner, description and extend
+	sa.Description = r.Description
+	sa.Extend = r.Extend
+
+	if errs := sa.Validate(); len(errs) != 0 {
+		core.WriteResponse(c, errors.WithCode(code.ErrValidation, errs.ToAggregate().Error()), nil)
+
+		return
+	}
+
+	if err := s.srv.ServiceAccounts().Update(c, sa, metav1.UpdateOptions{}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, sa)
+}
@@ -0,0 +1,38 @@
+package snapshot
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rose839/IAM/internal/apiserver/snapshot"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/core"
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+// Export returns a snapshot of all users, secrets and policies in the json or yaml format,
+// the secret keys are encrypted when a passphrase is given.
+func (s *SnapshotController) Export(c *gin.Context) {
+	format := c.DefaultQuery("format", snapshot.FormatJSON)
+
+	snap, err := s.srv.Snapshots().Export(c, c.GetHeader(PassphraseHeader))
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	data, err := snapshot.Marshal(snap, format)
+	if err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrValidation, err.Error()), nil)
+
+		return
+	}
+
+	contentType := "application/json; charset=utf-8"
+	if format == snapshot.FormatYAML {
+		contentType = "application/yaml; charset=utf-8"
+	}
+
+	c.Data(http.StatusOK, contentType, data)
+}
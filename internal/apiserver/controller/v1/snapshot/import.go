@@ -0,0 +1,47 @@
+package snapshot
+
+import (
+	"io"
+
+	"github.com/gin-gonic/gin"
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	"github.com/rose839/IAM/internal/apiserver/snapshot"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/core"
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+// Import imports a json or yaml snapshot in the create-only, upsert or replace mode and
+// returns the changes, a dry run only reports them.
+func (s *SnapshotController) Import(c *gin.Context) {
+	opts := v1.SnapshotImportOptions{Mode: v1.SnapshotModeCreateOnly}
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+	opts.Passphrase = c.GetHeader(PassphraseHeader)
+
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	snap, err := snapshot.Unmarshal(data)
+	if err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	result, err := s.srv.Snapshots().Import(c, snap, opts)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, result)
+}
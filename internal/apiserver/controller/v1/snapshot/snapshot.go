@@ -0,0 +1,21 @@
+package snapshot
+
+import (
+	srvv1 "github.com/rose839/IAM/internal/apiserver/service/v1"
+	"github.com/rose839/IAM/internal/apiserver/store"
+)
+
+// PassphraseHeader carries the passphrase the secret keys of a snapshot are encrypted with.
+const PassphraseHeader = "X-IAM-Snapshot-Passphrase"
+
+// SnapshotController create a snapshot handler used to export and import snapshots.
+type SnapshotController struct {
+	srv srvv1.Service
+}
+
+// NewSnapshotController creates a snapshot handler.
+func NewSnapshotController(store store.Factory) *SnapshotController {
+	return &SnapshotController{
+		srv: srvv1.NewService(store),
+	}
+}
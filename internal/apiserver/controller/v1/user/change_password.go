@@ -6,6 +6,7 @@ import (
 	"github.com/rose839/IAM/internal/pkg/code"
 	"github.com/rose839/IAM/pkg/core"
 	"github.com/rose839/IAM/pkg/errors"
+	"github.com/rose839/IAM/pkg/validation"
 )
 
 // ChangePasswordRequest defines the ChangePasswordRequest data format.
@@ -16,7 +17,7 @@ type ChangePasswordRequest struct {
 
 	// New password.
 	// Required: true
-	NewPassword string `json:"newPassword" binding:"password"`
+	NewPassword string `json:"newPassword" binding:"required"`
 }
 
 func (u *UserController) ChangePassword(c *gin.Context) {
@@ -41,6 +42,12 @@ func (u *UserController) ChangePassword(c *gin.Context) {
 		return
 	}
 
+	if err := validation.IsValidUserPassword(user.Name, r.NewPassword); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrValidation, err.Error()), nil)
+
+		return
+	}
+
 	user.Password = r.NewPassword
 	if err := u.srv.Users().ChangePassword(c, user); err != nil {
 		core.WriteResponse(c, err, nil)
@@ -7,6 +7,7 @@ import (
 	"github.com/rose839/IAM/internal/pkg/code"
 	"github.com/rose839/IAM/pkg/core"
 	"github.com/rose839/IAM/pkg/errors"
+	"github.com/rose839/IAM/pkg/log"
 )
 
 func (u *UserController) Create(c *gin.Context) {
@@ -22,6 +23,13 @@ func (u *UserController) Create(c *gin.Context) {
 		return
 	}
 
+	// email address must be verified through the verification flow.
+	r.EmailVerified = false
+
+	// service accounts are created through their own api.
+	r.Type = v1.UserTypeHuman
+	r.Owner = ""
+
 	// Insert the user to the storage.
 	if err := u.srv.Users().Create(c, &r, metav1.CreateOptions{}); err != nil {
 		core.WriteResponse(c, err, nil)
@@ -29,5 +37,10 @@ func (u *UserController) Create(c *gin.Context) {
 		return
 	}
 
+	// the user can ask for another verification email if this one got lost.
+	if err := u.srv.Users().RequestEmailVerification(c, r.Name); err != nil {
+		log.L(c).Warnf("Send verification email to user `%s` failed: %s", r.Name, err.Error())
+	}
+
 	core.WriteResponse(c, nil, r)
 }
@@ -0,0 +1,46 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/core"
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+// ConfirmEmailVerificationRequest defines the ConfirmEmailVerificationRequest data format.
+type ConfirmEmailVerificationRequest struct {
+	// Token received by email.
+	// Required: true
+	Token string `json:"token" binding:"required"`
+}
+
+// RequestEmailVerification sends an email verification token to the email address of an user.
+func (u *UserController) RequestEmailVerification(c *gin.Context) {
+	if err := u.srv.Users().RequestEmailVerification(c, c.Param("name")); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
+
+// ConfirmEmailVerification marks the email address of an user as verified with an
+// email verification token.
+func (u *UserController) ConfirmEmailVerification(c *gin.Context) {
+	var r ConfirmEmailVerificationRequest
+
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	if err := u.srv.Users().VerifyEmail(c, r.Token); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
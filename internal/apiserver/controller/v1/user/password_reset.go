@@ -0,0 +1,65 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/core"
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+// PasswordResetRequest defines the PasswordResetRequest data format.
+type PasswordResetRequest struct {
+	// Name of the user who forgot the password.
+	// Required: true
+	Username string `json:"username" binding:"required"`
+}
+
+// ConfirmPasswordResetRequest defines the ConfirmPasswordResetRequest data format.
+type ConfirmPasswordResetRequest struct {
+	// Token received by email.
+	// Required: true
+	Token string `json:"token" binding:"required"`
+
+	// New password.
+	// Required: true
+	NewPassword string `json:"newPassword" binding:"required"`
+}
+
+// RequestPasswordReset sends a password reset token to the email address of an user.
+// The response is the same whether the user exists or not.
+func (u *UserController) RequestPasswordReset(c *gin.Context) {
+	var r PasswordResetRequest
+
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	if err := u.srv.Users().RequestPasswordReset(c, r.Username); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
+
+// ConfirmPasswordReset sets a new password with a password reset token.
+func (u *UserController) ConfirmPasswordReset(c *gin.Context) {
+	var r ConfirmPasswordResetRequest
+
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	if err := u.srv.Users().ResetPassword(c, r.Token, r.NewPassword); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
@@ -0,0 +1,36 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/apiserver/lockout"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/core"
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+// Unlock clears the login lockout of an user.
+// Only administrator can call this function.
+func (u *UserController) Unlock(c *gin.Context) {
+	user, err := u.srv.Users().Get(c, c.Param("name"), metav1.GetOptions{})
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	guard, err := lockout.GetGuardOr(nil)
+	if err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrUnknown, err.Error()), nil)
+
+		return
+	}
+
+	if err := guard.Unlock(c, user.Name); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrUnknown, err.Error()), nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/rose839/IAM/internal/pkg/code"
 	"github.com/rose839/IAM/pkg/core"
 	"github.com/rose839/IAM/pkg/errors"
+	"github.com/rose839/IAM/pkg/log"
 )
 
 func (u *UserController) Update(c *gin.Context) {
@@ -25,6 +26,11 @@ func (u *UserController) Update(c *gin.Context) {
 		return
 	}
 
+	emailChanged := user.Email != r.Email
+	if emailChanged {
+		user.EmailVerified = false
+	}
+
 	user.Nickname = r.Nickname
 	user.Email = r.Email
 	user.Phone = r.Phone
@@ -43,5 +49,11 @@ func (u *UserController) Update(c *gin.Context) {
 		return
 	}
 
+	if emailChanged {
+		if err := u.srv.Users().RequestEmailVerification(c, user.Name); err != nil {
+			log.L(c).Warnf("Send verification email to user `%s` failed: %s", user.Name, err.Error())
+		}
+	}
+
 	core.WriteResponse(c, nil, user)
 }
@@ -0,0 +1,18 @@
+package webhook
+
+import (
+	"github.com/gin-gonic/gin"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/pkg/core"
+)
+
+// Delete delete a webhook and its delivery log by the webhook identifier.
+func (w *WebhookController) Delete(c *gin.Context) {
+	if err := w.srv.Webhooks().Delete(c, c.Param("name"), metav1.DeleteOptions{Unscoped: true}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
@@ -0,0 +1,29 @@
+package webhook
+
+import (
+	"github.com/gin-gonic/gin"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/core"
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+// ListDeliveries list the delivery log of a webhook, newest first. The field selector
+// filters by status and event, e.g. fieldSelector=status=failed.
+func (w *WebhookController) ListDeliveries(c *gin.Context) {
+	var r metav1.ListOptions
+	if err := c.ShouldBindQuery(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	deliveries, err := w.srv.Webhooks().ListDeliveries(c, c.Param("name"), r)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, deliveries)
+}
@@ -0,0 +1,20 @@
+package webhook
+
+import (
+	"github.com/gin-gonic/gin"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/pkg/core"
+)
+
+// Get get a webhook by the webhook identifier, without its secret.
+func (w *WebhookController) Get(c *gin.Context) {
+	webhook, err := w.srv.Webhooks().Get(c, c.Param("name"), metav1.GetOptions{})
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	webhook.Secret = ""
+	core.WriteResponse(c, nil, webhook)
+}
@@ -0,0 +1,32 @@
+package webhook
+
+import (
+	"github.com/gin-gonic/gin"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/core"
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+// List list the webhooks in the storage, without their secrets.
+func (w *WebhookController) List(c *gin.Context) {
+	var r metav1.ListOptions
+	if err := c.ShouldBindQuery(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	webhooks, err := w.srv.Webhooks().List(c, r)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	for _, webhook := range webhooks.Items {
+		webhook.Secret = ""
+	}
+
+	core.WriteResponse(c, nil, webhooks)
+}
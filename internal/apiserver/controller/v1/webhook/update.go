@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/core"
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+// Update update a webhook info by the webhook identifier, the secret is kept when none is given
+// and is not returned.
+func (w *WebhookController) Update(c *gin.Context) {
+	var r v1.Webhook
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	webhook, err := w.srv.Webhooks().Get(c, c.Param("name"), metav1.GetOptions{})
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	// only update url, events, secret and extend
+	webhook.URL = r.URL
+	webhook.Events = r.Events
+	webhook.Extend = r.Extend
+	if r.Secret != "" {
+		webhook.Secret = r.Secret
+	}
+
+	if errs := webhook.Validate(); len(errs) != 0 {
+		core.WriteResponse(c, errors.WithCode(code.ErrValidation, errs.ToAggregate().Error()), nil)
+
+		return
+	}
+
+	if err := w.srv.Webhooks().Update(c, webhook, metav1.UpdateOptions{}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	webhook.Secret = ""
+	core.WriteResponse(c, nil, webhook)
+}
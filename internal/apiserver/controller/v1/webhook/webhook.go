@@ -0,0 +1,18 @@
+package webhook
+
+import (
+	srvv1 "github.com/rose839/IAM/internal/apiserver/service/v1"
+	"github.com/rose839/IAM/internal/apiserver/store"
+)
+
+// WebhookController create a webhook handler used to handle request for webhook resource.
+type WebhookController struct {
+	srv srvv1.Service
+}
+
+// NewWebhookController creates a webhook handler.
+func NewWebhookController(store store.Factory) *WebhookController {
+	return &WebhookController{
+		srv: srvv1.NewService(store),
+	}
+}
@@ -5,11 +5,16 @@ import (
 
 	"github.com/rose839/IAM/pkg/log"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 type grpcAPIServer struct {
 	*grpc.Server
 	address string
+
+	// health serves grpc.health.v1.Health, its status follows the readiness of the apiserver.
+	health *health.Server
 }
 
 func (s *grpcAPIServer) Run() {
@@ -27,7 +32,21 @@ func (s *grpcAPIServer) Run() {
 	log.Infof("start grpc server at %s", s.address)
 }
 
+// SetServingStatus sets the health status of the server and all its services.
+func (s *grpcAPIServer) SetServingStatus(serving bool) {
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if serving {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+
+	s.health.SetServingStatus("", status)
+	for service := range s.GetServiceInfo() {
+		s.health.SetServingStatus(service, status)
+	}
+}
+
 func (s *grpcAPIServer) Close() {
+	s.health.Shutdown()
 	s.GracefulStop()
 	log.Infof("GRPC server on %s stopped", s.address)
 }
@@ -0,0 +1,93 @@
+// Package impersonation decides whether an administrator may act as another user.
+// Only administrators who are members of the configured group can impersonate,
+// and administrators can never be impersonated, so impersonation can not be used
+// to escalate privileges or to hide behind another administrator.
+package impersonation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/apiserver/store"
+	"github.com/rose839/IAM/internal/pkg/code"
+	genericoptions "github.com/rose839/IAM/internal/pkg/options"
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+// Impersonator authorizes impersonation requests.
+type Impersonator struct {
+	opts *genericoptions.ImpersonationOptions
+}
+
+var (
+	impersonator *Impersonator
+	once         sync.Once
+)
+
+// GetImpersonatorOr return impersonator instance with given options.
+func GetImpersonatorOr(opts *genericoptions.ImpersonationOptions) (*Impersonator, error) {
+	if opts != nil {
+		once.Do(func() {
+			impersonator = &Impersonator{opts: opts}
+		})
+	}
+
+	if impersonator == nil {
+		return nil, fmt.Errorf("got nil impersonator")
+	}
+
+	return impersonator, nil
+}
+
+// Authorize returns an error if operator is not allowed to impersonate target.
+func (i *Impersonator) Authorize(ctx context.Context, operator, target string) error {
+	if !i.opts.Enabled {
+		return errors.WithCode(code.ErrPermissionDenied, "Impersonation is disabled.")
+	}
+
+	if operator == target {
+		return errors.WithCode(code.ErrPermissionDenied, "User `%s` can not impersonate themselves.", operator)
+	}
+
+	user, err := store.Client().Users().Get(ctx, operator, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	if user.IsAdmin != 1 {
+		return errors.WithCode(code.ErrPermissionDenied, "Only administrators can impersonate users.")
+	}
+
+	groups, err := store.Client().Groups().ListByUsers(ctx, []string{operator})
+	if err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	if !contains(groups[operator], i.opts.Group) {
+		return errors.WithCode(code.ErrPermissionDenied,
+			"User `%s` is not a member of group `%s` which may impersonate users.", operator, i.opts.Group)
+	}
+
+	targetUser, err := store.Client().Users().Get(ctx, target, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	if targetUser.IsAdmin == 1 {
+		return errors.WithCode(code.ErrPermissionDenied, "Administrator `%s` can not be impersonated.", target)
+	}
+
+	return nil
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,172 @@
+package impersonation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/apiserver/audit"
+	"github.com/rose839/IAM/internal/apiserver/store"
+	"github.com/rose839/IAM/internal/apiserver/store/mysql/mysqltest"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/internal/pkg/middleware"
+	genericoptions "github.com/rose839/IAM/internal/pkg/options"
+	"github.com/rose839/IAM/pkg/core"
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+// newTestStore sets a store with the administrators colin, member of the impersonators
+// group, and root, who is not, and the users alice and bob, who is an administrator.
+func newTestStore(t *testing.T) {
+	t.Helper()
+
+	factory := mysqltest.NewFactory(t)
+	store.SetClient(factory)
+	ctx := context.Background()
+
+	for _, u := range []struct {
+		name    string
+		isAdmin int
+	}{{"colin", 1}, {"root", 1}, {"alice", 0}, {"bob", 1}} {
+		user := &v1.User{
+			ObjectMeta: metav1.ObjectMeta{Name: u.name},
+			Nickname:   u.name,
+			Password:   "Admin@2021",
+			Email:      u.name + "@foxmail.com",
+			IsAdmin:    u.isAdmin,
+		}
+		if err := factory.Users().Create(ctx, user, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("create user %s: %v", u.name, err)
+		}
+	}
+
+	group := &v1.Group{ObjectMeta: metav1.ObjectMeta{Name: "impersonators"}}
+	if err := factory.Groups().Create(ctx, group, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+	if err := factory.Groups().AddMembers(ctx, "impersonators", []string{"colin", "alice"}); err != nil {
+		t.Fatalf("add members: %v", err)
+	}
+}
+
+func TestImpersonator_Authorize(t *testing.T) {
+	newTestStore(t)
+
+	tests := []struct {
+		name     string
+		disabled bool
+		operator string
+		target   string
+		wantCode int
+	}{
+		{"member administrator", false, "colin", "alice", code.ErrSuccess},
+		{"disabled", true, "colin", "alice", code.ErrPermissionDenied},
+		{"member not administrator", false, "alice", "colin", code.ErrPermissionDenied},
+		{"administrator not member", false, "root", "alice", code.ErrPermissionDenied},
+		{"administrator target", false, "colin", "bob", code.ErrPermissionDenied},
+		{"themselves", false, "colin", "colin", code.ErrPermissionDenied},
+		{"unknown target", false, "colin", "tom", code.ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := genericoptions.NewImpersonationOptions()
+			opts.Enabled = !tt.disabled
+			i := &Impersonator{opts: opts}
+
+			err := i.Authorize(context.Background(), tt.operator, tt.target)
+			if tt.wantCode == code.ErrSuccess {
+				if err != nil {
+					t.Errorf("Authorize() error = %v, want nil", err)
+				}
+
+				return
+			}
+			if !errors.IsCode(err, tt.wantCode) {
+				t.Errorf("Authorize() error = %v, want code %d", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestImpersonate_Audit(t *testing.T) {
+	newTestStore(t)
+	gin.SetMode(gin.TestMode)
+
+	opts := genericoptions.NewImpersonationOptions()
+	opts.Enabled = true
+	i := &Impersonator{opts: opts}
+
+	auditOpts := genericoptions.NewAuditOptions()
+	auditOpts.Level = string(audit.LevelMetadata)
+	auditOpts.Sinks = []string{"mysql"}
+	auditor, err := audit.GetAuditorOr(auditOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var handledAs string
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(middleware.UsernameKey, c.GetHeader("X-Test-User"))
+	}, middleware.Impersonate(i.Authorize), auditor.Handler())
+	r.PUT("/v1/users/:name", func(c *gin.Context) {
+		handledAs = c.GetString(middleware.UsernameKey)
+		core.WriteResponse(c, nil, nil)
+	})
+
+	tests := []struct {
+		name         string
+		user         string
+		impersonate  string
+		wantStatus   int
+		wantUsername string
+		wantOperator string
+	}{
+		{"impersonated", "colin", "alice", http.StatusOK, "alice", "colin"},
+		{"not impersonated", "colin", "", http.StatusOK, "colin", ""},
+		{"refused", "alice", "colin", http.StatusForbidden, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handledAs = ""
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/v1/users/alice", nil)
+			req.Header.Set("X-Test-User", tt.user)
+			if tt.impersonate != "" {
+				req.Header.Set(middleware.ImpersonateUserHeader, tt.impersonate)
+			}
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if handledAs != tt.wantUsername {
+				t.Errorf("request handled as `%s`, want `%s`", handledAs, tt.wantUsername)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			events, err := store.Client().AuditEvents().List(context.Background(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("list audit events: %v", err)
+			}
+			if len(events.Items) == 0 {
+				t.Fatal("no audit event recorded")
+			}
+
+			// events are listed newest first.
+			event := events.Items[0]
+			if event.Username != tt.wantUsername || event.Operator != tt.wantOperator {
+				t.Errorf("audit event username `%s` operator `%s`, want `%s` `%s`",
+					event.Username, event.Operator, tt.wantUsername, tt.wantOperator)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package lockout
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/rose839/IAM/internal/pkg/code"
+	genericoptions "github.com/rose839/IAM/internal/pkg/options"
+	"github.com/rose839/IAM/pkg/errors"
+	"github.com/rose839/IAM/pkg/storage"
+)
+
+var redisServer *miniredis.Miniredis
+
+func TestMain(m *testing.M) {
+	redisServer = miniredis.NewMiniRedis()
+	if err := redisServer.Start(); err != nil {
+		panic(err)
+	}
+
+	port, _ := strconv.Atoi(redisServer.Port())
+	ctx, cancel := context.WithCancel(context.Background())
+	go storage.ConnectToRedis(ctx, &storage.Config{Host: redisServer.Host(), Port: port})
+	for i := 0; i < 50 && !storage.Connected(); i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	code := m.Run()
+
+	cancel()
+	redisServer.Close()
+	os.Exit(code)
+}
+
+func newTestGuard(t *testing.T) *Guard {
+	t.Helper()
+
+	if !storage.Connected() {
+		t.Fatal("redis is not connected")
+	}
+	redisServer.FlushAll()
+
+	opts := genericoptions.NewLockoutOptions()
+	opts.MaxAttempts = 3
+	opts.IPMaxAttempts = 5
+
+	return &Guard{opts: opts, store: &storage.RedisCluster{KeyPrefix: keyPrefix}}
+}
+
+func TestGuard_FailLocksUser(t *testing.T) {
+	g := newTestGuard(t)
+	ctx := context.Background()
+
+	for i := 0; i < g.opts.MaxAttempts-1; i++ {
+		g.Fail(ctx, "colin", "10.0.0.1")
+		if err := g.Check("colin", "10.0.0.1"); err != nil {
+			t.Fatalf("Check() after %d failures = %v, want nil", i+1, err)
+		}
+	}
+
+	g.Fail(ctx, "colin", "10.0.0.1")
+	if err := g.Check("colin", "10.0.0.2"); !errors.IsCode(err, code.ErrAccountLocked) {
+		t.Fatalf("Check() after %d failures = %v, want ErrAccountLocked", g.opts.MaxAttempts, err)
+	}
+	if err := g.Check("admin", "10.0.0.1"); err != nil {
+		t.Errorf("Check() of another user = %v, want nil", err)
+	}
+
+	if err := g.Unlock(ctx, "colin"); err != nil {
+		t.Fatalf("Unlock() = %v", err)
+	}
+	if err := g.Check("colin", "10.0.0.1"); err != nil {
+		t.Errorf("Check() after Unlock() = %v, want nil", err)
+	}
+}
+
+func TestGuard_LockBacksOff(t *testing.T) {
+	g := newTestGuard(t)
+	ctx := context.Background()
+
+	for lock, want := range []time.Duration{g.opts.Duration, 2 * g.opts.Duration} {
+		for i := 0; i < g.opts.MaxAttempts; i++ {
+			g.Fail(ctx, "colin", "")
+		}
+
+		ttl, ok := g.locked(userSubject("colin"))
+		if !ok {
+			t.Fatalf("lock %d: user is not locked", lock+1)
+		}
+		if ttl != want {
+			t.Errorf("lock %d: ttl = %v, want %v", lock+1, ttl, want)
+		}
+
+		redisServer.FastForward(ttl)
+	}
+}
+
+func TestGuard_SucceedKeepsIPFailures(t *testing.T) {
+	g := newTestGuard(t)
+	g.opts.IPMaxAttempts = 2 * (g.opts.MaxAttempts - 1)
+	ctx := context.Background()
+
+	for i := 0; i < g.opts.MaxAttempts-1; i++ {
+		g.Fail(ctx, "colin", "10.0.0.1")
+	}
+	g.Succeed("colin")
+
+	// the failures of colin are forgotten, the ones of the address are not.
+	for i := 0; i < g.opts.MaxAttempts-1; i++ {
+		g.Fail(ctx, "colin", "10.0.0.1")
+	}
+	if err := g.Check("colin", "10.0.0.2"); err != nil {
+		t.Fatalf("Check() after Succeed() = %v, want nil", err)
+	}
+	if err := g.Check("admin", "10.0.0.1"); !errors.IsCode(err, code.ErrAccountLocked) {
+		t.Errorf("Check() of the address = %v, want ErrAccountLocked", err)
+	}
+}
+
+func TestGuard_Disabled(t *testing.T) {
+	g := newTestGuard(t)
+	g.opts.Enabled = false
+	ctx := context.Background()
+
+	for i := 0; i < 2*g.opts.IPMaxAttempts; i++ {
+		g.Fail(ctx, "colin", "10.0.0.1")
+	}
+	if err := g.Check("colin", "10.0.0.1"); err != nil {
+		t.Errorf("Check() = %v, want nil", err)
+	}
+}
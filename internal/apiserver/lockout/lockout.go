@@ -0,0 +1,195 @@
+// Package lockout protects the login endpoints against password brute-force attacks.
+// Failed login attempts are counted in redis per username and per client address,
+// once a threshold is reached the subject is locked with an exponential backoff.
+// When redis is not available the guard fails open and never locks anyone out.
+package lockout
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/internal/pkg/metrics"
+	genericoptions "github.com/rose839/IAM/internal/pkg/options"
+	"github.com/rose839/IAM/pkg/errors"
+	"github.com/rose839/IAM/pkg/log"
+	"github.com/rose839/IAM/pkg/storage"
+)
+
+const keyPrefix = "iam-lockout-"
+
+// Guard tracks failed login attempts and locks out usernames and client addresses.
+type Guard struct {
+	opts  *genericoptions.LockoutOptions
+	store *storage.RedisCluster
+}
+
+var (
+	guard *Guard
+	once  sync.Once
+)
+
+// GetGuardOr return lockout guard instance with given options.
+func GetGuardOr(opts *genericoptions.LockoutOptions) (*Guard, error) {
+	if opts != nil {
+		once.Do(func() {
+			guard = &Guard{
+				opts:  opts,
+				store: &storage.RedisCluster{KeyPrefix: keyPrefix},
+			}
+		})
+	}
+
+	if guard == nil {
+		return nil, fmt.Errorf("got nil lockout guard")
+	}
+
+	return guard, nil
+}
+
+// Check returns an ErrAccountLocked error if the username or the client address is locked.
+func (g *Guard) Check(username, ip string) error {
+	if !g.opts.Enabled {
+		return nil
+	}
+
+	if ttl, ok := g.locked(userSubject(username)); ok {
+		return errors.WithCode(code.ErrAccountLocked, "User `%s` is locked, retry after %s.", username, ttl)
+	}
+
+	if ttl, ok := g.locked(ipSubject(ip)); ok {
+		return errors.WithCode(code.ErrAccountLocked, "Client `%s` is locked, retry after %s.", ip, ttl)
+	}
+
+	return nil
+}
+
+// Fail records a failed login attempt of username from client address ip.
+func (g *Guard) Fail(ctx context.Context, username, ip string) {
+	if !g.opts.Enabled {
+		return
+	}
+
+	if g.fail(ctx, userSubject(username), g.opts.MaxAttempts) {
+		metrics.Lockouts.WithLabelValues(metrics.SubjectUser).Inc()
+	}
+	if g.fail(ctx, ipSubject(ip), g.opts.IPMaxAttempts) {
+		metrics.Lockouts.WithLabelValues(metrics.SubjectIP).Inc()
+	}
+}
+
+// Succeed clears the failed login attempts and the backoff level of username.
+// Failures of the client address are kept, so that a valid account can not be used
+// to reset the counter of an address which is guessing passwords of other users.
+func (g *Guard) Succeed(username string) {
+	if !g.opts.Enabled {
+		return
+	}
+
+	subject := userSubject(username)
+	g.store.DeleteKeys([]string{failuresKey(subject), levelKey(subject)})
+}
+
+// Unlock removes the lock, the failed login attempts and the backoff level of username.
+func (g *Guard) Unlock(ctx context.Context, username string) error {
+	subject := userSubject(username)
+	if !g.store.WithContext(ctx).DeleteKeys([]string{lockKey(subject), failuresKey(subject), levelKey(subject)}) {
+		return storage.ErrRedisIsDown
+	}
+
+	log.L(ctx).Infof("User `%s` is unlocked", username)
+
+	return nil
+}
+
+func (g *Guard) locked(subject string) (time.Duration, bool) {
+	if subject == "" {
+		return 0, false
+	}
+
+	if _, err := g.store.GetKey(lockKey(subject)); err != nil {
+		return 0, false
+	}
+
+	ttl, _ := g.store.GetKeyTTL(lockKey(subject))
+
+	return time.Duration(ttl) * time.Second, true
+}
+
+// fail records a failed login attempt of subject, it returns true when subject is locked out.
+func (g *Guard) fail(ctx context.Context, subject string, maxAttempts int) bool {
+	if subject == "" || maxAttempts <= 0 {
+		return false
+	}
+
+	store := g.store.WithContext(ctx)
+
+	// IncrememntWithExpire works on raw keys, so prefix it ourselves.
+	failures := store.IncrememntWithExpire(keyPrefix+failuresKey(subject), int64(g.opts.FailureWindow.Seconds()))
+	if failures < int64(maxAttempts) {
+		return false
+	}
+
+	level := store.IncrememntWithExpire(keyPrefix+levelKey(subject), 0)
+	duration := backoff(g.opts.Duration, g.opts.MaxDuration, level)
+
+	if err := store.SetKey(lockKey(subject), strconv.FormatInt(level, 10), duration); err != nil {
+		log.L(ctx).Errorf("Lock out `%s` failed: %s", subject, err.Error())
+
+		return false
+	}
+
+	// forget the backoff level once the subject behaves for max-duration after the lock expires.
+	_ = store.SetExp(levelKey(subject), duration+g.opts.MaxDuration)
+	store.DeleteKey(failuresKey(subject))
+
+	log.L(ctx).Warnf("Lock out `%s` for %s after %d failed login attempts", subject, duration, failures)
+
+	return true
+}
+
+// backoff returns the lock duration of the given level: base doubled for every
+// level above the first one, capped at max.
+func backoff(base, max time.Duration, level int64) time.Duration {
+	duration := base
+	for i := int64(1); i < level && duration < max; i++ {
+		duration *= 2
+	}
+
+	if duration > max {
+		duration = max
+	}
+
+	return duration
+}
+
+func userSubject(username string) string {
+	if username == "" {
+		return ""
+	}
+
+	return "user:" + username
+}
+
+func ipSubject(ip string) string {
+	if ip == "" {
+		return ""
+	}
+
+	return "ip:" + ip
+}
+
+func lockKey(subject string) string {
+	return "lock-" + subject
+}
+
+func failuresKey(subject string) string {
+	return "failures-" + subject
+}
+
+func levelKey(subject string) string {
+	return "level-" + subject
+}
@@ -0,0 +1,28 @@
+package lockout
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_backoff(t *testing.T) {
+	tests := []struct {
+		name  string
+		level int64
+		want  time.Duration
+	}{
+		{"first lock", 1, time.Minute},
+		{"second lock", 2, 2 * time.Minute},
+		{"third lock", 3, 4 * time.Minute},
+		{"capped", 7, 30 * time.Minute},
+		{"far beyond cap", 100, 30 * time.Minute},
+		{"unknown level", 0, time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := backoff(time.Minute, 30*time.Minute, tt.level); got != tt.want {
+				t.Errorf("backoff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
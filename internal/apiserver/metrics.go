@@ -0,0 +1,132 @@
+package apiserver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/apiserver/store"
+	"github.com/rose839/IAM/internal/pkg/metrics"
+	"github.com/rose839/IAM/pkg/log"
+	"github.com/rose839/IAM/pkg/storage"
+)
+
+const (
+	// resourceCountTimeout bounds the time a scrape waits for the resource counts.
+	resourceCountTimeout = 5 * time.Second
+
+	// resourceCountTTL is the time the resource counts are cached, so that the scrapes do
+	// not load the database.
+	resourceCountTTL = time.Minute
+)
+
+var resourcesDesc = prometheus.NewDesc("iam_apiserver_resources",
+	"Total number of users, secrets and policies stored.", []string{"resource"}, nil)
+
+// resourceCollector counts the users, secrets and policies in the store, the counts are
+// cached for resourceCountTTL.
+type resourceCollector struct {
+	mu      sync.Mutex
+	counts  map[string]int64
+	counted time.Time
+}
+
+var _ prometheus.Collector = &resourceCollector{}
+
+func (c *resourceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- resourcesDesc
+}
+
+func (c *resourceCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.counted) >= resourceCountTTL {
+		c.count()
+	}
+
+	for resource, total := range c.counts {
+		ch <- prometheus.MustNewConstMetric(resourcesDesc, prometheus.GaugeValue, float64(total), resource)
+	}
+}
+
+// count refreshes the counts, the last count of a resource is kept when counting it fails.
+func (c *resourceCollector) count() {
+	storeIns := store.Client()
+	if storeIns == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), resourceCountTimeout)
+	defer cancel()
+
+	// only the total counts are used, fetch a single row of each resource
+	limit := int64(1)
+	opts := metav1.ListOptions{Limit: &limit}
+
+	counts := map[string]func() (int64, error){
+		"users": func() (int64, error) {
+			users, err := storeIns.Users().List(ctx, opts)
+			if err != nil {
+				return 0, err
+			}
+
+			return users.TotalCount, nil
+		},
+		"secrets": func() (int64, error) {
+			secrets, err := storeIns.Secrets().List(ctx, "", opts)
+			if err != nil {
+				return 0, err
+			}
+
+			return secrets.TotalCount, nil
+		},
+		"policies": func() (int64, error) {
+			policies, err := storeIns.Policies().List(ctx, "", opts)
+			if err != nil {
+				return 0, err
+			}
+
+			return policies.TotalCount, nil
+		},
+	}
+
+	if c.counts == nil {
+		c.counts = make(map[string]int64, len(counts))
+	}
+
+	for resource, count := range counts {
+		total, err := count()
+		if err != nil {
+			log.Warnf("Count %s for metrics failed: %s", resource, err.Error())
+
+			continue
+		}
+
+		c.counts[resource] = total
+	}
+
+	c.counted = time.Now()
+}
+
+// registerMetrics registers the metrics of the resources and of the redis connection.
+func registerMetrics() {
+	metrics.Registry.MustRegister(
+		&resourceCollector{},
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "iam",
+			Subsystem: "redis",
+			Name:      "connected",
+			Help:      "Whether the connection to redis is up (1) or down (0).",
+		}, func() float64 {
+			if storage.Connected() {
+				return 1
+			}
+
+			return 0
+		}),
+	)
+}
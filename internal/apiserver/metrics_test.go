@@ -0,0 +1,60 @@
+package apiserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/apiserver/store"
+	"github.com/rose839/IAM/internal/apiserver/store/mysql/mysqltest"
+)
+
+func TestResourceCollector(t *testing.T) {
+	factory := mysqltest.NewFactory(t)
+	store.SetClient(factory)
+
+	createUser := func(name string) {
+		t.Helper()
+
+		user := &v1.User{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Nickname:   name,
+			Password:   "Admin@2021",
+			Email:      name + "@foxmail.com",
+		}
+		if err := factory.Users().Create(context.Background(), user, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("create user %s: %v", name, err)
+		}
+	}
+
+	collector := &resourceCollector{}
+	users := func() int64 {
+		t.Helper()
+
+		if n := testutil.CollectAndCount(collector); n != 3 {
+			t.Errorf("collected %d metrics, want the counts of users, secrets and policies", n)
+		}
+
+		return collector.counts["users"]
+	}
+
+	createUser("colin")
+	if got := users(); got != 1 {
+		t.Fatalf("users = %v, want 1", got)
+	}
+
+	// the counts are cached until they expire
+	createUser("alice")
+	if got := users(); got != 1 {
+		t.Errorf("users within the ttl = %v, want the cached 1", got)
+	}
+
+	collector.counted = collector.counted.Add(-resourceCountTTL - time.Second)
+	if got := users(); got != 2 {
+		t.Errorf("users after the ttl = %v, want 2", got)
+	}
+}
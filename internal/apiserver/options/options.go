@@ -20,15 +20,24 @@ import (
 
 // Options runs a iam api server.
 type Options struct {
-	GenericServerRunOptions *genericoptions.ServerRunOptions       `json:"server"   mapstructure:"server"`
-	InsecureServing         *genericoptions.InsecureServingOptions `json:"insecure" mapstructure:"insecure"`
-	SecureServing           *genericoptions.SecureServingOptions   `json:"secure"   mapstructure:"secure"`
-	GRPCOptions             *genericoptions.GRPCOptions            `json:"grpc"     mapstructure:"grpc"`
-	FeatureOptions          *genericoptions.FeatureOptions         `json:"feature"  mapstructure:"feature"`
-	JwtOptions              *genericoptions.JwtOptions             `json:"jwt"      mapstructure:"jwt"`
-	MySQLOptions            *genericoptions.MySQLOptions           `json:"mysql"    mapstructure:"mysql"`
-	RedisOptions            *genericoptions.RedisOptions           `json:"redis"    mapstructure:"redis"`
-	Log                     *log.Options
+	GenericServerRunOptions *genericoptions.ServerRunOptions       `json:"server"          mapstructure:"server"`
+	InsecureServing         *genericoptions.InsecureServingOptions `json:"insecure"        mapstructure:"insecure"`
+	SecureServing           *genericoptions.SecureServingOptions   `json:"secure"          mapstructure:"secure"`
+	GRPCOptions             *genericoptions.GRPCOptions            `json:"grpc"            mapstructure:"grpc"`
+	FeatureOptions          *genericoptions.FeatureOptions         `json:"feature"         mapstructure:"feature"`
+	JwtOptions              *genericoptions.JwtOptions             `json:"jwt"             mapstructure:"jwt"`
+	MySQLOptions            *genericoptions.MySQLOptions           `json:"mysql"           mapstructure:"mysql"`
+	RedisOptions            *genericoptions.RedisOptions           `json:"redis"           mapstructure:"redis"`
+	LockoutOptions          *genericoptions.LockoutOptions         `json:"lockout"         mapstructure:"lockout"`
+	ImpersonationOptions    *genericoptions.ImpersonationOptions   `json:"impersonation"   mapstructure:"impersonation"`
+	AuditOptions            *genericoptions.AuditOptions           `json:"audit"           mapstructure:"audit"`
+	WebhookOptions          *genericoptions.WebhookOptions         `json:"webhook"         mapstructure:"webhook"`
+	RateLimitOptions        *genericoptions.RateLimitOptions       `json:"ratelimit"       mapstructure:"ratelimit"`
+	PasswordPolicyOptions   *genericoptions.PasswordPolicyOptions  `json:"password-policy" mapstructure:"password-policy"`
+	NotifierOptions         *genericoptions.NotifierOptions        `json:"notifier"        mapstructure:"notifier"`
+	VerificationOptions     *genericoptions.VerificationOptions    `json:"verification"    mapstructure:"verification"`
+	TracingOptions          *genericoptions.TracingOptions         `json:"tracing"         mapstructure:"tracing"`
+	Log                     *log.Options                           `json:"log"             mapstructure:"log"`
 }
 
 // NewOptions creates a new Options object with default parameters.
@@ -42,6 +51,15 @@ func NewOptions() *Options {
 		JwtOptions:              genericoptions.NewJwtOptions(),
 		MySQLOptions:            genericoptions.NewMySQLOptions(),
 		RedisOptions:            genericoptions.NewRedisOptions(),
+		LockoutOptions:          genericoptions.NewLockoutOptions(),
+		ImpersonationOptions:    genericoptions.NewImpersonationOptions(),
+		AuditOptions:            genericoptions.NewAuditOptions(),
+		WebhookOptions:          genericoptions.NewWebhookOptions(),
+		RateLimitOptions:        genericoptions.NewRateLimitOptions(),
+		PasswordPolicyOptions:   genericoptions.NewPasswordPolicyOptions(),
+		NotifierOptions:         genericoptions.NewNotifierOptions(),
+		VerificationOptions:     genericoptions.NewVerificationOptions(),
+		TracingOptions:          genericoptions.NewTracingOptions(),
 		Log:                     log.NewOptions(),
 	}
 
@@ -63,6 +81,15 @@ func (o *Options) Flags() (fss cliflag.NamedFlagSets) {
 	o.JwtOptions.AddFlags(fss.FlagSet("jwt"))
 	o.MySQLOptions.AddFlags(fss.FlagSet("mysql"))
 	o.RedisOptions.AddFlags(fss.FlagSet("redis"))
+	o.LockoutOptions.AddFlags(fss.FlagSet("lockout"))
+	o.ImpersonationOptions.AddFlags(fss.FlagSet("impersonation"))
+	o.AuditOptions.AddFlags(fss.FlagSet("audit"))
+	o.WebhookOptions.AddFlags(fss.FlagSet("webhook"))
+	o.RateLimitOptions.AddFlags(fss.FlagSet("ratelimit"))
+	o.PasswordPolicyOptions.AddFlags(fss.FlagSet("password-policy"))
+	o.NotifierOptions.AddFlags(fss.FlagSet("notifier"))
+	o.VerificationOptions.AddFlags(fss.FlagSet("verification"))
+	o.TracingOptions.AddFlags(fss.FlagSet("tracing"))
 	o.Log.AddFlags(fss.FlagSet("logs"))
 
 	return fss
@@ -93,6 +120,15 @@ func (o *Options) Validate() []error {
 	errs = append(errs, o.JwtOptions.Validate()...)
 	errs = append(errs, o.MySQLOptions.Validate()...)
 	errs = append(errs, o.RedisOptions.Validate()...)
+	errs = append(errs, o.LockoutOptions.Validate()...)
+	errs = append(errs, o.ImpersonationOptions.Validate()...)
+	errs = append(errs, o.AuditOptions.Validate()...)
+	errs = append(errs, o.WebhookOptions.Validate()...)
+	errs = append(errs, o.RateLimitOptions.Validate()...)
+	errs = append(errs, o.PasswordPolicyOptions.Validate()...)
+	errs = append(errs, o.NotifierOptions.Validate()...)
+	errs = append(errs, o.VerificationOptions.Validate()...)
+	errs = append(errs, o.TracingOptions.Validate()...)
 
 	return errs
 }
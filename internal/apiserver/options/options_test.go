@@ -0,0 +1,55 @@
+package options
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/rose839/IAM/pkg/app"
+)
+
+var update = flag.Bool("update", false, "update the generated sample configuration and schema")
+
+// TestGeneratedConfig keeps the sample configuration and the schema in line with the options,
+// run it with -update to regenerate them.
+func TestGeneratedConfig(t *testing.T) {
+	tests := []struct {
+		file     string
+		generate func() ([]byte, error)
+	}{
+		{
+			file:     "../../../configs/iam-apiserver.sample.yaml",
+			generate: func() ([]byte, error) { return app.GenerateSampleConfig(NewOptions()) },
+		},
+		{
+			file: "../../../configs/iam-apiserver.schema.json",
+			generate: func() ([]byte, error) {
+				return app.GenerateConfigSchema(NewOptions(), "iam-apiserver configuration")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		data, err := tt.generate()
+		if err != nil {
+			t.Fatalf("generate %s: %v", tt.file, err)
+		}
+
+		if *update {
+			if err := os.WriteFile(tt.file, data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			continue
+		}
+
+		current, err := os.ReadFile(tt.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(current, data) {
+			t.Errorf("%s is out of date, run go test ./internal/apiserver/options -run TestGeneratedConfig -update", tt.file)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package options
+
+import (
+	"fmt"
+
+	genericoptions "github.com/rose839/IAM/internal/pkg/options"
+	cliflag "github.com/rose839/IAM/pkg/app"
+)
+
+// PolicyApplyOptions runs the policy apply subcommand of the iam api server.
+type PolicyApplyOptions struct {
+	MySQLOptions *genericoptions.MySQLOptions `json:"mysql"       mapstructure:"mysql"`
+	RedisOptions *genericoptions.RedisOptions `json:"redis"       mapstructure:"redis"`
+	File         string                       `json:"file"        mapstructure:"file"`
+	Owner        string                       `json:"owner"       mapstructure:"owner"`
+	DryRun       bool                         `json:"dry-run"     mapstructure:"dry-run"`
+	AllowEmpty   bool                         `json:"allow-empty" mapstructure:"allow-empty"`
+}
+
+// NewPolicyApplyOptions creates a new PolicyApplyOptions object with default parameters.
+func NewPolicyApplyOptions() *PolicyApplyOptions {
+	return &PolicyApplyOptions{
+		MySQLOptions: genericoptions.NewMySQLOptions(),
+		RedisOptions: genericoptions.NewRedisOptions(),
+	}
+}
+
+// Flags returns flags of the policy apply subcommand by section name.
+func (o *PolicyApplyOptions) Flags() (fss cliflag.NamedFlagSets) {
+	o.MySQLOptions.AddFlags(fss.FlagSet("mysql"))
+	o.RedisOptions.AddFlags(fss.FlagSet("redis"))
+
+	fs := fss.FlagSet("apply")
+	fs.StringVarP(&o.File, "file", "f", o.File, ""+
+		"Yaml or json policy manifest file, or directory of manifest files, to apply. "+
+		"A yaml file can hold several manifests separated by ---.")
+	fs.StringVar(&o.Owner, "owner", o.Owner, ""+
+		"User owning the policies, the policies of the user missing in the manifests are deleted.")
+	fs.BoolVar(&o.DryRun, "dry-run", o.DryRun, "Only print the plan of the apply without making it.")
+	fs.BoolVar(&o.AllowEmpty, "allow-empty", o.AllowEmpty, ""+
+		"Allow applying no manifests, which deletes all policies of the owner.")
+
+	return fss
+}
+
+// Validate checks PolicyApplyOptions and return a slice of found errs.
+func (o *PolicyApplyOptions) Validate() []error {
+	errs := o.MySQLOptions.Validate()
+	errs = append(errs, o.RedisOptions.Validate()...)
+
+	if o.File == "" {
+		errs = append(errs, fmt.Errorf("--file can not be empty"))
+	}
+
+	if o.Owner == "" {
+		errs = append(errs, fmt.Errorf("--owner can not be empty"))
+	}
+
+	return errs
+}
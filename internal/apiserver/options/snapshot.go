@@ -0,0 +1,110 @@
+package options
+
+import (
+	"fmt"
+
+	genericoptions "github.com/rose839/IAM/internal/pkg/options"
+	cliflag "github.com/rose839/IAM/pkg/app"
+	"github.com/rose839/IAM/pkg/sets"
+)
+
+var (
+	snapshotFormats = sets.NewString("yaml", "json")
+	snapshotModes   = sets.NewString("create-only", "upsert", "replace")
+)
+
+// ExportOptions runs the export subcommand of the iam api server.
+type ExportOptions struct {
+	MySQLOptions *genericoptions.MySQLOptions `json:"mysql"  mapstructure:"mysql"`
+	Output       string                       `json:"output" mapstructure:"output"`
+	Format       string                       `json:"format" mapstructure:"format"`
+	Passphrase   string                       `json:"-"      mapstructure:"passphrase"`
+}
+
+// NewExportOptions creates a new ExportOptions object with default parameters.
+func NewExportOptions() *ExportOptions {
+	return &ExportOptions{
+		MySQLOptions: genericoptions.NewMySQLOptions(),
+		Output:       "-",
+		Format:       "yaml",
+	}
+}
+
+// Flags returns flags of the export subcommand by section name.
+func (o *ExportOptions) Flags() (fss cliflag.NamedFlagSets) {
+	o.MySQLOptions.AddFlags(fss.FlagSet("mysql"))
+
+	fs := fss.FlagSet("export")
+	fs.StringVarP(&o.Output, "output", "o", o.Output, "File the snapshot is written to, - for stdout.")
+	fs.StringVar(&o.Format, "format", o.Format, "Format of the snapshot, yaml or json.")
+	fs.StringVar(&o.Passphrase, "passphrase", o.Passphrase, ""+
+		"Passphrase the secret keys are encrypted with, they are exported in plain text when empty. "+
+		"Prefer the IAM_APISERVER_PASSPHRASE environment variable to keep it out of the shell history.")
+
+	return fss
+}
+
+// Validate checks ExportOptions and return a slice of found errs.
+func (o *ExportOptions) Validate() []error {
+	errs := o.MySQLOptions.Validate()
+
+	if !snapshotFormats.Has(o.Format) {
+		errs = append(errs, fmt.Errorf("--format must be one of %v", snapshotFormats.List()))
+	}
+
+	return errs
+}
+
+// ImportOptions runs the import subcommand of the iam api server.
+type ImportOptions struct {
+	MySQLOptions *genericoptions.MySQLOptions `json:"mysql"   mapstructure:"mysql"`
+	RedisOptions *genericoptions.RedisOptions `json:"redis"   mapstructure:"redis"`
+	File         string                       `json:"file"    mapstructure:"file"`
+	Mode         string                       `json:"mode"    mapstructure:"mode"`
+	DryRun       bool                         `json:"dry-run" mapstructure:"dry-run"`
+	Passphrase   string                       `json:"-"       mapstructure:"passphrase"`
+}
+
+// NewImportOptions creates a new ImportOptions object with default parameters.
+func NewImportOptions() *ImportOptions {
+	return &ImportOptions{
+		MySQLOptions: genericoptions.NewMySQLOptions(),
+		RedisOptions: genericoptions.NewRedisOptions(),
+		File:         "-",
+		Mode:         "create-only",
+	}
+}
+
+// Flags returns flags of the import subcommand by section name.
+func (o *ImportOptions) Flags() (fss cliflag.NamedFlagSets) {
+	o.MySQLOptions.AddFlags(fss.FlagSet("mysql"))
+	o.RedisOptions.AddFlags(fss.FlagSet("redis"))
+
+	fs := fss.FlagSet("import")
+	fs.StringVarP(&o.File, "file", "f", o.File, "Yaml or json snapshot file to import, - for stdin.")
+	fs.StringVar(&o.Mode, "mode", o.Mode, ""+
+		"Import mode: create-only only creates missing objects, upsert also updates existing ones, "+
+		"replace also deletes the objects missing in the snapshot.")
+	fs.BoolVar(&o.DryRun, "dry-run", o.DryRun, "Only print the changes of the import without making them.")
+	fs.StringVar(&o.Passphrase, "passphrase", o.Passphrase, ""+
+		"Passphrase the secret keys of the snapshot are encrypted with. "+
+		"Prefer the IAM_APISERVER_PASSPHRASE environment variable to keep it out of the shell history.")
+
+	return fss
+}
+
+// Validate checks ImportOptions and return a slice of found errs.
+func (o *ImportOptions) Validate() []error {
+	errs := o.MySQLOptions.Validate()
+	errs = append(errs, o.RedisOptions.Validate()...)
+
+	if o.File == "" {
+		errs = append(errs, fmt.Errorf("--file can not be empty"))
+	}
+
+	if !snapshotModes.Has(o.Mode) {
+		errs = append(errs, fmt.Errorf("--mode must be one of %v", snapshotModes.List()))
+	}
+
+	return errs
+}
@@ -0,0 +1,72 @@
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	"github.com/rose839/IAM/internal/apiserver/options"
+	srvv1 "github.com/rose839/IAM/internal/apiserver/service/v1"
+	"github.com/rose839/IAM/internal/apiserver/snapshot"
+	"github.com/rose839/IAM/internal/apiserver/store/mysql"
+	"github.com/rose839/IAM/internal/pkg/middleware"
+	"github.com/rose839/IAM/pkg/app"
+	"github.com/rose839/IAM/pkg/log"
+)
+
+// newPolicyCommand returns the policy subcommand managing policies from manifests.
+func newPolicyCommand() *app.Command {
+	cmd := app.NewCommand("policy", "Manage policies from manifests")
+	cmd.AddCommand(newPolicyApplyCommand())
+
+	return cmd
+}
+
+// newPolicyApplyCommand returns the policy apply subcommand making the policies of an
+// user match the manifests of a file or directory in one transaction.
+func newPolicyApplyCommand() *app.Command {
+	opts := options.NewPolicyApplyOptions()
+
+	return app.NewCommand(
+		"apply",
+		"Create, update and delete the policies of an user to match manifests",
+		app.WithCommandOptions(opts),
+		app.WithCommandConfig(),
+		app.WithCommandRunFunc(func(args []string) error {
+			initCommandLog()
+			defer log.Flush()
+
+			policies, err := snapshot.ReadPolicies(opts.File)
+			if err != nil {
+				return err
+			}
+
+			storeIns, err := mysql.GetMySQLFactoryOr(opts.MySQLOptions)
+			if err != nil {
+				return err
+			}
+			defer storeIns.Close()
+
+			result, err := srvv1.NewService(storeIns).Policies().Apply(context.Background(), opts.Owner, policies,
+				v1.PolicyApplyOptions{DryRun: opts.DryRun, AllowEmpty: opts.AllowEmpty})
+			if err != nil {
+				return err
+			}
+
+			printChanges(os.Stdout, result.Changes)
+
+			summary := "applied"
+			if result.DryRun {
+				summary = "would be applied (dry run)"
+			}
+			fmt.Fprintf(os.Stdout, "%d changes %s to the policies of %s\n", len(result.Changes), summary, result.Username)
+
+			if result.DryRun || len(result.Changes) == 0 {
+				return nil
+			}
+
+			return publishNotices(opts.RedisOptions, middleware.NoticePolicyChanged)
+		}),
+	)
+}
@@ -0,0 +1,213 @@
+// Package ratelimit limits the request rate of clients. Requests are counted in a
+// sliding window per client, identified by the authenticated user or the client
+// ip, and per route for routes with an own quota. Windows are kept in redis so
+// that the limits hold across replicas, when redis is not available every replica
+// falls back to counting in memory.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rose839/IAM/internal/pkg/middleware"
+	genericoptions "github.com/rose839/IAM/internal/pkg/options"
+	"github.com/rose839/IAM/pkg/log"
+	"github.com/rose839/IAM/pkg/storage"
+)
+
+const (
+	keyPrefix = "iam-ratelimit-"
+
+	// defaultScope counts the requests to routes without an own quota.
+	defaultScope = "default"
+)
+
+// Response headers describing the quota of the request.
+const (
+	LimitHeader     = "X-RateLimit-Limit"
+	RemainingHeader = "X-RateLimit-Remaining"
+	ResetHeader     = "X-RateLimit-Reset"
+)
+
+// quota is the number of requests allowed in a window.
+type quota struct {
+	requests int
+	window   time.Duration
+}
+
+// limits are the quotas of a limiter, which are replaced as a whole on reload.
+type limits struct {
+	enabled bool
+	quota   quota
+	routes  map[string]quota
+}
+
+// Limiter limits the request rate of clients.
+type Limiter struct {
+	limits atomic.Value // *limits
+	redis  window
+	memory window
+}
+
+var (
+	limiter *Limiter
+	once    sync.Once
+)
+
+// GetLimiterOr return rate limiter instance with given options.
+func GetLimiterOr(opts *genericoptions.RateLimitOptions) (*Limiter, error) {
+	var err error
+	if opts != nil {
+		once.Do(func() {
+			limiter, err = newLimiter(opts)
+		})
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if limiter == nil {
+		return nil, fmt.Errorf("got nil rate limiter")
+	}
+
+	return limiter, nil
+}
+
+func newLimiter(opts *genericoptions.RateLimitOptions) (*Limiter, error) {
+	l := &Limiter{
+		redis:  &redisWindow{store: &storage.RedisCluster{}},
+		memory: newMemoryWindow(),
+	}
+
+	if err := l.Reload(opts); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// Reload replaces the quotas of the limiter with the given options, the requests counted
+// in the current windows are kept.
+func (l *Limiter) Reload(opts *genericoptions.RateLimitOptions) error {
+	lim := &limits{enabled: opts.Enabled, routes: make(map[string]quota, len(opts.Routes))}
+	if !opts.Enabled {
+		l.limits.Store(lim)
+
+		return nil
+	}
+
+	requests, window, err := genericoptions.ParseRateLimitQuota(opts.Quota)
+	if err != nil {
+		return err
+	}
+	lim.quota = quota{requests: requests, window: window}
+
+	for route, q := range opts.Routes {
+		requests, window, err := genericoptions.ParseRateLimitQuota(q)
+		if err != nil {
+			return err
+		}
+
+		lim.routes[normalizeRoute(route)] = quota{requests: requests, window: window}
+	}
+
+	l.limits.Store(lim)
+
+	return nil
+}
+
+// Handler returns a gin middleware rejecting the requests of clients exceeding their
+// quota with http status 429. It must be installed after the authentication
+// middlewares to limit users instead of client addresses.
+func (l *Limiter) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lim, _ := l.limits.Load().(*limits)
+		if !lim.enabled {
+			c.Next()
+
+			return
+		}
+
+		scope, q := lim.quotaFor(c.Request.Method, c.FullPath())
+		client := clientOf(c)
+
+		count, oldest, ok := l.window().take(keyPrefix+scope+":"+client, q.requests, q.window)
+		reset := int64(math.Ceil(time.Until(oldest.Add(q.window)).Seconds()))
+		if reset < 0 {
+			reset = 0
+		}
+
+		remaining := q.requests - count
+		if remaining < 0 {
+			remaining = 0
+		}
+
+		c.Header(LimitHeader, strconv.Itoa(q.requests))
+		c.Header(RemainingHeader, strconv.Itoa(remaining))
+		c.Header(ResetHeader, strconv.FormatInt(reset, 10))
+
+		if !ok {
+			log.L(c).Warnf("Client `%s` exceeded the rate limit of %s", client, scope)
+
+			c.Header("Retry-After", strconv.FormatInt(reset, 10))
+			_ = c.Error(middleware.ErrorLimitExceeded)
+			c.AbortWithStatus(http.StatusTooManyRequests)
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// window returns the redis window, or the in-memory one when redis is not connected.
+func (l *Limiter) window() window {
+	if storage.Connected() {
+		return l.redis
+	}
+
+	return l.memory
+}
+
+// quotaFor returns the scope requests to route are counted in and its quota.
+func (l *limits) quotaFor(method, route string) (string, quota) {
+	for _, key := range []string{method + " " + route, route} {
+		if q, ok := l.routes[key]; ok {
+			return key, q
+		}
+	}
+
+	return defaultScope, l.quota
+}
+
+// clientOf identifies the client sending the request: the authenticated operator
+// acting as another identity, the authenticated user or the client address.
+func clientOf(c *gin.Context) string {
+	if operator := c.GetString(middleware.OperatorKey); operator != "" {
+		return "user:" + operator
+	}
+
+	if username := c.GetString(middleware.UsernameKey); username != "" {
+		return "user:" + username
+	}
+
+	return "ip:" + c.ClientIP()
+}
+
+// normalizeRoute upper cases the method of a route key, config keys are lower cased by viper.
+func normalizeRoute(route string) string {
+	route = strings.TrimSpace(route)
+	if i := strings.Index(route, " "); i > 0 {
+		return strings.ToUpper(route[:i]) + " " + strings.TrimSpace(route[i+1:])
+	}
+
+	return route
+}
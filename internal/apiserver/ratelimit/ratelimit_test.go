@@ -0,0 +1,226 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+
+	"github.com/rose839/IAM/internal/pkg/middleware"
+	genericoptions "github.com/rose839/IAM/internal/pkg/options"
+	"github.com/rose839/IAM/pkg/storage"
+)
+
+var redisServer *miniredis.Miniredis
+
+func TestMain(m *testing.M) {
+	redisServer = miniredis.NewMiniRedis()
+	if err := redisServer.Start(); err != nil {
+		panic(err)
+	}
+
+	port, _ := strconv.Atoi(redisServer.Port())
+	ctx, cancel := context.WithCancel(context.Background())
+	go storage.ConnectToRedis(ctx, &storage.Config{Host: redisServer.Host(), Port: port})
+	for i := 0; i < 50 && !storage.Connected(); i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	code := m.Run()
+
+	cancel()
+	redisServer.Close()
+	os.Exit(code)
+}
+
+// TestHandler runs against the redis window, served by miniredis.
+func TestHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	if !storage.Connected() {
+		t.Fatal("redis is not connected")
+	}
+	redisServer.FlushAll()
+
+	opts := genericoptions.NewRateLimitOptions()
+	opts.Quota = "3/1m"
+	opts.Routes = map[string]string{"post /login": "2/1m"}
+
+	l, err := newLimiter(opts)
+	if err != nil {
+		t.Fatalf("newLimiter() error = %v", err)
+	}
+
+	g := gin.New()
+	g.POST("/login", l.Handler(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	g.GET("/v1/users", func(c *gin.Context) {
+		c.Set(middleware.UsernameKey, c.GetHeader("X-User"))
+		if operator := c.GetHeader("X-Operator"); operator != "" {
+			c.Set(middleware.OperatorKey, operator)
+		}
+	}, l.Handler(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		user          string
+		operator      string
+		wantStatus    int
+		wantLimit     string
+		wantRemaining string
+	}{
+		{"login 1", http.MethodPost, "/login", "", "", http.StatusOK, "2", "1"},
+		{"login 2", http.MethodPost, "/login", "", "", http.StatusOK, "2", "0"},
+		{"login exceeded", http.MethodPost, "/login", "", "", http.StatusTooManyRequests, "2", "0"},
+		{"default quota counted separately", http.MethodGet, "/v1/users", "colin", "", http.StatusOK, "3", "2"},
+		{"colin 2", http.MethodGet, "/v1/users", "colin", "", http.StatusOK, "3", "1"},
+		{"colin 3", http.MethodGet, "/v1/users", "colin", "", http.StatusOK, "3", "0"},
+		{"colin exceeded", http.MethodGet, "/v1/users", "colin", "", http.StatusTooManyRequests, "3", "0"},
+		{"other user from the same address", http.MethodGet, "/v1/users", "admin", "", http.StatusOK, "3", "2"},
+		{"admin impersonating colin", http.MethodGet, "/v1/users", "colin", "admin", http.StatusOK, "3", "1"},
+		{"admin impersonating another user", http.MethodGet, "/v1/users", "alice", "admin", http.StatusOK, "3", "0"},
+		{"admin exceeded", http.MethodGet, "/v1/users", "bob", "admin", http.StatusTooManyRequests, "3", "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("X-User", tt.user)
+			req.Header.Set("X-Operator", tt.operator)
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get(LimitHeader); got != tt.wantLimit {
+				t.Errorf("%s = %s, want %s", LimitHeader, got, tt.wantLimit)
+			}
+			if got := w.Header().Get(RemainingHeader); got != tt.wantRemaining {
+				t.Errorf("%s = %s, want %s", RemainingHeader, got, tt.wantRemaining)
+			}
+			if got := w.Header().Get(ResetHeader); got == "" || got == "0" {
+				t.Errorf("%s = %q, want the seconds until the window frees a request", ResetHeader, got)
+			}
+		})
+	}
+}
+
+func TestLimiterReload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	opts := genericoptions.NewRateLimitOptions()
+	opts.Quota = "1/1m"
+
+	l, err := newLimiter(opts)
+	if err != nil {
+		t.Fatalf("newLimiter() error = %v", err)
+	}
+
+	g := gin.New()
+	g.GET("/version", l.Handler(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+		return w
+	}
+
+	if w := get(); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if w := get(); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+
+	// an invalid quota keeps the current ones
+	if err := l.Reload(&genericoptions.RateLimitOptions{Enabled: true, Quota: "1"}); err == nil {
+		t.Fatal("Reload() with an invalid quota succeeded")
+	}
+	if w := get(); w.Header().Get(LimitHeader) != "1" {
+		t.Errorf("limit = %s, want 1", w.Header().Get(LimitHeader))
+	}
+
+	// the accepted request already counted is kept with the new quota
+	if err := l.Reload(&genericoptions.RateLimitOptions{Enabled: true, Quota: "5/1m"}); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if w := get(); w.Code != http.StatusOK || w.Header().Get(LimitHeader) != "5" || w.Header().Get(RemainingHeader) != "3" {
+		t.Errorf("status = %d, limit = %s, remaining = %s, want 200, 5, 3",
+			w.Code, w.Header().Get(LimitHeader), w.Header().Get(RemainingHeader))
+	}
+
+	if err := l.Reload(&genericoptions.RateLimitOptions{Enabled: false}); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if w := get(); w.Code != http.StatusOK || w.Header().Get(LimitHeader) != "" {
+		t.Errorf("status = %d, limit = %s, want 200 without limit", w.Code, w.Header().Get(LimitHeader))
+	}
+}
+
+func TestRedisWindow_Concurrent(t *testing.T) {
+	if !storage.Connected() {
+		t.Fatal("redis is not connected")
+	}
+	redisServer.FlushAll()
+
+	const limit = 5
+	w := &redisWindow{store: &storage.RedisCluster{}}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		allowed int
+	)
+	for i := 0; i < 4*limit; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if _, _, ok := w.take(keyPrefix+"test", limit, time.Minute); ok {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed != limit {
+		t.Errorf("allowed %d concurrent requests, want %d", allowed, limit)
+	}
+
+	count, oldest, ok := w.take(keyPrefix+"test", limit, time.Minute)
+	if ok || count != limit {
+		t.Errorf("take() = %d, %v, want %d, false", count, ok, limit)
+	}
+	if age := time.Since(oldest); age < 0 || age > time.Minute {
+		t.Errorf("oldest request is %s old", age)
+	}
+}
+
+func TestMemoryWindow(t *testing.T) {
+	w := newMemoryWindow()
+
+	for i := 1; i <= 2; i++ {
+		if count, _, ok := w.take("test", 2, time.Minute); !ok || count != i {
+			t.Fatalf("take() = %d, %v, want %d, true", count, ok, i)
+		}
+	}
+
+	if count, _, ok := w.take("test", 2, time.Minute); ok || count != 2 {
+		t.Errorf("take() = %d, %v, want 2, false", count, ok)
+	}
+
+	if _, _, ok := w.take("other", 2, time.Minute); !ok {
+		t.Error("take() of another key was rejected")
+	}
+}
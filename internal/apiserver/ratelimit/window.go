@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rose839/IAM/pkg/log"
+	"github.com/rose839/IAM/pkg/storage"
+)
+
+// sweepInterval is the interval the in-memory window forgets idle clients at.
+const sweepInterval = time.Minute
+
+// window counts the requests of a key in a sliding window.
+type window interface {
+	// take records a request of key if less than limit requests are in the window. It
+	// returns the number of requests in the window, the time of the oldest one and
+	// whether the request is allowed.
+	take(key string, limit int, per time.Duration) (int, time.Time, bool)
+}
+
+// redisWindow keeps the windows in redis sorted sets shared by all replicas.
+type redisWindow struct {
+	store *storage.RedisCluster
+}
+
+// take checks and records the request in one script, so that concurrent requests of a
+// client on several replicas cannot exceed the limit. Rejected requests are not recorded
+// and do not extend the window of a client. Requests are allowed when redis fails.
+func (w *redisWindow) take(key string, limit int, per time.Duration) (int, time.Time, bool) {
+	count, oldest, ok, err := w.store.TakeRollingWindow(key, per, limit)
+	if err != nil {
+		log.Errorf("Count request of `%s` failed: %s", key, err.Error())
+
+		return 0, time.Now(), true
+	}
+
+	return count, oldest, ok
+}
+
+// memoryWindow keeps the windows of a single replica in memory.
+type memoryWindow struct {
+	mu       sync.Mutex
+	requests map[string]*memoryEntry
+	sweptAt  time.Time
+}
+
+type memoryEntry struct {
+	per   time.Duration
+	times []time.Time
+}
+
+func newMemoryWindow() *memoryWindow {
+	return &memoryWindow{
+		requests: make(map[string]*memoryEntry),
+		sweptAt:  time.Now(),
+	}
+}
+
+func (w *memoryWindow) take(key string, limit int, per time.Duration) (int, time.Time, bool) {
+	now := time.Now()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if now.Sub(w.sweptAt) >= sweepInterval {
+		w.sweep(now)
+	}
+
+	entry, ok := w.requests[key]
+	if !ok {
+		entry = &memoryEntry{per: per}
+		w.requests[key] = entry
+	}
+
+	entry.expire(now)
+	if len(entry.times) >= limit {
+		return len(entry.times), entry.times[0], false
+	}
+
+	entry.times = append(entry.times, now)
+
+	return len(entry.times), entry.times[0], true
+}
+
+// sweep forgets the clients without requests in their window.
+func (w *memoryWindow) sweep(now time.Time) {
+	for key, entry := range w.requests {
+		if entry.expire(now); len(entry.times) == 0 {
+			delete(w.requests, key)
+		}
+	}
+
+	w.sweptAt = now
+}
+
+// expire drops the requests which left the window.
+func (e *memoryEntry) expire(now time.Time) {
+	start := now.Add(-e.per)
+
+	i := 0
+	for i < len(e.times) && !e.times[i].After(start) {
+		i++
+	}
+
+	e.times = e.times[i:]
+}
@@ -0,0 +1,207 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+
+	"github.com/rose839/IAM/internal/apiserver/options"
+	"github.com/rose839/IAM/internal/apiserver/ratelimit"
+	"github.com/rose839/IAM/internal/pkg/middleware"
+	genericapiserver "github.com/rose839/IAM/internal/pkg/server"
+	"github.com/rose839/IAM/pkg/app"
+	"github.com/rose839/IAM/pkg/errors"
+	"github.com/rose839/IAM/pkg/log"
+	"github.com/rose839/IAM/pkg/reload"
+	"github.com/rose839/IAM/pkg/reload/reloadmanagers/filewatcher"
+	"github.com/rose839/IAM/pkg/reload/reloadmanagers/posixsignal"
+)
+
+// configReloader applies the changes of the configuration file to the running apiserver.
+// Only the options which can be changed safely are applied, the changes of the others
+// are logged and take effect on the next restart.
+type configReloader struct {
+	mu     sync.RWMutex
+	opts   *options.Options // effective options
+	server *genericapiserver.GenericAPIServer
+}
+
+func newConfigReloader(opts *options.Options, server *genericapiserver.GenericAPIServer) *configReloader {
+	return &configReloader{opts: opts, server: server}
+}
+
+// newGracefulReload reloads the configuration on SIGHUP and when the configuration file changes.
+func newGracefulReload(reloader *configReloader) *reload.GracefulReload {
+	gr := reload.New()
+	gr.AddReloadManager(posixsignal.NewPosixSignalManager())
+	if file := viper.ConfigFileUsed(); file != "" {
+		gr.AddReloadManager(filewatcher.NewFileWatcherManager(file))
+	}
+
+	gr.AddReloadCallback(reloader)
+	gr.SetErrorHandler(reload.ErrorFunc(func(err error) {
+		log.Errorf("Reload configuration failed: %s", err.Error())
+	}))
+
+	return gr
+}
+
+// OnReload reads the configuration file again and applies the safe changes, a configuration
+// which is not valid is rejected as a whole.
+func (r *configReloader) OnReload(trigger string) error {
+	if err := viper.ReadInConfig(); err != nil {
+		return fmt.Errorf("reload configuration file %s: %w", viper.ConfigFileUsed(), err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	opts := options.NewOptions()
+	if err := viper.Unmarshal(opts); err != nil {
+		return fmt.Errorf("reload configuration file %s: %w", viper.ConfigFileUsed(), err)
+	}
+
+	// the jwt key generated at startup is not in the configuration file
+	if opts.JwtOptions.Key == "" {
+		opts.JwtOptions.Key = r.opts.JwtOptions.Key
+	}
+
+	if err := opts.Complete(); err != nil {
+		return fmt.Errorf("reload configuration file %s: %w", viper.ConfigFileUsed(), err)
+	}
+
+	if errs := opts.Validate(); len(errs) != 0 {
+		return fmt.Errorf("configuration file %s is rejected: %w", viper.ConfigFileUsed(), errors.NewAggregate(errs))
+	}
+
+	effective, errs := r.apply(opts)
+	applied := changedKeys(r.opts, effective)
+	ignored := changedKeys(effective, opts)
+	r.opts = effective
+
+	log.Infof("Configuration reloaded on request of %s, applied changes: %v", trigger, applied)
+	if len(ignored) > 0 {
+		log.Warnf("Changes of %v require a restart of the apiserver, they are ignored until then", ignored)
+	}
+
+	return errors.NewAggregate(errs)
+}
+
+// apply applies the options which can be changed at runtime and returns the effective
+// options, which keep the current values of the others.
+func (r *configReloader) apply(opts *options.Options) (*options.Options, []error) {
+	var errs []error
+	current := r.opts
+	effective := *current
+
+	if !reflect.DeepEqual(current.Log, opts.Log) {
+		log.Reload(opts.Log)
+		effective.Log = opts.Log
+	}
+
+	serverRun := *current.GenericServerRunOptions
+	if !reflect.DeepEqual(serverRun.Middlewares, opts.GenericServerRunOptions.Middlewares) {
+		r.server.SetMiddlewares(opts.GenericServerRunOptions.Middlewares)
+		serverRun.Middlewares = opts.GenericServerRunOptions.Middlewares
+	}
+	if !reflect.DeepEqual(serverRun.CorsOrigins, opts.GenericServerRunOptions.CorsOrigins) {
+		middleware.SetCorsOrigins(opts.GenericServerRunOptions.CorsOrigins)
+		serverRun.CorsOrigins = opts.GenericServerRunOptions.CorsOrigins
+	}
+	effective.GenericServerRunOptions = &serverRun
+
+	if !reflect.DeepEqual(current.RateLimitOptions, opts.RateLimitOptions) {
+		limiter, err := ratelimit.GetLimiterOr(nil)
+		if err == nil {
+			err = limiter.Reload(opts.RateLimitOptions)
+		}
+
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			effective.RateLimitOptions = opts.RateLimitOptions
+		}
+	}
+
+	jwt := *current.JwtOptions
+	if jwt.Timeout != opts.JwtOptions.Timeout || jwt.MaxRefresh != opts.JwtOptions.MaxRefresh {
+		setTokenTimeouts(opts.JwtOptions.Timeout, opts.JwtOptions.MaxRefresh)
+		jwt.Timeout, jwt.MaxRefresh = opts.JwtOptions.Timeout, opts.JwtOptions.MaxRefresh
+	}
+	effective.JwtOptions = &jwt
+
+	// the certificate files are read again on every reload to pick up renewed certificates
+	secure := *current.SecureServing
+	if certKey := opts.SecureServing.ServerCert.CertKey; certKey.CertFile != "" && certKey.KeyFile != "" {
+		err := r.server.ReloadCertificate(genericapiserver.CertKey{CertFile: certKey.CertFile, KeyFile: certKey.KeyFile})
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			secure.ServerCert.CertKey = certKey
+		}
+	}
+	effective.SecureServing = &secure
+
+	return &effective, errs
+}
+
+// Config returns the effective options with the secrets masked.
+func (r *configReloader) Config() (interface{}, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return app.Redact(r.opts)
+}
+
+// changedKeys returns the keys of the configuration items which differ between a and b,
+// like ratelimit.quota.
+func changedKeys(a, b *options.Options) []string {
+	itemsA, itemsB := flatten(a), flatten(b)
+
+	keys := []string{}
+	for key, value := range itemsA {
+		if itemsB[key] != value {
+			keys = append(keys, key)
+		}
+	}
+	for key := range itemsB {
+		if _, ok := itemsA[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// flatten returns the configuration items of the options by their dotted keys, the
+// values are json encoded.
+func flatten(opts *options.Options) map[string]string {
+	data, _ := json.Marshal(opts)
+
+	var tree map[string]interface{}
+	_ = json.Unmarshal(data, &tree)
+
+	items := map[string]string{}
+	var walk func(prefix string, value interface{})
+	walk = func(prefix string, value interface{}) {
+		if m, ok := value.(map[string]interface{}); ok && len(m) > 0 {
+			for key, v := range m {
+				walk(strings.TrimPrefix(prefix+"."+key, "."), v)
+			}
+
+			return
+		}
+
+		encoded, _ := json.Marshal(value)
+		items[prefix] = string(encoded)
+	}
+	walk("", tree)
+
+	return items
+}
@@ -1,10 +1,24 @@
 package apiserver
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/apiserver/audit"
+	"github.com/rose839/IAM/internal/apiserver/controller/v1/auditevent"
+	"github.com/rose839/IAM/internal/apiserver/controller/v1/cache"
+	"github.com/rose839/IAM/internal/apiserver/controller/v1/group"
 	"github.com/rose839/IAM/internal/apiserver/controller/v1/policy"
 	"github.com/rose839/IAM/internal/apiserver/controller/v1/secret"
+	"github.com/rose839/IAM/internal/apiserver/controller/v1/serviceaccount"
+	"github.com/rose839/IAM/internal/apiserver/controller/v1/snapshot"
 	"github.com/rose839/IAM/internal/apiserver/controller/v1/user"
+	"github.com/rose839/IAM/internal/apiserver/controller/v1/webhook"
+	"github.com/rose839/IAM/internal/apiserver/impersonation"
+	"github.com/rose839/IAM/internal/apiserver/ratelimit"
+	srvv1 "github.com/rose839/IAM/internal/apiserver/service/v1"
+	"github.com/rose839/IAM/internal/apiserver/store"
 	"github.com/rose839/IAM/internal/apiserver/store/mysql"
 	"github.com/rose839/IAM/internal/pkg/code"
 	"github.com/rose839/IAM/internal/pkg/middleware"
@@ -24,42 +38,68 @@ func InstallMiddleware(g *gin.Engine) {
 }
 
 func installController(g *gin.Engine) *gin.Engine {
+	// rate limit clients, unauthenticated requests are counted per client address
+	limiter, _ := ratelimit.GetLimiterOr(nil)
+	limit := limiter.Handler()
+
 	JWTStrategy, _ := newJWTAuth().(auth.JWTStrategy)
-	g.POST("/login", JWTStrategy.LoginHandler)
-	g.POST("logout", JWTStrategy.LogoutHandler)
-	g.POST("/refresh", JWTStrategy.RefreshHandler) // Refresh time can be longer than token timeout
+	installTokenIssuer(JWTStrategy)
+	g.POST("/login", limit, loginHandler)
+	g.POST("logout", limit, logoutHandler)
+	g.POST("/refresh", limit, refreshHandler) // Refresh time can be longer than token timeout
 
 	auto := newAutoAuth()
 	g.NoRoute(auto.AuthFunc(), func(c *gin.Context) {
 		core.WriteResponse(c, errors.WithCode(code.ErrPageNotFound, "Page not found."), nil)
 	})
 
-	// v1 handlers, requiring authentication
 	storeIns, _ := mysql.GetMySQLFactoryOr(nil)
+	impersonator, _ := impersonation.GetImpersonatorOr(nil)
+	auditor, _ := audit.GetAuditorOr(nil)
+	installAuditGetters(auditor, storeIns)
+	userController := user.NewUserController(storeIns)
+
+	// v1 handlers for users who can not authenticate
+	g.POST("/v1/password-reset", limit, auditor.Handler(), userController.RequestPasswordReset)
+	g.POST("/v1/password-reset/confirm", limit, auditor.Handler(), userController.ConfirmPasswordReset)
+	g.POST("/v1/email-verification/confirm", limit, auditor.Handler(), userController.ConfirmEmailVerification)
+
+	// v1 http/json gateway of the grpc cache service, https only, for the administrators
+	if gateway, err := cache.GetGatewayOr(nil, nil); err == nil {
+		cachev1 := g.Group("/v1/cache", middleware.HTTPSOnly(), auto.AuthFunc(), limit, middleware.Admin())
+		{
+			cachev1.GET("/secrets", gateway.ListSecrets)
+			cachev1.GET("/policies", gateway.ListPolicies)
+		}
+	}
+
+	// v1 handlers, requiring authentication
 	v1 := g.Group("/v1")
-	v1.Use(auto.AuthFunc())
+	v1.Use(auto.AuthFunc(), middleware.Impersonate(impersonator.Authorize), limit, auditor.Handler())
 	{
 		// user RESTful resource
 		userv1 := v1.Group("/users")
 		userv1.Use(middleware.Validation())
 		{
-			userController := user.NewUserController(storeIns)
-
 			userv1.POST("", userController.Create)
-			userv1.DELETE("", userController.Delete)      // admin api
-			userv1.DELETE(":name", userController.Delete) // admin api
+			userv1.DELETE("", middleware.Publish(), userController.Delete)      // admin api
+			userv1.DELETE(":name", middleware.Publish(), userController.Delete) // admin api
 			userv1.PUT(":name/change-password", userController.ChangePassword)
+			userv1.PUT(":name/unlock", userController.Unlock) // admin api
+			userv1.POST(":name/email-verification", userController.RequestEmailVerification)
 			userv1.PUT(":name", userController.Update)
 			userv1.GET("", userController.List)
 			userv1.GET(":name", userController.Get)
 		}
 
+		policyController := policy.NewPolicyController(storeIns)
+		secretController := secret.NewSecretController(storeIns)
+
 		// police RESTful resource
 		policyv1 := v1.Group("/policies", middleware.Publish())
 		{
-			policyController := policy.NewPolicyController(storeIns)
-
 			policyv1.POST("", policyController.Create)
+			policyv1.POST("apply", policyController.Apply)
 			policyv1.DELETE("", policyController.Delete)
 			policyv1.DELETE(":name", policyController.Delete)
 			policyv1.PUT(":name", policyController.Update)
@@ -70,15 +110,116 @@ func installController(g *gin.Engine) *gin.Engine {
 		// secret RESTful resource
 		secretv1 := v1.Group("/secrets", middleware.Publish())
 		{
-			secretController := secret.NewSecretController(storeIns)
-
 			secretv1.POST("", secretController.Create)
 			secretv1.DELETE(":name", secretController.Delete)
 			secretv1.PUT(":name", secretController.Update)
 			secretv1.GET("", secretController.List)
 			secretv1.GET(":name", secretController.Get)
 		}
+
+		// group RESTful resource, admin api
+		groupv1 := v1.Group("/groups", middleware.Admin(), middleware.Publish())
+		{
+			groupController := group.NewGroupController(storeIns)
+
+			groupv1.POST("", groupController.Create)
+			groupv1.DELETE("", groupController.DeleteCollection)
+			groupv1.DELETE(":name", groupController.Delete)
+			groupv1.PUT(":name", groupController.Update)
+			groupv1.GET("", groupController.List)
+			groupv1.GET(":name", groupController.Get)
+			groupv1.POST(":name/members", groupController.AddMembers)
+			groupv1.DELETE(":name/members/:member", groupController.RemoveMember)
+		}
+
+		// audit event resource, admin api
+		auditv1 := v1.Group("/audit-events", middleware.Admin())
+		{
+			auditController := auditevent.NewAuditEventController(storeIns)
+
+			auditv1.GET("", auditController.List)
+		}
+
+		// webhook RESTful resource, admin api
+		webhookv1 := v1.Group("/webhooks", middleware.Admin())
+		{
+			webhookController := webhook.NewWebhookController(storeIns)
+
+			webhookv1.POST("", webhookController.Create)
+			webhookv1.DELETE(":name", webhookController.Delete)
+			webhookv1.PUT(":name", webhookController.Update)
+			webhookv1.GET("", webhookController.List)
+			webhookv1.GET(":name", webhookController.Get)
+			webhookv1.GET(":name/deliveries", webhookController.ListDeliveries)
+		}
+
+		// snapshot of users, secrets and policies, admin api
+		snapshotv1 := v1.Group("/snapshot", middleware.Admin(), middleware.Publish())
+		{
+			snapshotController := snapshot.NewSnapshotController(storeIns)
+
+			snapshotv1.GET("", snapshotController.Export)
+			snapshotv1.POST("", snapshotController.Import)
+		}
+
+		// service account RESTful resource, only owners and administrators can access a service account
+		sav1 := v1.Group("/serviceaccounts")
+		{
+			saController := serviceaccount.NewServiceAccountController(storeIns)
+
+			sav1.POST("", saController.Create)
+			sav1.GET("", saController.List)
+
+			sa := sav1.Group(":name", middleware.ServiceAccount())
+			{
+				sa.DELETE("", middleware.Publish(), saController.Delete)
+				sa.PUT("", saController.Update)
+				sa.GET("", saController.Get)
+
+				// secrets and policies owned by the service account
+				saSecret := sa.Group("/secrets", middleware.ActAsServiceAccount(), middleware.Publish())
+				{
+					saSecret.POST("", secretController.Create)
+					saSecret.DELETE(":resource", secretController.Delete)
+					saSecret.PUT(":resource", secretController.Update)
+					saSecret.GET("", secretController.List)
+					saSecret.GET(":resource", secretController.Get)
+				}
+
+				saPolicy := sa.Group("/policies", middleware.ActAsServiceAccount(), middleware.Publish())
+				{
+					saPolicy.POST("", policyController.Create)
+					saPolicy.POST("apply", policyController.Apply)
+					saPolicy.DELETE("", policyController.Delete)
+					saPolicy.DELETE(":resource", policyController.Delete)
+					saPolicy.PUT(":resource", policyController.Update)
+					saPolicy.GET("", policyController.List)
+					saPolicy.GET(":resource", policyController.Get)
+				}
+			}
+		}
 	}
 
 	return g
 }
+
+// installAuditGetters lets the auditor load resources to record their changes.
+func installAuditGetters(auditor *audit.Auditor, storeIns store.Factory) {
+	srv := srvv1.NewService(storeIns)
+
+	auditor.Register("users", func(ctx context.Context, _, name string) (interface{}, error) {
+		return srv.Users().Get(ctx, name, metav1.GetOptions{})
+	})
+	auditor.Register("secrets", func(ctx context.Context, owner, name string) (interface{}, error) {
+		return srv.Secrets().Get(ctx, owner, name, metav1.GetOptions{})
+	})
+	auditor.Register("policies", func(ctx context.Context, owner, name string) (interface{}, error) {
+		return srv.Policies().Get(ctx, owner, name, metav1.GetOptions{})
+	})
+	auditor.Register("groups", func(ctx context.Context, _, name string) (interface{}, error) {
+		return srv.Groups().Get(ctx, name, metav1.GetOptions{})
+	})
+	auditor.Register("serviceaccounts", func(ctx context.Context, _, name string) (interface{}, error) {
+		return srv.ServiceAccounts().Get(ctx, name, metav1.GetOptions{})
+	})
+}
@@ -2,30 +2,55 @@ package apiserver
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
-	"log"
+	"os"
+	"time"
 
 	pb "github.com/rose839/IAM/api/proto/apiserver/v1"
+	"github.com/rose839/IAM/internal/apiserver/audit"
 	"github.com/rose839/IAM/internal/apiserver/config"
 	cachev1 "github.com/rose839/IAM/internal/apiserver/controller/v1/cache"
+	"github.com/rose839/IAM/internal/apiserver/impersonation"
+	"github.com/rose839/IAM/internal/apiserver/lockout"
+	"github.com/rose839/IAM/internal/apiserver/ratelimit"
 	"github.com/rose839/IAM/internal/apiserver/store"
 	"github.com/rose839/IAM/internal/apiserver/store/mysql"
+	"github.com/rose839/IAM/internal/apiserver/verification"
+	"github.com/rose839/IAM/internal/apiserver/webhook"
+	"github.com/rose839/IAM/internal/pkg/interceptor"
+	"github.com/rose839/IAM/internal/pkg/middleware"
 	genericoptions "github.com/rose839/IAM/internal/pkg/options"
 	genericapiserver "github.com/rose839/IAM/internal/pkg/server"
+	"github.com/rose839/IAM/internal/pkg/tracing"
+	"github.com/rose839/IAM/pkg/log"
+	"github.com/rose839/IAM/pkg/reload"
 	"github.com/rose839/IAM/pkg/shutdown"
 	"github.com/rose839/IAM/pkg/shutdown/shutdownmanagers/posixsignal"
 	"github.com/rose839/IAM/pkg/storage"
+	"github.com/rose839/IAM/pkg/validation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 )
 
+// healthCheckInterval is the interval the readiness of the apiserver is checked at
+// to update the grpc health status.
+const healthCheckInterval = 5 * time.Second
+
 // apiServer represent iam apiserver runtime instance.
 type apiServer struct {
 	gs               *shutdown.GracefulShutdown         // graceful shutdown instance
 	redisOptions     *genericoptions.RedisOptions       // redis options
 	genericAPIServer *genericapiserver.GenericAPIServer // rest api server
 	gRPCAPIServer    *grpcAPIServer                     // grpc server
+	tracerProvider   *sdktrace.TracerProvider           // exports the spans, nil if tracing is disabled
+	gr               *reload.GracefulReload             // graceful reload instance
+	reloader         *configReloader                    // applies the configuration changes
 }
 
 // preparedAPIServer represent an iam apiserver runtime instance that is prepared.
@@ -38,6 +63,9 @@ type ExtraConfig struct {
 	Addr         string // grpc address
 	MaxMsgSize   int    // grpc max message size
 	ServerCert   genericoptions.GeneratableKeyCert
+	Certificate  *genericapiserver.Certificate // shared with the https server, nil to load ServerCert
+	ClientCAFile string                        // client certificate authorities verified in mtls auth mode
+	Auth         interceptor.AuthConfig        // grpc caller authentication
 	MySQLOptions *genericoptions.MySQLOptions
 }
 
@@ -53,6 +81,11 @@ func buildGenericConfig(cfg *config.Config) (genericConfig *genericapiserver.Con
 		return
 	}
 
+	// generate a self-signed certificate for development when it is enabled and none is provided
+	if err = cfg.SecureServing.MaybeGenerateCertKey(); err != nil {
+		return
+	}
+
 	if err = cfg.SecureServing.ApplyTo(genericConfig); err != nil {
 		return
 	}
@@ -68,6 +101,12 @@ func buildExtraConfig(cfg *config.Config) (*ExtraConfig, error) {
 		Addr:         fmt.Sprintf("%s:%d", cfg.GRPCOptions.BindAddress, cfg.GRPCOptions.BindPort),
 		MaxMsgSize:   cfg.GRPCOptions.MaxMsgSize,
 		ServerCert:   cfg.SecureServing.ServerCert,
+		ClientCAFile: cfg.GRPCOptions.ClientCAFile,
+		Auth: interceptor.AuthConfig{
+			Mode:           cfg.GRPCOptions.AuthMode,
+			Tokens:         cfg.GRPCOptions.Tokens,
+			AllowedCallers: cfg.GRPCOptions.AllowedCallers,
+		},
 		MySQLOptions: cfg.MySQLOptions,
 	}, nil
 }
@@ -92,16 +131,68 @@ func createAPIServer(cfg *config.Config) (*apiServer, error) {
 		return nil, err
 	}
 
+	// the grpc server serves the certificate of the https server, both follow its reloads
+	extraConfig.Certificate = genericServer.Certificate()
+
 	extraServer, err := extraConfig.complete().New()
 	if err != nil {
 		return nil, err
 	}
 
+	// export the stored resources and the redis connection state on /metrics
+	registerMetrics()
+
+	if _, err := lockout.GetGuardOr(cfg.LockoutOptions); err != nil {
+		return nil, err
+	}
+
+	if _, err := impersonation.GetImpersonatorOr(cfg.ImpersonationOptions); err != nil {
+		return nil, err
+	}
+
+	if _, err := ratelimit.GetLimiterOr(cfg.RateLimitOptions); err != nil {
+		return nil, err
+	}
+
+	if _, err := audit.GetAuditorOr(cfg.AuditOptions); err != nil {
+		return nil, err
+	}
+
+	if _, err := webhook.GetDispatcherOr(cfg.WebhookOptions); err != nil {
+		return nil, err
+	}
+
+	passwordPolicy, err := cfg.PasswordPolicyOptions.NewPolicy()
+	if err != nil {
+		return nil, err
+	}
+	validation.SetPasswordPolicy(passwordPolicy)
+
+	notifierIns, err := cfg.NotifierOptions.NewNotifier()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := verification.GetVerifierOr(cfg.VerificationOptions, notifierIns); err != nil {
+		return nil, err
+	}
+
+	reloader := newConfigReloader(cfg.Options, genericServer)
+
 	server := &apiServer{
 		gs:               gs,
 		redisOptions:     cfg.RedisOptions,
 		genericAPIServer: genericServer,
 		gRPCAPIServer:    extraServer,
+		gr:               newGracefulReload(reloader),
+		reloader:         reloader,
+	}
+
+	if cfg.TracingOptions.Enabled {
+		if server.tracerProvider, err = cfg.TracingOptions.NewTracerProvider(); err != nil {
+			return nil, err
+		}
+		tracing.SetTracerProvider(server.tracerProvider)
 	}
 
 	return server, nil
@@ -112,11 +203,53 @@ func (s *apiServer) PrepareRun() preparedAPIServer {
 	// init rest api server router
 	initRouter(s.genericAPIServer.Engine)
 
+	// read and change the log levels at runtime, admin api
+	s.genericAPIServer.InstallLogLevelHandler(newAutoAuth().AuthFunc(), middleware.Admin())
+
+	// show the effective configuration with the secrets masked, admin api
+	s.genericAPIServer.InstallConfigHandler(s.reloader.Config, newAutoAuth().AuthFunc(), middleware.Admin())
+
 	// init redis connection
 	s.initRedisStore()
 
+	if auditor, _ := audit.GetAuditorOr(nil); auditor != nil {
+		// prune expired audit events
+		auditor.StartPruner()
+
+		// deliver resource change events to webhooks, also when auditing is disabled
+		if dispatcher, _ := webhook.GetDispatcherOr(nil); dispatcher != nil {
+			dispatcher.Start()
+			auditor.Subscribe(dispatcher)
+		}
+	}
+
+	// readiness requires the dependencies, the grpc health status follows readiness
+	s.genericAPIServer.AddReadyzChecks(
+		genericapiserver.NamedCheck("mysql", func(ctx context.Context) error {
+			return store.Client().Ping(ctx)
+		}),
+		genericapiserver.NamedCheck("redis", func(context.Context) error {
+			if !storage.Connected() {
+				return storage.ErrRedisIsDown
+			}
+
+			return nil
+		}),
+	)
+	s.watchHealth()
+
 	// add graceful shutdown callback
 	s.gs.AddShutdownCallback(shutdown.ShutdownFunc(func(string) error {
+		// stop webhook deliveries, the pending ones are resumed on the next start
+		if dispatcher, _ := webhook.GetDispatcherOr(nil); dispatcher != nil {
+			_ = dispatcher.Close()
+		}
+
+		// export the buffered spans
+		if s.tracerProvider != nil {
+			_ = s.tracerProvider.Shutdown(context.Background())
+		}
+
 		// close mysql connection
 		mysqlStore, _ := mysql.GetMySQLFactoryOr(nil)
 		if mysqlStore != nil {
@@ -143,6 +276,11 @@ func (s preparedAPIServer) Run() error {
 		log.Fatalf("start shutdown manager failed: %s", err.Error())
 	}
 
+	// start reload managers
+	if err := s.gr.Start(); err != nil {
+		log.Fatalf("start reload manager failed: %s", err.Error())
+	}
+
 	// this will block until api server close
 	s.genericAPIServer.Run()
 
@@ -164,13 +302,24 @@ func (c *ExtraConfig) complete() *completedExtraConfig {
 // New create a grpcAPIServer instance.
 func (c *completedExtraConfig) New() (*grpcAPIServer, error) {
 	// create grpc encryption communication
-	creds, err := credentials.NewServerTLSFromFile(c.ServerCert.CertKey.CertFile, c.ServerCert.CertKey.KeyFile)
+	creds, err := c.serverCredentials()
 	if err != nil {
 		log.Fatalf("Failed to generate credentials: %s", err.Error())
 	}
 
-	// create grpc server
-	opts := []grpc.ServerOption{grpc.MaxRecvMsgSize(c.MaxMsgSize), grpc.Creds(creds)}
+	if c.Auth.Mode == interceptor.AuthModeNone {
+		log.Warn("Grpc callers are not authenticated, any caller can read all secrets and policies: " +
+			"--grpc.auth-mode is none, which must only be used in development environments")
+	}
+
+	// create grpc server, interceptors authenticate callers, propagate request ids,
+	// log, measure and recover calls
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(c.MaxMsgSize),
+		grpc.Creds(creds),
+		grpc.ChainUnaryInterceptor(interceptor.UnaryServerInterceptors(c.Auth)...),
+		grpc.ChainStreamInterceptor(interceptor.StreamServerInterceptors(c.Auth)...),
+	}
 	grpcServer := grpc.NewServer(opts...)
 
 	// create mysql store instance
@@ -186,36 +335,147 @@ func (c *completedExtraConfig) New() (*grpcAPIServer, error) {
 	// register grpc server
 	pb.RegisterCacheServer(grpcServer, cacheIns)
 
+	// serve the cache service over http/json too, to the administrators. Gateway callers
+	// authenticate like rest api users, the grpc caller authentication is skipped.
+	noAuth := interceptor.AuthConfig{Mode: interceptor.AuthModeNone}
+	if _, err := cachev1.GetGatewayOr(cacheIns,
+		interceptor.ChainUnaryServer(interceptor.UnaryServerInterceptors(noAuth)...)); err != nil {
+		return nil, err
+	}
+
+	// register grpc health server, services are not serving until the apiserver is ready
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
 	// register grpc reflcetion server to enable grpcurl and grpc Swagger test
 	reflection.Register(grpcServer)
 
-	return &grpcAPIServer{grpcServer, c.Addr}, nil
+	server := &grpcAPIServer{Server: grpcServer, address: c.Addr, health: healthServer}
+	server.SetServingStatus(false)
+
+	return server, nil
+}
+
+// serverCredentials returns the tls credentials of the grpc server, client
+// certificates are required and verified in mtls auth mode.
+func (c *completedExtraConfig) serverCredentials() (credentials.TransportCredentials, error) {
+	certificate := c.Certificate
+	if certificate == nil {
+		var err error
+		certificate, err = genericapiserver.NewCertificate(genericapiserver.CertKey{
+			CertFile: c.ServerCert.CertKey.CertFile,
+			KeyFile:  c.ServerCert.CertKey.KeyFile,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := certificate.Watch(); err != nil {
+			log.Warnf("Watch grpc certificate files failed, they are not reloaded: %s", err.Error())
+		}
+	}
+
+	tlsConfig := &tls.Config{
+		GetCertificate: certificate.GetCertificate,
+		MinVersion:     tls.VersionTLS12,
+	}
+
+	if c.Auth.Mode == interceptor.AuthModeMTLS {
+		clientCAs, err := loadClientCAs(c.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		tlsConfig.ClientCAs = clientCAs
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// loadClientCAs loads the certificate authorities client certificates are verified with.
+func loadClientCAs(file string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate found in client ca file %s", file)
+	}
+
+	return clientCAs, nil
+}
+
+// watchHealth keeps the grpc health status in line with the readiness of the apiserver.
+func (s *apiServer) watchHealth() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.gs.AddShutdownCallback(shutdown.ShutdownFunc(func(string) error {
+		cancel()
+
+		return nil
+	}))
+
+	go func() {
+		ticker := time.NewTicker(healthCheckInterval)
+		defer ticker.Stop()
+
+		serving := false
+		for {
+			err := s.genericAPIServer.Readyz(ctx)
+			if (err == nil) != serving {
+				serving = err == nil
+				s.gRPCAPIServer.SetServingStatus(serving)
+
+				if serving {
+					log.Info("Grpc services are serving")
+				} else {
+					log.Warnf("Grpc services are not serving: %s", err.Error())
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
 }
 
 func (s *apiServer) initRedisStore() {
 	ctx, cancle := context.WithCancel(context.Background())
 	s.gs.AddShutdownCallback(shutdown.ShutdownFunc(func(string) error {
+		// flush audit sinks
+		if auditor, _ := audit.GetAuditorOr(nil); auditor != nil {
+			_ = auditor.Close()
+		}
+
 		cancle()
 
 		return nil
 	}))
 
-	config := &storage.Config{
-		Host:                  s.redisOptions.Host,
-		Port:                  s.redisOptions.Port,
-		Addrs:                 s.redisOptions.Addrs,
-		MasterName:            s.redisOptions.MasterName,
-		Username:              s.redisOptions.Username,
-		Password:              s.redisOptions.Password,
-		Database:              s.redisOptions.Database,
-		MaxIdle:               s.redisOptions.MaxIdle,
-		MaxActive:             s.redisOptions.MaxActive,
-		Timeout:               s.redisOptions.Timeout,
-		EnableCluster:         s.redisOptions.EnableCluster,
-		UseSSL:                s.redisOptions.UseSSL,
-		SSLInsecureSkipVerify: s.redisOptions.SSLInsecureSkipVerify,
-	}
-
 	// try to connect to redis
-	go storage.ConnectToRedis(ctx, config)
+	go storage.ConnectToRedis(ctx, redisConfig(s.redisOptions))
+}
+
+// redisConfig converts the redis options into the storage config.
+func redisConfig(opts *genericoptions.RedisOptions) *storage.Config {
+	return &storage.Config{
+		Host:                  opts.Host,
+		Port:                  opts.Port,
+		Addrs:                 opts.Addrs,
+		MasterName:            opts.MasterName,
+		Username:              opts.Username,
+		Password:              opts.Password,
+		Database:              opts.Database,
+		MaxIdle:               opts.MaxIdle,
+		MaxActive:             opts.MaxActive,
+		Timeout:               opts.Timeout,
+		EnableCluster:         opts.EnableCluster,
+		UseSSL:                opts.UseSSL,
+		SSLInsecureSkipVerify: opts.SSLInsecureSkipVerify,
+	}
 }
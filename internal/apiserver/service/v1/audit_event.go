@@ -0,0 +1,33 @@
+package v1
+
+import (
+	"context"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/apiserver/store"
+)
+
+// AuditEventSrv defines functions used to handle audit event request.
+type AuditEventSrv interface {
+	List(ctx context.Context, opts metav1.ListOptions) (*v1.AuditEventList, error)
+}
+
+type auditEventService struct {
+	store store.Factory
+}
+
+var _ AuditEventSrv = (*auditEventService)(nil)
+
+func newAuditEvents(srv *service) AuditEventSrv {
+	return &auditEventService{
+		store: srv.store,
+	}
+}
+
+func (a *auditEventService) List(ctx context.Context, opts metav1.ListOptions) (*v1.AuditEventList, error) {
+	ctx, span := tracer.Start(ctx, "AuditEventSrv.List")
+	defer span.End()
+
+	return a.store.AuditEvents().List(ctx, opts)
+}
@@ -0,0 +1,144 @@
+package v1
+
+import (
+	"context"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/apiserver/store"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+// GroupSrv defines functions used to handle group request.
+type GroupSrv interface {
+	Create(ctx context.Context, group *v1.Group, opts metav1.CreateOptions) error
+	Update(ctx context.Context, group *v1.Group, opts metav1.UpdateOptions) error
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, names []string, opts metav1.DeleteOptions) error
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Group, error)
+	List(ctx context.Context, opts metav1.ListOptions) (*v1.GroupList, error)
+	AddMembers(ctx context.Context, name string, usernames []string) error
+	RemoveMembers(ctx context.Context, name string, usernames []string) error
+}
+
+type groupService struct {
+	store store.Factory
+}
+
+var _ GroupSrv = (*groupService)(nil)
+
+func newGroups(srv *service) GroupSrv {
+	return &groupService{
+		store: srv.store,
+	}
+}
+
+func (g *groupService) Create(ctx context.Context, group *v1.Group, opts metav1.CreateOptions) error {
+	ctx, span := tracer.Start(ctx, "GroupSrv.Create")
+	defer span.End()
+
+	// the group is not created unless all of its members are added.
+	return g.store.Transaction(ctx, func(factory store.Factory) error {
+		if err := factory.Groups().Create(ctx, group, opts); err != nil {
+			return errors.WithCode(code.ErrDatabase, err.Error())
+		}
+
+		return addMembers(ctx, factory, group.Name, group.Members)
+	})
+}
+
+func (g *groupService) Update(ctx context.Context, group *v1.Group, opts metav1.UpdateOptions) error {
+	ctx, span := tracer.Start(ctx, "GroupSrv.Update")
+	defer span.End()
+
+	if err := g.store.Groups().Update(ctx, group, opts); err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return nil
+}
+
+func (g *groupService) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	ctx, span := tracer.Start(ctx, "GroupSrv.Delete")
+	defer span.End()
+
+	if err := g.store.Groups().Delete(ctx, name, opts); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (g *groupService) DeleteCollection(ctx context.Context, names []string, opts metav1.DeleteOptions) error {
+	ctx, span := tracer.Start(ctx, "GroupSrv.DeleteCollection")
+	defer span.End()
+
+	if err := g.store.Groups().DeleteCollection(ctx, names, opts); err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return nil
+}
+
+// Get returns a group together with its members.
+func (g *groupService) Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Group, error) {
+	ctx, span := tracer.Start(ctx, "GroupSrv.Get")
+	defer span.End()
+
+	group, err := g.store.Groups().Get(ctx, name, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if group.Members, err = g.store.Groups().ListMembers(ctx, name); err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return group, nil
+}
+
+func (g *groupService) List(ctx context.Context, opts metav1.ListOptions) (*v1.GroupList, error) {
+	ctx, span := tracer.Start(ctx, "GroupSrv.List")
+	defer span.End()
+
+	groups, err := g.store.Groups().List(ctx, opts)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return groups, nil
+}
+
+// AddMembers adds existing users to the group.
+func (g *groupService) AddMembers(ctx context.Context, name string, usernames []string) error {
+	ctx, span := tracer.Start(ctx, "GroupSrv.AddMembers")
+	defer span.End()
+
+	return addMembers(ctx, g.store, name, usernames)
+}
+
+func addMembers(ctx context.Context, factory store.Factory, name string, usernames []string) error {
+	for _, username := range usernames {
+		if _, err := factory.Users().Get(ctx, username, metav1.GetOptions{}); err != nil {
+			return err
+		}
+	}
+
+	if err := factory.Groups().AddMembers(ctx, name, usernames); err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return nil
+}
+
+func (g *groupService) RemoveMembers(ctx context.Context, name string, usernames []string) error {
+	ctx, span := tracer.Start(ctx, "GroupSrv.RemoveMembers")
+	defer span.End()
+
+	if err := g.store.Groups().RemoveMembers(ctx, name, usernames); err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package v1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/apiserver/store"
+	"github.com/rose839/IAM/internal/apiserver/store/mysql/mysqltest"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+func createUsers(t *testing.T, factory store.Factory, names ...string) {
+	t.Helper()
+
+	for _, name := range names {
+		user := &v1.User{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Nickname:   name,
+			Password:   "Admin@2021",
+			Email:      name + "@foxmail.com",
+		}
+		if err := factory.Users().Create(context.Background(), user, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("create user %s: %v", name, err)
+		}
+	}
+}
+
+func TestGroupService_Create(t *testing.T) {
+	tests := []struct {
+		name        string
+		members     []string
+		wantCode    int
+		wantMembers []string
+	}{
+		{"no members", nil, code.ErrSuccess, []string{}},
+		{"members", []string{"colin", "alice"}, code.ErrSuccess, []string{"alice", "colin"}},
+		{"unknown member", []string{"colin", "bob"}, code.ErrUserNotFound, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := mysqltest.NewFactory(t)
+			createUsers(t, factory, "colin", "alice")
+			srv := NewService(factory).Groups()
+			ctx := context.Background()
+
+			group := &v1.Group{ObjectMeta: metav1.ObjectMeta{Name: "dev"}, Members: tt.members}
+			err := srv.Create(ctx, group, metav1.CreateOptions{})
+			if tt.wantCode != code.ErrSuccess {
+				if !errors.IsCode(err, tt.wantCode) {
+					t.Fatalf("Create() error = %v, want code %d", err, tt.wantCode)
+				}
+
+				// the group is rolled back together with its members.
+				if _, err := srv.Get(ctx, "dev", metav1.GetOptions{}); !errors.IsCode(err, code.ErrGroupNotFound) {
+					t.Errorf("Get() after failed Create() error = %v, want ErrGroupNotFound", err)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			got, err := srv.Get(ctx, "dev", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Members, tt.wantMembers) {
+				t.Errorf("Get() members = %v, want %v", got.Members, tt.wantMembers)
+			}
+		})
+	}
+}
+
+func TestGroupService_Members(t *testing.T) {
+	factory := mysqltest.NewFactory(t)
+	createUsers(t, factory, "colin", "alice")
+	srv := NewService(factory).Groups()
+	ctx := context.Background()
+
+	if err := srv.Create(ctx, &v1.Group{ObjectMeta: metav1.ObjectMeta{Name: "dev"}}, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// adding a member twice is not an error.
+	for _, members := range [][]string{{"colin"}, {"colin", "alice"}} {
+		if err := srv.AddMembers(ctx, "dev", members); err != nil {
+			t.Fatalf("AddMembers(%v) error = %v", members, err)
+		}
+	}
+	if err := srv.AddMembers(ctx, "dev", []string{"bob"}); !errors.IsCode(err, code.ErrUserNotFound) {
+		t.Errorf("AddMembers() of unknown user error = %v, want ErrUserNotFound", err)
+	}
+
+	if err := srv.RemoveMembers(ctx, "dev", []string{"colin"}); err != nil {
+		t.Fatalf("RemoveMembers() error = %v", err)
+	}
+
+	got, err := srv.Get(ctx, "dev", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if want := []string{"alice"}; !reflect.DeepEqual(got.Members, want) {
+		t.Errorf("Get() members = %v, want %v", got.Members, want)
+	}
+}
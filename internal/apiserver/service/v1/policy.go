@@ -5,9 +5,11 @@ import (
 
 	v1 "github.com/rose839/IAM/api/apiserver/v1"
 	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/apiserver/snapshot"
 	"github.com/rose839/IAM/internal/apiserver/store"
 	"github.com/rose839/IAM/internal/pkg/code"
 	"github.com/rose839/IAM/pkg/errors"
+	"github.com/rose839/IAM/pkg/sets"
 )
 
 // PolicySrv defines functions used to handle policy request.
@@ -18,6 +20,7 @@ type PolicySrv interface {
 	DeleteCollection(ctx context.Context, username string, names []string, opts metav1.DeleteOptions) error
 	Get(ctx context.Context, username string, name string, opts metav1.GetOptions) (*v1.Policy, error)
 	List(ctx context.Context, username string, opts metav1.ListOptions) (*v1.PolicyList, error)
+	Apply(ctx context.Context, username string, policies []*v1.Policy, opts v1.PolicyApplyOptions) (*v1.PolicyApplyResult, error)
 }
 
 type policyService struct {
@@ -31,6 +34,9 @@ func newPolicies(srv *service) *policyService {
 }
 
 func (s *policyService) Create(ctx context.Context, policy *v1.Policy, opts metav1.CreateOptions) error {
+	ctx, span := tracer.Start(ctx, "PolicySrv.Create")
+	defer span.End()
+
 	if err := s.store.Policies().Create(ctx, policy, opts); err != nil {
 		return errors.WithCode(code.ErrDatabase, err.Error())
 	}
@@ -39,6 +45,9 @@ func (s *policyService) Create(ctx context.Context, policy *v1.Policy, opts meta
 }
 
 func (s *policyService) Update(ctx context.Context, policy *v1.Policy, opts metav1.UpdateOptions) error {
+	ctx, span := tracer.Start(ctx, "PolicySrv.Update")
+	defer span.End()
+
 	// Save changed fields.
 	if err := s.store.Policies().Update(ctx, policy, opts); err != nil {
 		return errors.WithCode(code.ErrDatabase, err.Error())
@@ -48,6 +57,9 @@ func (s *policyService) Update(ctx context.Context, policy *v1.Policy, opts meta
 }
 
 func (s *policyService) Delete(ctx context.Context, username, name string, opts metav1.DeleteOptions) error {
+	ctx, span := tracer.Start(ctx, "PolicySrv.Delete")
+	defer span.End()
+
 	if err := s.store.Policies().Delete(ctx, username, name, opts); err != nil {
 		return err
 	}
@@ -61,6 +73,9 @@ func (s *policyService) DeleteCollection(
 	names []string,
 	opts metav1.DeleteOptions,
 ) error {
+	ctx, span := tracer.Start(ctx, "PolicySrv.DeleteCollection")
+	defer span.End()
+
 	if err := s.store.Policies().DeleteCollection(ctx, username, names, opts); err != nil {
 		return errors.WithCode(code.ErrDatabase, err.Error())
 	}
@@ -69,6 +84,9 @@ func (s *policyService) DeleteCollection(
 }
 
 func (s *policyService) Get(ctx context.Context, username, name string, opts metav1.GetOptions) (*v1.Policy, error) {
+	ctx, span := tracer.Start(ctx, "PolicySrv.Get")
+	defer span.End()
+
 	policy, err := s.store.Policies().Get(ctx, username, name, opts)
 	if err != nil {
 		return nil, err
@@ -78,6 +96,9 @@ func (s *policyService) Get(ctx context.Context, username, name string, opts met
 }
 
 func (s *policyService) List(ctx context.Context, username string, opts metav1.ListOptions) (*v1.PolicyList, error) {
+	ctx, span := tracer.Start(ctx, "PolicySrv.List")
+	defer span.End()
+
 	policies, err := s.store.Policies().List(ctx, username, opts)
 	if err != nil {
 		return nil, errors.WithCode(code.ErrDatabase, err.Error())
@@ -85,3 +106,62 @@ func (s *policyService) List(ctx context.Context, username string, opts metav1.L
 
 	return policies, nil
 }
+
+// Apply makes the policies of an user match the given ones in one transaction: missing
+// policies are created, changed ones updated and the others deleted. A dry run only
+// reports the changes. Applying no policies deletes all policies of the user, so it
+// must be allowed explicitly.
+func (s *policyService) Apply(
+	ctx context.Context,
+	username string,
+	policies []*v1.Policy,
+	opts v1.PolicyApplyOptions,
+) (*v1.PolicyApplyResult, error) {
+	ctx, span := tracer.Start(ctx, "PolicySrv.Apply")
+	defer span.End()
+
+	if len(policies) == 0 && !opts.AllowEmpty {
+		return nil, errors.WithCode(code.ErrValidation, "no policies to apply, allow empty to delete all policies of %s", username)
+	}
+
+	names := sets.NewString()
+	for _, pol := range policies {
+		if errs := pol.Validate(); len(errs) != 0 {
+			return nil, errors.WithCode(code.ErrValidation, "policy %s: %s", pol.Name, errs.ToAggregate().Error())
+		}
+
+		if pol.Username != "" && pol.Username != username {
+			return nil, errors.WithCode(code.ErrValidation, "policy %s belongs to %s, not %s", pol.Name, pol.Username, username)
+		}
+
+		if names.Has(pol.Name) {
+			return nil, errors.WithCode(code.ErrValidation, "policy %s is duplicated", pol.Name)
+		}
+		names.Insert(pol.Name)
+
+		pol.Username = username
+	}
+
+	if _, err := s.store.Users().Get(ctx, username, metav1.GetOptions{}); err != nil {
+		return nil, err
+	}
+
+	all := int64(-1)
+	current, err := s.store.Policies().List(ctx, username, metav1.ListOptions{Limit: &all})
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	plan, changes := snapshot.PlanPolicies(snapshot.SnapshotPolicies(current.Items), snapshot.DesiredPolicies(policies))
+
+	result := &v1.PolicyApplyResult{Username: username, DryRun: opts.DryRun, Changes: changes}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	if err := s.store.Snapshots().Apply(ctx, plan); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,55 @@
+package v1
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/apiserver/store/mysql/mysqltest"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+func TestPolicyService_ApplyEmpty(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         v1.PolicyApplyOptions
+		wantCode     int
+		wantPolicies bool
+	}{
+		{"refused", v1.PolicyApplyOptions{}, code.ErrValidation, true},
+		{"dry run refused", v1.PolicyApplyOptions{DryRun: true}, code.ErrValidation, true},
+		{"allowed", v1.PolicyApplyOptions{AllowEmpty: true}, code.ErrSuccess, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := mysqltest.NewFactory(t)
+			createUsers(t, factory, "colin")
+			createOwned(t, factory, "colin")
+
+			result, err := newPolicies(&service{store: factory}).Apply(context.Background(), "colin", nil, tt.opts)
+			if tt.wantCode != code.ErrSuccess {
+				if !errors.IsCode(err, tt.wantCode) {
+					t.Fatalf("Apply() error = %v, want code %d", err, tt.wantCode)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Apply() error = %v", err)
+				}
+				if len(result.Changes) != 1 || result.Changes[0].Action != v1.SnapshotActionDelete {
+					t.Errorf("Apply() changes = %+v, want the deletion of the policy", result.Changes)
+				}
+			}
+
+			policies, err := factory.Policies().List(context.Background(), "colin", metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("list policies: %v", err)
+			}
+			if got := policies.TotalCount != 0; got != tt.wantPolicies {
+				t.Errorf("colin owns policies = %v, want %v", got, tt.wantPolicies)
+			}
+		})
+	}
+}
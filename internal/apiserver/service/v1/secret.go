@@ -33,6 +33,9 @@ func newSecrets(srv *service) SecretSrv {
 }
 
 func (s *secretService) Create(ctx context.Context, secret *v1.Secret, opts metav1.CreateOptions) error {
+	ctx, span := tracer.Start(ctx, "SecretSrv.Create")
+	defer span.End()
+
 	if err := s.store.Secrets().Create(ctx, secret, opts); err != nil {
 		return errors.WithCode(code.ErrDatabase, err.Error())
 	}
@@ -41,6 +44,9 @@ func (s *secretService) Create(ctx context.Context, secret *v1.Secret, opts meta
 }
 
 func (s *secretService) Update(ctx context.Context, secret *v1.Secret, opts metav1.UpdateOptions) error {
+	ctx, span := tracer.Start(ctx, "SecretSrv.Update")
+	defer span.End()
+
 	// Save changed fields.
 	if err := s.store.Secrets().Update(ctx, secret, opts); err != nil {
 		return errors.WithCode(code.ErrDatabase, err.Error())
@@ -50,6 +56,9 @@ func (s *secretService) Update(ctx context.Context, secret *v1.Secret, opts meta
 }
 
 func (s *secretService) Delete(ctx context.Context, username, secretID string, opts metav1.DeleteOptions) error {
+	ctx, span := tracer.Start(ctx, "SecretSrv.Delete")
+	defer span.End()
+
 	if err := s.store.Secrets().Delete(ctx, username, secretID, opts); err != nil {
 		return err
 	}
@@ -63,6 +72,9 @@ func (s *secretService) DeleteCollection(
 	secretIDs []string,
 	opts metav1.DeleteOptions,
 ) error {
+	ctx, span := tracer.Start(ctx, "SecretSrv.DeleteCollection")
+	defer span.End()
+
 	if err := s.store.Secrets().DeleteCollection(ctx, username, secretIDs, opts); err != nil {
 		return errors.WithCode(code.ErrDatabase, err.Error())
 	}
@@ -75,6 +87,9 @@ func (s *secretService) Get(
 	username, secretID string,
 	opts metav1.GetOptions,
 ) (*v1.Secret, error) {
+	ctx, span := tracer.Start(ctx, "SecretSrv.Get")
+	defer span.End()
+
 	secret, err := s.store.Secrets().Get(ctx, username, secretID, opts)
 	if err != nil {
 		return nil, err
@@ -84,6 +99,9 @@ func (s *secretService) Get(
 }
 
 func (s *secretService) List(ctx context.Context, username string, opts metav1.ListOptions) (*v1.SecretList, error) {
+	ctx, span := tracer.Start(ctx, "SecretSrv.List")
+	defer span.End()
+
 	secrets, err := s.store.Secrets().List(ctx, username, opts)
 	if err != nil {
 		return nil, errors.WithCode(code.ErrDatabase, err.Error())
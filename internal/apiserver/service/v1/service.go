@@ -1,12 +1,24 @@
 package v1
 
-import "github.com/rose839/IAM/internal/apiserver/store"
+import (
+	"go.opentelemetry.io/otel"
+
+	"github.com/rose839/IAM/internal/apiserver/store"
+)
+
+// tracer starts the spans of the service methods, children of the spans of the requests.
+var tracer = otel.Tracer("github.com/rose839/IAM/internal/apiserver/service/v1")
 
 // Service defines functions used to return resource interface.
 type Service interface {
 	Users() UserSrv
 	Secrets() SecretSrv
 	Policies() PolicySrv
+	Groups() GroupSrv
+	ServiceAccounts() ServiceAccountSrv
+	AuditEvents() AuditEventSrv
+	Webhooks() WebhookSrv
+	Snapshots() SnapshotSrv
 }
 
 type service struct {
@@ -30,3 +42,23 @@ func (s *service) Secrets() SecretSrv {
 func (s *service) Policies() PolicySrv {
 	return newPolicies(s)
 }
+
+func (s *service) Groups() GroupSrv {
+	return newGroups(s)
+}
+
+func (s *service) ServiceAccounts() ServiceAccountSrv {
+	return newServiceAccounts(s)
+}
+
+func (s *service) AuditEvents() AuditEventSrv {
+	return newAuditEvents(s)
+}
+
+func (s *service) Webhooks() WebhookSrv {
+	return newWebhooks(s)
+}
+
+func (s *service) Snapshots() SnapshotSrv {
+	return newSnapshots(s)
+}
@@ -0,0 +1,146 @@
+package v1
+
+import (
+	"context"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/apiserver/store"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+// ServiceAccountSrv defines functions used to handle service account request.
+type ServiceAccountSrv interface {
+	Create(ctx context.Context, sa *v1.ServiceAccount, opts metav1.CreateOptions) error
+	Update(ctx context.Context, sa *v1.ServiceAccount, opts metav1.UpdateOptions) error
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.ServiceAccount, error)
+	List(ctx context.Context, owners []string, opts metav1.ListOptions) (*v1.ServiceAccountList, error)
+	Owners(ctx context.Context, username string) ([]string, error)
+}
+
+type serviceAccountService struct {
+	store store.Factory
+}
+
+var _ ServiceAccountSrv = (*serviceAccountService)(nil)
+
+func newServiceAccounts(srv *service) ServiceAccountSrv {
+	return &serviceAccountService{
+		store: srv.store,
+	}
+}
+
+// Create creates a service account owned by an existing user or group.
+func (s *serviceAccountService) Create(ctx context.Context, sa *v1.ServiceAccount, opts metav1.CreateOptions) error {
+	ctx, span := tracer.Start(ctx, "ServiceAccountSrv.Create")
+	defer span.End()
+
+	if err := s.checkOwner(ctx, sa.Owner); err != nil {
+		return err
+	}
+
+	if err := s.store.ServiceAccounts().Create(ctx, sa, opts); err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return nil
+}
+
+func (s *serviceAccountService) Update(ctx context.Context, sa *v1.ServiceAccount, opts metav1.UpdateOptions) error {
+	ctx, span := tracer.Start(ctx, "ServiceAccountSrv.Update")
+	defer span.End()
+
+	if err := s.checkOwner(ctx, sa.Owner); err != nil {
+		return err
+	}
+
+	if err := s.store.ServiceAccounts().Update(ctx, sa, opts); err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return nil
+}
+
+func (s *serviceAccountService) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	ctx, span := tracer.Start(ctx, "ServiceAccountSrv.Delete")
+	defer span.End()
+
+	if err := s.store.ServiceAccounts().Delete(ctx, name, opts); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *serviceAccountService) Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.ServiceAccount, error) {
+	ctx, span := tracer.Start(ctx, "ServiceAccountSrv.Get")
+	defer span.End()
+
+	sa, err := s.store.ServiceAccounts().Get(ctx, name, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return sa, nil
+}
+
+func (s *serviceAccountService) List(
+	ctx context.Context,
+	owners []string,
+	opts metav1.ListOptions,
+) (*v1.ServiceAccountList, error) {
+	ctx, span := tracer.Start(ctx, "ServiceAccountSrv.List")
+	defer span.End()
+
+	sas, err := s.store.ServiceAccounts().List(ctx, owners, opts)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return sas, nil
+}
+
+// Owners returns the owners whose service accounts username can manage: the user
+// itself and every group it is a member of.
+func (s *serviceAccountService) Owners(ctx context.Context, username string) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "ServiceAccountSrv.Owners")
+	defer span.End()
+
+	groups, err := s.store.Groups().ListByUsers(ctx, []string{username})
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	owners := []string{v1.UserOwner(username)}
+	for _, group := range groups[username] {
+		owners = append(owners, v1.GroupSubject(group))
+	}
+
+	return owners, nil
+}
+
+// checkOwner makes sure the owner is a person or a group, service accounts can not own each other.
+func (s *serviceAccountService) checkOwner(ctx context.Context, owner string) error {
+	prefix, name := v1.ParseOwner(owner)
+	switch prefix {
+	case v1.UserOwnerPrefix:
+		user, err := s.store.Users().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		if user.IsServiceAccount() {
+			return errors.WithCode(code.ErrValidation, "Service account `%s` can not own service accounts.", name)
+		}
+	case v1.GroupSubjectPrefix:
+		if _, err := s.store.Groups().Get(ctx, name, metav1.GetOptions{}); err != nil {
+			return err
+		}
+	default:
+		return errors.WithCode(code.ErrValidation, "Owner must be user:<name> or group:<name>.")
+	}
+
+	return nil
+}
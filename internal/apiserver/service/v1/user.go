@@ -3,12 +3,17 @@ package v1
 import (
 	"context"
 	"sync"
+	"time"
 
 	v1 "github.com/rose839/IAM/api/apiserver/v1"
 	metav1 "github.com/rose839/IAM/api/meta/v1"
 	"github.com/rose839/IAM/internal/apiserver/store"
+	"github.com/rose839/IAM/internal/apiserver/verification"
 	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/auth"
 	"github.com/rose839/IAM/pkg/errors"
+	"github.com/rose839/IAM/pkg/log"
+	"github.com/rose839/IAM/pkg/validation"
 )
 
 // UserSrv defines functions used to handle user request.
@@ -21,6 +26,10 @@ type UserSrv interface {
 	List(ctx context.Context, opts metav1.ListOptions) (*v1.UserList, error)
 	ListWithBadPerformance(ctx context.Context, opts metav1.ListOptions) (*v1.UserList, error)
 	ChangePassword(ctx context.Context, user *v1.User) error
+	RequestPasswordReset(ctx context.Context, username string) error
+	ResetPassword(ctx context.Context, token string, password string) error
+	RequestEmailVerification(ctx context.Context, username string) error
+	VerifyEmail(ctx context.Context, token string) error
 }
 
 type userService struct {
@@ -36,14 +45,31 @@ func newUsers(srv *service) UserSrv {
 }
 
 func (u *userService) Create(ctx context.Context, user *v1.User, opts metav1.CreateOptions) error {
-	if err := u.store.Users().Create(ctx, user, opts); err != nil {
-		return errors.WithCode(code.ErrDatabase, err.Error())
+	ctx, span := tracer.Start(ctx, "UserSrv.Create")
+	defer span.End()
+
+	// the password is encrypted in place by the store, hash it for the history beforehand.
+	hash, err := auth.Encrypt(user.Password)
+	if err != nil {
+		return errors.WithCode(code.ErrEncrypt, err.Error())
 	}
 
-	return nil
+	user.PasswordChangedAt = time.Now()
+
+	// the user is not created unless its password is recorded in the history.
+	return u.store.Transaction(ctx, func(factory store.Factory) error {
+		if err := factory.Users().Create(ctx, user, opts); err != nil {
+			return errors.WithCode(code.ErrDatabase, err.Error())
+		}
+
+		return recordPassword(ctx, factory, user.Name, hash)
+	})
 }
 
 func (u *userService) Update(ctx context.Context, user *v1.User, opts metav1.UpdateOptions) error {
+	ctx, span := tracer.Start(ctx, "UserSrv.Update")
+	defer span.End()
+
 	if err := u.store.Users().Update(ctx, user, opts); err != nil {
 		return errors.WithCode(code.ErrDatabase, err.Error())
 	}
@@ -52,6 +78,9 @@ func (u *userService) Update(ctx context.Context, user *v1.User, opts metav1.Upd
 }
 
 func (u *userService) Delete(ctx context.Context, username string, opts metav1.DeleteOptions) error {
+	ctx, span := tracer.Start(ctx, "UserSrv.Delete")
+	defer span.End()
+
 	if err := u.store.Users().Delete(ctx, username, opts); err != nil {
 		return err
 	}
@@ -60,6 +89,9 @@ func (u *userService) Delete(ctx context.Context, username string, opts metav1.D
 }
 
 func (u *userService) DeleteCollection(ctx context.Context, usernames []string, opts metav1.DeleteOptions) error {
+	ctx, span := tracer.Start(ctx, "UserSrv.DeleteCollection")
+	defer span.End()
+
 	if err := u.store.Users().DeleteCollection(ctx, usernames, opts); err != nil {
 		return errors.WithCode(code.ErrDatabase, err.Error())
 	}
@@ -68,6 +100,9 @@ func (u *userService) DeleteCollection(ctx context.Context, usernames []string,
 }
 
 func (u *userService) Get(ctx context.Context, username string, opts metav1.GetOptions) (*v1.User, error) {
+	ctx, span := tracer.Start(ctx, "UserSrv.Get")
+	defer span.End()
+
 	user, err := u.store.Users().Get(ctx, username, opts)
 	if err != nil {
 		return nil, err
@@ -78,6 +113,9 @@ func (u *userService) Get(ctx context.Context, username string, opts metav1.GetO
 
 // List returns user list in the storage. This function has a good performance.
 func (u *userService) List(ctx context.Context, opts metav1.ListOptions) (*v1.UserList, error) {
+	ctx, span := tracer.Start(ctx, "UserSrv.List")
+	defer span.End()
+
 	users, err := u.store.Users().List(ctx, opts)
 	if err != nil {
 		return nil, errors.WithCode(code.ErrDatabase, err.Error())
@@ -115,6 +153,8 @@ func (u *userService) List(ctx context.Context, opts metav1.ListOptions) (*v1.Us
 				Email:       user.Email,
 				Phone:       user.Phone,
 				TotalPolicy: policies.TotalCount,
+				Type:        user.Type,
+				Owner:       user.Owner,
 			})
 		}(user)
 	}
@@ -141,6 +181,9 @@ func (u *userService) List(ctx context.Context, opts metav1.ListOptions) (*v1.Us
 
 // ListWithBadPerformance returns user list in the storage. This function has a bad performance.
 func (u *userService) ListWithBadPerformance(ctx context.Context, opts metav1.ListOptions) (*v1.UserList, error) {
+	ctx, span := tracer.Start(ctx, "UserSrv.ListWithBadPerformance")
+	defer span.End()
+
 	users, err := u.store.Users().List(ctx, opts)
 	if err != nil {
 		return nil, errors.WithCode(code.ErrDatabase, err.Error())
@@ -164,14 +207,202 @@ func (u *userService) ListWithBadPerformance(ctx context.Context, opts metav1.Li
 			Email:       user.Email,
 			Phone:       user.Phone,
 			TotalPolicy: policies.TotalCount,
+			Type:        user.Type,
+			Owner:       user.Owner,
 		})
 	}
 
 	return &v1.UserList{ListMeta: users.ListMeta, Items: infos}, nil
 }
 
+// ChangePassword saves the new plain text password of user, reusing one of the
+// recent passwords is refused.
 func (u *userService) ChangePassword(ctx context.Context, user *v1.User) error {
+	ctx, span := tracer.Start(ctx, "UserSrv.ChangePassword")
+	defer span.End()
+
+	if user.IsServiceAccount() {
+		return errors.WithCode(code.ErrPermissionDenied, "Service account `%s` has no password.", user.Name)
+	}
+
+	if err := u.checkPasswordHistory(ctx, user.Name, user.Password); err != nil {
+		return err
+	}
+
+	hash, err := auth.Encrypt(user.Password)
+	if err != nil {
+		return errors.WithCode(code.ErrEncrypt, err.Error())
+	}
+
 	// Save changed fields.
+	user.PasswordChangedAt = time.Now()
+
+	return u.store.Transaction(ctx, func(factory store.Factory) error {
+		if err := factory.Users().Update(ctx, user, metav1.UpdateOptions{}); err != nil {
+			return errors.WithCode(code.ErrDatabase, err.Error())
+		}
+
+		return recordPassword(ctx, factory, user.Name, hash)
+	})
+}
+
+// checkPasswordHistory returns an error if password matches one of the recent passwords of username.
+func (u *userService) checkPasswordHistory(ctx context.Context, username, password string) error {
+	size := validation.GetPasswordPolicy().HistorySize
+	if size <= 0 {
+		return nil
+	}
+
+	histories, err := u.store.PasswordHistories().List(ctx, username, size)
+	if err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	for _, history := range histories {
+		if auth.Compare(history.Password, password) == nil {
+			return errors.WithCode(code.ErrValidation, "password must not be one of the last %d passwords", size)
+		}
+	}
+
+	return nil
+}
+
+// recordPassword adds the password hash to the history of username and forgets the old ones.
+func recordPassword(ctx context.Context, factory store.Factory, username, hash string) error {
+	size := validation.GetPasswordPolicy().HistorySize
+	if size <= 0 {
+		return nil
+	}
+
+	history := &v1.PasswordHistory{Username: username, Password: hash}
+	if err := factory.PasswordHistories().Create(ctx, history); err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	if err := factory.PasswordHistories().Prune(ctx, username, size); err != nil {
+		log.L(ctx).Warnf("Prune password history of user `%s` failed: %s", username, err.Error())
+	}
+
+	return nil
+}
+
+// RequestPasswordReset sends a password reset token to the email address of username.
+// Unknown users are not reported to the caller, so the api can not be used to probe usernames.
+func (u *userService) RequestPasswordReset(ctx context.Context, username string) error {
+	ctx, span := tracer.Start(ctx, "UserSrv.RequestPasswordReset")
+	defer span.End()
+
+	user, err := u.store.Users().Get(ctx, username, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsCode(err, code.ErrUserNotFound) {
+			log.L(ctx).Infof("Password reset requested for unknown user `%s`", username)
+
+			return nil
+		}
+
+		return err
+	}
+
+	if user.IsServiceAccount() {
+		log.L(ctx).Infof("Password reset requested for service account `%s`", username)
+
+		return nil
+	}
+
+	verifier, err := verification.GetVerifierOr(nil, nil)
+	if err != nil {
+		return errors.WithCode(code.ErrUnknown, err.Error())
+	}
+
+	return verifier.Send(ctx, verification.PasswordReset, user)
+}
+
+// ResetPassword sets the password of the user the reset token was issued to.
+// The token is only consumed once the password is changed.
+func (u *userService) ResetPassword(ctx context.Context, token string, password string) error {
+	ctx, span := tracer.Start(ctx, "UserSrv.ResetPassword")
+	defer span.End()
+
+	verifier, err := verification.GetVerifierOr(nil, nil)
+	if err != nil {
+		return errors.WithCode(code.ErrUnknown, err.Error())
+	}
+
+	claim, err := verifier.Peek(verification.PasswordReset, token)
+	if err != nil {
+		return err
+	}
+
+	user, err := u.store.Users().Get(ctx, claim.Username, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	if err := validation.IsValidUserPassword(user.Name, password); err != nil {
+		return errors.WithCode(code.ErrValidation, err.Error())
+	}
+
+	user.Password = password
+	if err := u.ChangePassword(ctx, user); err != nil {
+		return err
+	}
+
+	// the token is kept when the password is not changed, so that the user can retry.
+	if _, err := verifier.Consume(verification.PasswordReset, token); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RequestEmailVerification sends an email verification token to the email address of username.
+func (u *userService) RequestEmailVerification(ctx context.Context, username string) error {
+	ctx, span := tracer.Start(ctx, "UserSrv.RequestEmailVerification")
+	defer span.End()
+
+	user, err := u.store.Users().Get(ctx, username, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	if user.IsServiceAccount() {
+		return errors.WithCode(code.ErrValidation, "Service account `%s` has no email address.", username)
+	}
+
+	verifier, err := verification.GetVerifierOr(nil, nil)
+	if err != nil {
+		return errors.WithCode(code.ErrUnknown, err.Error())
+	}
+
+	return verifier.Send(ctx, verification.EmailVerification, user)
+}
+
+// VerifyEmail marks the email address the token was issued for as verified, as long
+// as it is still the email address of the user.
+func (u *userService) VerifyEmail(ctx context.Context, token string) error {
+	ctx, span := tracer.Start(ctx, "UserSrv.VerifyEmail")
+	defer span.End()
+
+	verifier, err := verification.GetVerifierOr(nil, nil)
+	if err != nil {
+		return errors.WithCode(code.ErrUnknown, err.Error())
+	}
+
+	claim, err := verifier.Consume(verification.EmailVerification, token)
+	if err != nil {
+		return err
+	}
+
+	user, err := u.store.Users().Get(ctx, claim.Username, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	if user.Email != claim.Email {
+		return errors.WithCode(code.ErrVerificationTokenInvalid, "Email address has changed since the token was issued.")
+	}
+
+	user.EmailVerified = true
 	if err := u.store.Users().Update(ctx, user, metav1.UpdateOptions{}); err != nil {
 		return errors.WithCode(code.ErrDatabase, err.Error())
 	}
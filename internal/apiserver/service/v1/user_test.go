@@ -0,0 +1,108 @@
+package v1
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/apiserver/store"
+	"github.com/rose839/IAM/internal/apiserver/store/mysql/mysqltest"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+// createOwned creates a secret and a policy owned by each of the usernames.
+func createOwned(t *testing.T, factory store.Factory, usernames ...string) {
+	t.Helper()
+	ctx := context.Background()
+
+	for _, username := range usernames {
+		secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: username + "-secret"}, Username: username}
+		if err := factory.Secrets().Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("create secret of %s: %v", username, err)
+		}
+
+		policy := &v1.Policy{ObjectMeta: metav1.ObjectMeta{Name: username + "-policy"}, Username: username}
+		if err := factory.Policies().Create(ctx, policy, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("create policy of %s: %v", username, err)
+		}
+	}
+}
+
+func createServiceAccount(t *testing.T, factory store.Factory, name, owner string) {
+	t.Helper()
+
+	sa := &v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: name}, Owner: owner}
+	if err := factory.ServiceAccounts().Create(context.Background(), sa, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create service account %s: %v", name, err)
+	}
+}
+
+// owns reports whether username still owns its secret and policy.
+func owns(t *testing.T, factory store.Factory, username string) bool {
+	t.Helper()
+	ctx := context.Background()
+
+	secrets, err := factory.Secrets().List(ctx, username, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("list secrets of %s: %v", username, err)
+	}
+
+	policies, err := factory.Policies().List(ctx, username, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("list policies of %s: %v", username, err)
+	}
+
+	return secrets.TotalCount != 0 || policies.TotalCount != 0
+}
+
+func TestUserService_DeleteCascades(t *testing.T) {
+	tests := []struct {
+		name   string
+		delete func(srv UserSrv) error
+	}{
+		{"delete", func(srv UserSrv) error {
+			return srv.Delete(context.Background(), "colin", metav1.DeleteOptions{Unscoped: true})
+		}},
+		{"delete collection", func(srv UserSrv) error {
+			return srv.DeleteCollection(context.Background(), []string{"colin"}, metav1.DeleteOptions{Unscoped: true})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := mysqltest.NewFactory(t)
+			createUsers(t, factory, "colin", "alice")
+			createServiceAccount(t, factory, "colin-bot", v1.UserOwnerPrefix+"colin")
+			createServiceAccount(t, factory, "alice-bot", v1.UserOwnerPrefix+"alice")
+			createOwned(t, factory, "colin", "colin-bot", "alice", "alice-bot")
+			ctx := context.Background()
+
+			if err := tt.delete(NewService(factory).Users()); err != nil {
+				t.Fatalf("delete error = %+v", err)
+			}
+
+			if _, err := factory.Users().Get(ctx, "colin", metav1.GetOptions{}); !errors.IsCode(err, code.ErrUserNotFound) {
+				t.Errorf("Get() deleted user error = %v, want ErrUserNotFound", err)
+			}
+			_, err := factory.ServiceAccounts().Get(ctx, "colin-bot", metav1.GetOptions{})
+			if !errors.IsCode(err, code.ErrServiceAccountNotFound) {
+				t.Errorf("Get() service account of deleted user error = %v, want ErrServiceAccountNotFound", err)
+			}
+			for _, username := range []string{"colin", "colin-bot"} {
+				if owns(t, factory, username) {
+					t.Errorf("secrets or policies of %s are left", username)
+				}
+			}
+
+			if _, err := factory.ServiceAccounts().Get(ctx, "alice-bot", metav1.GetOptions{}); err != nil {
+				t.Errorf("Get() service account of another user error = %v", err)
+			}
+			for _, username := range []string{"alice", "alice-bot"} {
+				if !owns(t, factory, username) {
+					t.Errorf("secrets and policies of %s are deleted", username)
+				}
+			}
+		})
+	}
+}
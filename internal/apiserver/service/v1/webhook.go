@@ -0,0 +1,108 @@
+package v1
+
+import (
+	"context"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/apiserver/store"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/errors"
+	"github.com/rose839/IAM/pkg/idutil"
+)
+
+// WebhookSrv defines functions used to handle webhook request.
+type WebhookSrv interface {
+	Create(ctx context.Context, webhook *v1.Webhook, opts metav1.CreateOptions) error
+	Update(ctx context.Context, webhook *v1.Webhook, opts metav1.UpdateOptions) error
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Webhook, error)
+	List(ctx context.Context, opts metav1.ListOptions) (*v1.WebhookList, error)
+	ListDeliveries(ctx context.Context, name string, opts metav1.ListOptions) (*v1.WebhookDeliveryList, error)
+}
+
+type webhookService struct {
+	store store.Factory
+}
+
+var _ WebhookSrv = (*webhookService)(nil)
+
+func newWebhooks(srv *service) WebhookSrv {
+	return &webhookService{
+		store: srv.store,
+	}
+}
+
+// Create creates a webhook, a signing secret is generated when none is given.
+func (w *webhookService) Create(ctx context.Context, webhook *v1.Webhook, opts metav1.CreateOptions) error {
+	ctx, span := tracer.Start(ctx, "WebhookSrv.Create")
+	defer span.End()
+
+	if webhook.Secret == "" {
+		webhook.Secret = idutil.NewSecretKey()
+	}
+
+	if err := w.store.Webhooks().Create(ctx, webhook, opts); err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return nil
+}
+
+func (w *webhookService) Update(ctx context.Context, webhook *v1.Webhook, opts metav1.UpdateOptions) error {
+	ctx, span := tracer.Start(ctx, "WebhookSrv.Update")
+	defer span.End()
+
+	if err := w.store.Webhooks().Update(ctx, webhook, opts); err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return nil
+}
+
+func (w *webhookService) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	ctx, span := tracer.Start(ctx, "WebhookSrv.Delete")
+	defer span.End()
+
+	return w.store.Webhooks().Delete(ctx, name, opts)
+}
+
+func (w *webhookService) Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Webhook, error) {
+	ctx, span := tracer.Start(ctx, "WebhookSrv.Get")
+	defer span.End()
+
+	return w.store.Webhooks().Get(ctx, name, opts)
+}
+
+func (w *webhookService) List(ctx context.Context, opts metav1.ListOptions) (*v1.WebhookList, error) {
+	ctx, span := tracer.Start(ctx, "WebhookSrv.List")
+	defer span.End()
+
+	webhooks, err := w.store.Webhooks().List(ctx, opts)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return webhooks, nil
+}
+
+// ListDeliveries returns the delivery log of a webhook.
+func (w *webhookService) ListDeliveries(
+	ctx context.Context,
+	name string,
+	opts metav1.ListOptions,
+) (*v1.WebhookDeliveryList, error) {
+	ctx, span := tracer.Start(ctx, "WebhookSrv.ListDeliveries")
+	defer span.End()
+
+	if _, err := w.store.Webhooks().Get(ctx, name, metav1.GetOptions{}); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := w.store.WebhookDeliveries().List(ctx, name, opts)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,188 @@
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/gosuri/uitable"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	"github.com/rose839/IAM/internal/apiserver/options"
+	srvv1 "github.com/rose839/IAM/internal/apiserver/service/v1"
+	"github.com/rose839/IAM/internal/apiserver/snapshot"
+	"github.com/rose839/IAM/internal/apiserver/store/mysql"
+	"github.com/rose839/IAM/internal/pkg/middleware"
+	genericoptions "github.com/rose839/IAM/internal/pkg/options"
+	"github.com/rose839/IAM/pkg/app"
+	"github.com/rose839/IAM/pkg/log"
+	"github.com/rose839/IAM/pkg/storage"
+)
+
+// noticeTimeout bounds the time the subcommands wait for redis to publish their changes.
+const noticeTimeout = 10 * time.Second
+
+// newExportCommand returns the export subcommand writing a snapshot of all users,
+// secrets and policies.
+func newExportCommand() *app.Command {
+	opts := options.NewExportOptions()
+
+	return app.NewCommand(
+		"export",
+		"Export a snapshot of all users, secrets and policies",
+		app.WithCommandOptions(opts),
+		app.WithCommandConfig(),
+		app.WithCommandRunFunc(func(args []string) error {
+			initCommandLog()
+			defer log.Flush()
+
+			storeIns, err := mysql.GetMySQLFactoryOr(opts.MySQLOptions)
+			if err != nil {
+				return err
+			}
+			defer storeIns.Close()
+
+			snap, err := srvv1.NewService(storeIns).Snapshots().Export(context.Background(), opts.Passphrase)
+			if err != nil {
+				return err
+			}
+
+			data, err := snapshot.Marshal(snap, opts.Format)
+			if err != nil {
+				return err
+			}
+
+			if opts.Output == "-" {
+				_, err = os.Stdout.Write(data)
+
+				return err
+			}
+
+			return os.WriteFile(opts.Output, data, 0o600)
+		}),
+	)
+}
+
+// newImportCommand returns the import subcommand applying a snapshot, or only printing
+// the changes of applying it.
+func newImportCommand() *app.Command {
+	opts := options.NewImportOptions()
+
+	return app.NewCommand(
+		"import",
+		"Import a snapshot of users, secrets and policies",
+		app.WithCommandOptions(opts),
+		app.WithCommandConfig(),
+		app.WithCommandRunFunc(func(args []string) error {
+			initCommandLog()
+			defer log.Flush()
+
+			data, err := readSnapshotFile(opts.File)
+			if err != nil {
+				return err
+			}
+
+			snap, err := snapshot.Unmarshal(data)
+			if err != nil {
+				return fmt.Errorf("decode snapshot %s failed: %w", opts.File, err)
+			}
+
+			storeIns, err := mysql.GetMySQLFactoryOr(opts.MySQLOptions)
+			if err != nil {
+				return err
+			}
+			defer storeIns.Close()
+
+			result, err := srvv1.NewService(storeIns).Snapshots().Import(context.Background(), snap,
+				v1.SnapshotImportOptions{Mode: opts.Mode, DryRun: opts.DryRun, Passphrase: opts.Passphrase})
+			if err != nil {
+				return err
+			}
+
+			printImportResult(os.Stdout, result)
+
+			// the secrets and policies are cached by the other services
+			if result.DryRun || len(result.Changes) == 0 {
+				return nil
+			}
+
+			return publishNotices(opts.RedisOptions, middleware.NoticeSecretChanged, middleware.NoticePolicyChanged)
+		}),
+	)
+}
+
+// initCommandLog logs to stderr, stdout is kept for the output of the subcommands.
+func initCommandLog() {
+	opts := log.NewOptions()
+	opts.OutputPaths = []string{"stderr"}
+	log.Init(opts)
+}
+
+// publishNotices publishes the notices of the changes made by a subcommand, so that the
+// services caching the changed resources reload them.
+func publishNotices(opts *genericoptions.RedisOptions, notices ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), noticeTimeout)
+	defer cancel()
+
+	go storage.ConnectToRedis(ctx, redisConfig(opts))
+	for !storage.Connected() {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("changes are made, but the redis server to publish %v is not available", notices)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	for _, notice := range notices {
+		if err := middleware.Notify(ctx, notice); err != nil {
+			return fmt.Errorf("changes are made, but publishing %s failed: %w", notice, err)
+		}
+	}
+
+	return nil
+}
+
+func readSnapshotFile(file string) ([]byte, error) {
+	if file == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+
+	return os.ReadFile(file)
+}
+
+// printImportResult prints the changes of an import followed by a summary.
+func printImportResult(w io.Writer, result *v1.SnapshotImportResult) {
+	printChanges(w, result.Changes)
+
+	summary := "applied"
+	if result.DryRun {
+		summary = "would be applied (dry run)"
+	}
+	fmt.Fprintf(w, "%d changes %s in %s mode\n", len(result.Changes), summary, result.Mode)
+}
+
+// printChanges prints changes like a diff: + for creations, ~ for updates, - for deletions
+// and ! for objects skipped in create-only mode.
+func printChanges(w io.Writer, changes []v1.SnapshotChange) {
+	signs := map[string]string{
+		v1.SnapshotActionCreate: "+",
+		v1.SnapshotActionUpdate: "~",
+		v1.SnapshotActionDelete: "-",
+		v1.SnapshotActionSkip:   "!",
+	}
+
+	table := uitable.New()
+	table.Separator = " "
+	for _, change := range changes {
+		fields := ""
+		if len(change.Fields) > 0 {
+			fields = fmt.Sprintf("%v", change.Fields)
+		}
+		table.AddRow(signs[change.Action], change.Kind+"/"+change.Name, fields)
+	}
+	if len(changes) > 0 {
+		fmt.Fprintln(w, table)
+	}
+}
@@ -0,0 +1,121 @@
+package snapshot
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/scrypt"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+)
+
+// Algorithms the secret keys are encrypted with.
+const (
+	cipherAES256GCM = "aes-256-gcm"
+	kdfScrypt       = "scrypt"
+)
+
+// scrypt parameters recommended for interactive logins, and the salt size.
+const (
+	scryptN  = 1 << 15
+	scryptR  = 8
+	scryptP  = 1
+	keySize  = 32
+	saltSize = 16
+)
+
+// ErrPassphraseRequired is returned when an encrypted snapshot is imported without passphrase.
+var ErrPassphraseRequired = errors.New("the secret keys of the snapshot are encrypted, a passphrase is required")
+
+// Encrypt encrypts the secret keys of a snapshot with a key derived from the passphrase.
+// Every secret key is sealed with aes-256-gcm, its username and name are authenticated
+// as additional data so encrypted keys can not be swapped between secrets.
+func Encrypt(s *v1.Snapshot, passphrase string) error {
+	if s.Encryption != nil {
+		return errors.New("snapshot is encrypted already")
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return err
+	}
+
+	for _, secret := range s.Secrets {
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return err
+		}
+
+		sealed := aead.Seal(nonce, nonce, []byte(secret.SecretKey), additionalData(secret))
+		secret.SecretKey = base64.StdEncoding.EncodeToString(sealed)
+	}
+
+	s.Encryption = &v1.SnapshotEncryption{Cipher: cipherAES256GCM, KDF: kdfScrypt, Salt: salt}
+
+	return nil
+}
+
+// Decrypt decrypts the secret keys of an encrypted snapshot, plain snapshots are left untouched.
+func Decrypt(s *v1.Snapshot, passphrase string) error {
+	if s.Encryption == nil {
+		return nil
+	}
+
+	if passphrase == "" {
+		return ErrPassphraseRequired
+	}
+
+	if s.Encryption.Cipher != cipherAES256GCM || s.Encryption.KDF != kdfScrypt {
+		return fmt.Errorf("unsupported snapshot encryption %s with %s", s.Encryption.Cipher, s.Encryption.KDF)
+	}
+
+	aead, err := newAEAD(passphrase, s.Encryption.Salt)
+	if err != nil {
+		return err
+	}
+
+	for _, secret := range s.Secrets {
+		sealed, err := base64.StdEncoding.DecodeString(secret.SecretKey)
+		if err != nil || len(sealed) < aead.NonceSize() {
+			return fmt.Errorf("secret key of %s/%s is not encrypted", secret.Username, secret.Name)
+		}
+
+		key, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], additionalData(secret))
+		if err != nil {
+			return fmt.Errorf("decrypt secret key of %s/%s failed, the passphrase may be wrong", secret.Username, secret.Name)
+		}
+
+		secret.SecretKey = string(key)
+	}
+
+	s.Encryption = nil
+
+	return nil
+}
+
+func newAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keySize)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func additionalData(secret *v1.SnapshotSecret) []byte {
+	return []byte(secret.Username + "/" + secret.Name)
+}
@@ -0,0 +1,99 @@
+package snapshot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+)
+
+// manifestExtensions are the extensions of the manifest files read from a directory.
+var manifestExtensions = map[string]bool{".yaml": true, ".yml": true, ".json": true}
+
+// documentSeparator separates the manifests of a yaml file.
+var documentSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+// ReadPolicies reads the policy manifests of a yaml or json file, or of the files with
+// a .yaml, .yml or .json extension in a directory, in the order of their names. A yaml
+// file can hold several manifests separated by `---`.
+func ReadPolicies(path string) ([]*v1.Policy, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+
+		files = files[:0]
+		for _, entry := range entries {
+			if !entry.IsDir() && manifestExtensions[filepath.Ext(entry.Name())] {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+		sort.Strings(files)
+	}
+
+	var policies []*v1.Policy
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, doc := range documentSeparator.Split(string(data), -1) {
+			if strings.TrimSpace(doc) == "" {
+				continue
+			}
+
+			policy := &v1.Policy{}
+			if err := yaml.UnmarshalStrict([]byte(doc), policy); err != nil {
+				return nil, fmt.Errorf("decode manifest %d of %s failed: %w", i+1, file, err)
+			}
+
+			policies = append(policies, policy)
+		}
+	}
+
+	return policies, nil
+}
+
+// SnapshotPolicies converts policies into snapshot policies, which keep their identifiers.
+func SnapshotPolicies(policies []*v1.Policy) []*v1.SnapshotPolicy {
+	converted := make([]*v1.SnapshotPolicy, 0, len(policies))
+	for _, pol := range policies {
+		converted = append(converted, &v1.SnapshotPolicy{
+			ID:         pol.ID,
+			InstanceID: pol.InstanceID,
+			Username:   pol.Username,
+			Name:       pol.Name,
+			Policy:     pol.Policy,
+			Extend:     pol.Extend,
+		})
+	}
+
+	return converted
+}
+
+// DesiredPolicies converts applied policy manifests into snapshot policies. The
+// identifiers of the manifests are dropped: desired policies are matched to the
+// stored ones by username and name, and only the stored identifiers are used.
+func DesiredPolicies(policies []*v1.Policy) []*v1.SnapshotPolicy {
+	converted := SnapshotPolicies(policies)
+	for _, pol := range converted {
+		pol.ID = 0
+		pol.InstanceID = ""
+	}
+
+	return converted
+}
@@ -0,0 +1,96 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+)
+
+func TestReadPolicies(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"b.yaml": `
+metadata:
+  name: write
+policy:
+  effect: deny
+---
+metadata:
+  name: delete
+`,
+		"a.json":   `{"metadata": {"name": "read"}, "policy": {"effect": "allow"}}`,
+		"notes.md": "not a manifest",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	policies, err := ReadPolicies(dir)
+	if err != nil {
+		t.Fatalf("ReadPolicies() error: %v", err)
+	}
+
+	var names []string
+	for _, pol := range policies {
+		names = append(names, pol.Name)
+	}
+	if want := []string{"read", "write", "delete"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("ReadPolicies() read %v, want %v", names, want)
+	}
+	if policies[1].Policy.Effect != "deny" {
+		t.Errorf("policy write has effect %q, want deny", policies[1].Policy.Effect)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "c.yaml"), []byte("metadata:\n  nam: typo\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadPolicies(dir); err == nil {
+		t.Errorf("ReadPolicies() of a manifest with an unknown field succeeded")
+	}
+}
+
+func TestPlanPolicies(t *testing.T) {
+	policy := func(name, effect string) *v1.Policy {
+		pol := &v1.Policy{Username: "alice"}
+		pol.Name = name
+		pol.Policy.Effect = effect
+
+		return pol
+	}
+
+	current := []*v1.Policy{policy("read", "allow"), policy("write", "allow"), policy("delete", "deny")}
+	for i, pol := range current {
+		pol.ID = uint64(i + 1)
+	}
+	desired := []*v1.Policy{policy("read", "allow"), policy("write", "deny"), policy("list", "allow")}
+	// identifiers of the manifests must not reach the store
+	for _, pol := range desired {
+		pol.ID = 99
+		pol.InstanceID = "policy-forged"
+	}
+
+	plan, changes := PlanPolicies(SnapshotPolicies(current), DesiredPolicies(desired))
+
+	want := []v1.SnapshotChange{
+		{Kind: "policies", Name: "alice/list", Action: "create"},
+		{Kind: "policies", Name: "alice/write", Action: "update", Fields: []string{"policy"}},
+		{Kind: "policies", Name: "alice/delete", Action: "delete"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes = %+v, want %+v", changes, want)
+	}
+	if len(plan.Create.Policies) != 1 || plan.Create.Policies[0].ID != 0 || plan.Create.Policies[0].InstanceID != "" {
+		t.Errorf("created policies %+v, want list without identifiers", plan.Create.Policies)
+	}
+	if len(plan.Update.Policies) != 1 || plan.Update.Policies[0].ID != 2 || plan.Update.Policies[0].InstanceID != "" {
+		t.Errorf("updated policies %+v, want write with the current id 2", plan.Update.Policies)
+	}
+	if len(plan.Delete.Policies) != 1 || plan.Delete.Policies[0].ID != 3 {
+		t.Errorf("deleted policies %+v, want delete with the current id 3", plan.Delete.Policies)
+	}
+}
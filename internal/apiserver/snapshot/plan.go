@@ -0,0 +1,248 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	"github.com/rose839/IAM/internal/apiserver/store"
+	"github.com/rose839/IAM/pkg/sets"
+)
+
+var modes = sets.NewString(v1.SnapshotModeCreateOnly, v1.SnapshotModeUpsert, v1.SnapshotModeReplace)
+
+// Plan compares the desired snapshot with the current one and returns the plan and the
+// changes of importing it in the given mode. Updated and deleted objects carry the
+// identifiers of the current objects. Unchanged objects are neither planned nor reported.
+func Plan(current, desired *v1.Snapshot, mode string) (*store.SnapshotPlan, []v1.SnapshotChange, error) {
+	if !modes.Has(mode) {
+		return nil, nil, fmt.Errorf("unsupported import mode %s, must be one of %v", mode, modes.List())
+	}
+
+	if err := checkOwners(current, desired, mode); err != nil {
+		return nil, nil, err
+	}
+
+	plan, changes := planChanges(current, desired, mode)
+
+	return plan, changes, nil
+}
+
+// PlanPolicies compares the desired policies of an user with the current ones and returns
+// the plan and the changes of creating, updating and deleting policies to match them.
+func PlanPolicies(current, desired []*v1.SnapshotPolicy) (*store.SnapshotPlan, []v1.SnapshotChange) {
+	return planChanges(&v1.Snapshot{Policies: current}, &v1.Snapshot{Policies: desired}, v1.SnapshotModeReplace)
+}
+
+func planChanges(current, desired *v1.Snapshot, mode string) (*store.SnapshotPlan, []v1.SnapshotChange) {
+	p := &planner{mode: mode, plan: &store.SnapshotPlan{}}
+
+	currentUsers := map[string]*v1.SnapshotUser{}
+	for _, u := range current.Users {
+		currentUsers[u.Name] = u
+	}
+	desiredUsers := map[string]bool{}
+	for _, u := range sortedUsers(desired.Users) {
+		desiredUsers[u.Name] = true
+		c, ok := currentUsers[u.Name]
+		if ok {
+			u.ID = c.ID
+		}
+
+		switch p.compare("users", u.Name, c, u, ok) {
+		case v1.SnapshotActionCreate:
+			p.plan.Create.Users = append(p.plan.Create.Users, u)
+		case v1.SnapshotActionUpdate:
+			p.plan.Update.Users = append(p.plan.Update.Users, u)
+		}
+	}
+
+	currentSecrets := map[string]*v1.SnapshotSecret{}
+	for _, s := range current.Secrets {
+		currentSecrets[s.Username+"/"+s.Name] = s
+	}
+	desiredSecrets := map[string]bool{}
+	for _, s := range sortedSecrets(desired.Secrets) {
+		key := s.Username + "/" + s.Name
+		desiredSecrets[key] = true
+		c, ok := currentSecrets[key]
+		if ok {
+			s.ID = c.ID
+		}
+
+		switch p.compare("secrets", key, c, s, ok) {
+		case v1.SnapshotActionCreate:
+			p.plan.Create.Secrets = append(p.plan.Create.Secrets, s)
+		case v1.SnapshotActionUpdate:
+			p.plan.Update.Secrets = append(p.plan.Update.Secrets, s)
+		}
+	}
+
+	currentPolicies := map[string]*v1.SnapshotPolicy{}
+	for _, pol := range current.Policies {
+		currentPolicies[pol.Username+"/"+pol.Name] = pol
+	}
+	desiredPolicies := map[string]bool{}
+	for _, pol := range sortedPolicies(desired.Policies) {
+		key := pol.Username + "/" + pol.Name
+		desiredPolicies[key] = true
+		c, ok := currentPolicies[key]
+		if ok {
+			pol.ID = c.ID
+		}
+
+		switch p.compare("policies", key, c, pol, ok) {
+		case v1.SnapshotActionCreate:
+			p.plan.Create.Policies = append(p.plan.Create.Policies, pol)
+		case v1.SnapshotActionUpdate:
+			p.plan.Update.Policies = append(p.plan.Update.Policies, pol)
+		}
+	}
+
+	if mode == v1.SnapshotModeReplace {
+		for _, u := range sortedUsers(current.Users) {
+			if !desiredUsers[u.Name] {
+				p.plan.Delete.Users = append(p.plan.Delete.Users, u)
+				p.change("users", u.Name, v1.SnapshotActionDelete, nil)
+			}
+		}
+		for _, s := range sortedSecrets(current.Secrets) {
+			if key := s.Username + "/" + s.Name; !desiredSecrets[key] {
+				p.plan.Delete.Secrets = append(p.plan.Delete.Secrets, s)
+				p.change("secrets", key, v1.SnapshotActionDelete, nil)
+			}
+		}
+		for _, pol := range sortedPolicies(current.Policies) {
+			if key := pol.Username + "/" + pol.Name; !desiredPolicies[key] {
+				p.plan.Delete.Policies = append(p.plan.Delete.Policies, pol)
+				p.change("policies", key, v1.SnapshotActionDelete, nil)
+			}
+		}
+	}
+
+	return p.plan, p.changes
+}
+
+type planner struct {
+	mode    string
+	plan    *store.SnapshotPlan
+	changes []v1.SnapshotChange
+}
+
+// compare returns the action for the desired object and records it as change.
+// Nothing is done for unchanged objects, and for changed ones in create-only mode.
+func (p *planner) compare(kind, name string, current, desired interface{}, exists bool) string {
+	if !exists {
+		p.change(kind, name, v1.SnapshotActionCreate, nil)
+
+		return v1.SnapshotActionCreate
+	}
+
+	fields := changedFields(current, desired)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	if p.mode == v1.SnapshotModeCreateOnly {
+		p.change(kind, name, v1.SnapshotActionSkip, fields)
+
+		return v1.SnapshotActionSkip
+	}
+
+	p.change(kind, name, v1.SnapshotActionUpdate, fields)
+
+	return v1.SnapshotActionUpdate
+}
+
+func (p *planner) change(kind, name, action string, fields []string) {
+	p.changes = append(p.changes, v1.SnapshotChange{Kind: kind, Name: name, Action: action, Fields: fields})
+}
+
+// checkOwners checks the owners of the desired secrets and policies exist after the import.
+func checkOwners(current, desired *v1.Snapshot, mode string) error {
+	users := sets.NewString()
+	for _, u := range desired.Users {
+		users.Insert(u.Name)
+	}
+	if mode != v1.SnapshotModeReplace {
+		for _, u := range current.Users {
+			users.Insert(u.Name)
+		}
+	}
+
+	for _, s := range desired.Secrets {
+		if !users.Has(s.Username) {
+			return fmt.Errorf("user %s of secret %s does not exist", s.Username, s.Name)
+		}
+	}
+	for _, pol := range desired.Policies {
+		if !users.Has(pol.Username) {
+			return fmt.Errorf("user %s of policy %s does not exist", pol.Username, pol.Name)
+		}
+	}
+
+	return nil
+}
+
+// changedFields returns the sorted json names of the fields which differ between a and b.
+func changedFields(a, b interface{}) []string {
+	am, bm := toMap(a), toMap(b)
+
+	keys := sets.NewString()
+	for k := range am {
+		keys.Insert(k)
+	}
+	for k := range bm {
+		keys.Insert(k)
+	}
+
+	var fields []string
+	for _, k := range keys.List() {
+		if !reflect.DeepEqual(am[k], bm[k]) {
+			fields = append(fields, k)
+		}
+	}
+
+	return fields
+}
+
+func toMap(obj interface{}) map[string]interface{} {
+	if u, ok := obj.(*v1.SnapshotUser); ok {
+		normalized := *u
+		normalized.PasswordChangedAt = u.PasswordChangedAt.UTC()
+		obj = &normalized
+	}
+
+	m := map[string]interface{}{}
+	data, _ := json.Marshal(obj)
+	_ = json.Unmarshal(data, &m)
+
+	return m
+}
+
+func sortedUsers(users []*v1.SnapshotUser) []*v1.SnapshotUser {
+	sorted := append([]*v1.SnapshotUser(nil), users...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	return sorted
+}
+
+func sortedSecrets(secrets []*v1.SnapshotSecret) []*v1.SnapshotSecret {
+	sorted := append([]*v1.SnapshotSecret(nil), secrets...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Username+"/"+sorted[i].Name < sorted[j].Username+"/"+sorted[j].Name
+	})
+
+	return sorted
+}
+
+func sortedPolicies(policies []*v1.SnapshotPolicy) []*v1.SnapshotPolicy {
+	sorted := append([]*v1.SnapshotPolicy(nil), policies...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Username+"/"+sorted[i].Name < sorted[j].Username+"/"+sorted[j].Name
+	})
+
+	return sorted
+}
@@ -0,0 +1,57 @@
+// Package snapshot encodes, encrypts and plans the import of snapshots, the versioned
+// bundles of all users, secrets and policies used to back up iam and to move it
+// between environments. It also reads and plans the policy manifests synced from a
+// directory.
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+)
+
+// Formats a snapshot is encoded in.
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// New returns a snapshot of the given objects taken now.
+func New(users []*v1.SnapshotUser, secrets []*v1.SnapshotSecret, policies []*v1.SnapshotPolicy) *v1.Snapshot {
+	s := &v1.Snapshot{
+		CreatedAt: time.Now().UTC(),
+		Users:     users,
+		Secrets:   secrets,
+		Policies:  policies,
+	}
+	s.APIVersion = v1.SnapshotAPIVersion
+	s.Kind = v1.SnapshotKind
+
+	return s
+}
+
+// Marshal encodes a snapshot in the given format.
+func Marshal(s *v1.Snapshot, format string) ([]byte, error) {
+	switch format {
+	case FormatYAML:
+		return yaml.Marshal(s)
+	case FormatJSON:
+		return json.MarshalIndent(s, "", "  ")
+	default:
+		return nil, fmt.Errorf("unsupported snapshot format %s, must be %s or %s", format, FormatYAML, FormatJSON)
+	}
+}
+
+// Unmarshal decodes a snapshot encoded in yaml or json.
+func Unmarshal(data []byte) (*v1.Snapshot, error) {
+	s := &v1.Snapshot{}
+	if err := yaml.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
@@ -0,0 +1,204 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+)
+
+// password is the bcrypt hash of Admin@2021.
+const password = "$2a$10$Q9Qy1srirtlzRrgEnfcbheCBMQw6ysHohfna4Z0hfy0IAXcj9PuS."
+
+func testSnapshot() *v1.Snapshot {
+	return New(
+		[]*v1.SnapshotUser{
+			{Name: "alice", Type: v1.UserTypeHuman, Email: "alice@example.com", Password: password},
+			{Name: "bob", Type: v1.UserTypeHuman, Email: "bob@example.com", Password: password},
+		},
+		[]*v1.SnapshotSecret{
+			{Username: "alice", Name: "ci", SecretID: "id-1", SecretKey: "key-1", Description: "ci"},
+		},
+		[]*v1.SnapshotPolicy{
+			{Username: "alice", Name: "read"},
+		},
+	)
+}
+
+func TestMarshal(t *testing.T) {
+	for _, format := range []string{FormatYAML, FormatJSON} {
+		data, err := Marshal(testSnapshot(), format)
+		if err != nil {
+			t.Fatalf("Marshal(%s) error: %v", format, err)
+		}
+
+		s, err := Unmarshal(data)
+		if err != nil {
+			t.Fatalf("Unmarshal(%s) error: %v", format, err)
+		}
+
+		if errs := s.Validate(); len(errs) != 0 {
+			t.Errorf("%s snapshot is not valid: %v", format, errs)
+		}
+		if len(s.Users) != 2 || s.Secrets[0].SecretKey != "key-1" {
+			t.Errorf("%s snapshot was not decoded: %s", format, data)
+		}
+	}
+}
+
+func TestValidatePasswords(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     *v1.SnapshotUser
+		wantErrs int
+	}{
+		{"hashed", &v1.SnapshotUser{Name: "carol", Type: v1.UserTypeHuman, Password: password}, 0},
+		{"plain text", &v1.SnapshotUser{Name: "carol", Type: v1.UserTypeHuman, Password: "Admin@2021"}, 1},
+		{"empty", &v1.SnapshotUser{Name: "carol", Type: v1.UserTypeHuman}, 1},
+		{"service account", &v1.SnapshotUser{Name: "ci", Type: v1.UserTypeServiceAccount, Owner: "user:alice"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testSnapshot()
+			s.Users = append(s.Users, tt.user)
+
+			if errs := s.Validate(); len(errs) != tt.wantErrs {
+				t.Errorf("Validate() = %v, want %d errors", errs, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestEncrypt(t *testing.T) {
+	s := testSnapshot()
+	if err := Encrypt(s, "passphrase"); err != nil {
+		t.Fatalf("Encrypt() error: %v", err)
+	}
+	if s.Secrets[0].SecretKey == "key-1" || s.Encryption == nil {
+		t.Fatalf("secret key was not encrypted")
+	}
+
+	tests := []struct {
+		name       string
+		passphrase string
+		wantErr    bool
+	}{
+		{"missing passphrase", "", true},
+		{"wrong passphrase", "wrong", true},
+		{"passphrase", "passphrase", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := Marshal(s, FormatJSON)
+			encrypted, _ := Unmarshal(data)
+
+			err := Decrypt(encrypted, tt.passphrase)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decrypt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (encrypted.Secrets[0].SecretKey != "key-1" || encrypted.Encryption != nil) {
+				t.Errorf("secret key was not decrypted")
+			}
+		})
+	}
+}
+
+func TestPlan(t *testing.T) {
+	current := func() *v1.Snapshot {
+		s := testSnapshot()
+		for i, u := range s.Users {
+			u.ID = uint64(i + 1)
+		}
+
+		return s
+	}
+
+	desired := func() *v1.Snapshot {
+		s := testSnapshot()
+		s.Users = append(s.Users[:1], &v1.SnapshotUser{Name: "carol", Type: v1.UserTypeHuman})
+		s.Users[0].Email = "alice@example.org"
+		s.Secrets[0].Description = "deploy"
+		s.Policies = nil
+
+		return s
+	}
+
+	tests := []struct {
+		mode    string
+		want    []v1.SnapshotChange
+		creates int
+		updates int
+		deletes int
+	}{
+		{
+			mode: v1.SnapshotModeCreateOnly,
+			want: []v1.SnapshotChange{
+				{Kind: "users", Name: "alice", Action: "skip", Fields: []string{"email"}},
+				{Kind: "users", Name: "carol", Action: "create"},
+				{Kind: "secrets", Name: "alice/ci", Action: "skip", Fields: []string{"description"}},
+			},
+			creates: 1,
+		},
+		{
+			mode: v1.SnapshotModeUpsert,
+			want: []v1.SnapshotChange{
+				{Kind: "users", Name: "alice", Action: "update", Fields: []string{"email"}},
+				{Kind: "users", Name: "carol", Action: "create"},
+				{Kind: "secrets", Name: "alice/ci", Action: "update", Fields: []string{"description"}},
+			},
+			creates: 1,
+			updates: 2,
+		},
+		{
+			mode: v1.SnapshotModeReplace,
+			want: []v1.SnapshotChange{
+				{Kind: "users", Name: "alice", Action: "update", Fields: []string{"email"}},
+				{Kind: "users", Name: "carol", Action: "create"},
+				{Kind: "secrets", Name: "alice/ci", Action: "update", Fields: []string{"description"}},
+				{Kind: "users", Name: "bob", Action: "delete"},
+				{Kind: "policies", Name: "alice/read", Action: "delete"},
+			},
+			creates: 1,
+			updates: 2,
+			deletes: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			plan, changes, err := Plan(current(), desired(), tt.mode)
+			if err != nil {
+				t.Fatalf("Plan() error: %v", err)
+			}
+
+			if !reflect.DeepEqual(changes, tt.want) {
+				t.Errorf("changes = %+v, want %+v", changes, tt.want)
+			}
+
+			creates := len(plan.Create.Users) + len(plan.Create.Secrets) + len(plan.Create.Policies)
+			updates := len(plan.Update.Users) + len(plan.Update.Secrets) + len(plan.Update.Policies)
+			deletes := len(plan.Delete.Users) + len(plan.Delete.Secrets) + len(plan.Delete.Policies)
+			if creates != tt.creates || updates != tt.updates || deletes != tt.deletes {
+				t.Errorf("plan creates %d, updates %d, deletes %d, want %d, %d, %d",
+					creates, updates, deletes, tt.creates, tt.updates, tt.deletes)
+			}
+			if updates > 0 && plan.Update.Users[0].ID != 1 {
+				t.Errorf("updated user has id %d, want the current id 1", plan.Update.Users[0].ID)
+			}
+		})
+	}
+}
+
+func TestPlanMissingOwner(t *testing.T) {
+	desired := testSnapshot()
+	desired.Users = desired.Users[1:]
+
+	if _, _, err := Plan(testSnapshot(), desired, v1.SnapshotModeReplace); err == nil {
+		t.Errorf("Plan() replacing the owner of secrets succeeded")
+	}
+	if _, _, err := Plan(testSnapshot(), desired, v1.SnapshotModeUpsert); err != nil {
+		t.Errorf("Plan() keeping the owner of secrets error: %v", err)
+	}
+}
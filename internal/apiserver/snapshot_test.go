@@ -0,0 +1,52 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/rose839/IAM/internal/pkg/middleware"
+	genericoptions "github.com/rose839/IAM/internal/pkg/options"
+)
+
+func TestPublishNotices(t *testing.T) {
+	redisServer := miniredis.RunT(t)
+	subscriber := redisServer.NewSubscriber()
+	defer subscriber.Close()
+	subscriber.Subscribe(middleware.RedisPubSubChannel)
+
+	// miniredis blocks publishing until the subscriber receives the message
+	messages := make(chan miniredis.PubsubMessage, 2)
+	go func() {
+		for message := range subscriber.Messages() {
+			messages <- message
+		}
+	}()
+
+	opts := genericoptions.NewRedisOptions()
+	opts.Host = redisServer.Host()
+	opts.Port, _ = strconv.Atoi(redisServer.Port())
+	opts.Password = ""
+
+	if err := publishNotices(opts, middleware.NoticeSecretChanged, middleware.NoticePolicyChanged); err != nil {
+		t.Fatalf("publishNotices() error = %v", err)
+	}
+
+	for _, want := range []string{middleware.NoticeSecretChanged, middleware.NoticePolicyChanged} {
+		select {
+		case message := <-messages:
+			var notification middleware.Notification
+			if err := json.Unmarshal([]byte(message.Message), &notification); err != nil {
+				t.Fatalf("unmarshal notification %s: %v", message.Message, err)
+			}
+			if notification.Command != want {
+				t.Errorf("published %s, want %s", notification.Command, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s was not published", want)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+)
+
+// AuditEventStore defines the audit event storage interface.
+type AuditEventStore interface {
+	Create(ctx context.Context, event *v1.AuditEvent) error
+	List(ctx context.Context, opts metav1.ListOptions) (*v1.AuditEventList, error)
+	Prune(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,22 @@
+package store
+
+import (
+	"context"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+)
+
+// GroupStore defines the group storage interface.
+type GroupStore interface {
+	Create(ctx context.Context, group *v1.Group, opts metav1.CreateOptions) error
+	Update(ctx context.Context, group *v1.Group, opts metav1.UpdateOptions) error
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, names []string, opts metav1.DeleteOptions) error
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Group, error)
+	List(ctx context.Context, opts metav1.ListOptions) (*v1.GroupList, error)
+	AddMembers(ctx context.Context, name string, usernames []string) error
+	RemoveMembers(ctx context.Context, name string, usernames []string) error
+	ListMembers(ctx context.Context, name string) ([]string, error)
+	ListByUsers(ctx context.Context, usernames []string) (map[string][]string, error)
+}
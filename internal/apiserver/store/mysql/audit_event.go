@@ -0,0 +1,118 @@
+package mysql
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/db"
+	"github.com/rose839/IAM/pkg/errors"
+	"github.com/rose839/IAM/pkg/fields"
+	"gorm.io/gorm"
+)
+
+// auditEventColumns maps the field selector fields of audit events to their columns.
+var auditEventColumns = map[string]string{
+	"username":    "username",
+	"operator":    "operator",
+	"kind":        "kind",
+	"owner":       "owner",
+	"subresource": "subresource",
+	"verb":        "verb",
+	"requestID":   "requestID",
+	"code":        "code",
+}
+
+type auditEvents struct {
+	db *gorm.DB
+}
+
+func newAuditEvents(ds *dataStore) *auditEvents {
+	return &auditEvents{db: ds.db}
+}
+
+// Create creates a new audit event.
+func (a *auditEvents) Create(ctx context.Context, event *v1.AuditEvent) error {
+	return a.db.WithContext(ctx).Create(event).Error
+}
+
+// likeEscaper escapes the wildcards of a value matched with like ... escape '!'.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// List return the audit events matching the field selector, newest first. Besides the
+// columns in auditEventColumns, `since` and `until` restrict the time range with RFC3339
+// times, they only support the = operator. A name matches the events of collection
+// requests which name it among others.
+func (a *auditEvents) List(ctx context.Context, opts metav1.ListOptions) (*v1.AuditEventList, error) {
+	ret := &v1.AuditEventList{}
+	ol := db.Unpointer(opts.Offset, opts.Limit)
+
+	selector, err := fields.ParseSelector(opts.FieldSelector)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrValidation, err.Error())
+	}
+
+	d := a.db.WithContext(ctx)
+	for _, r := range selector.Requirements() {
+		switch r.Field {
+		case "since", "until":
+			if r.Operator == fields.NotEquals {
+				return nil, errors.WithCode(code.ErrValidation, "unsupported operator %s of field selector %s", r.Operator, r.Field)
+			}
+
+			t, err := time.Parse(time.RFC3339, r.Value)
+			if err != nil {
+				return nil, errors.WithCode(code.ErrValidation, "invalid %s time: %s", r.Field, err.Error())
+			}
+
+			if r.Field == "since" {
+				d = d.Where("timestamp >= ?", t)
+			} else {
+				d = d.Where("timestamp < ?", t)
+			}
+		case "name":
+			v := likeEscaper.Replace(r.Value)
+			query := "(name = ? or name like ? escape '!' or name like ? escape '!' or name like ? escape '!')"
+			args := []interface{}{r.Value, v + ",%", "%," + v, "%," + v + ",%"}
+			if r.Operator == fields.NotEquals {
+				d = d.Not(query, args...)
+			} else {
+				d = d.Where(query, args...)
+			}
+		default:
+			column, ok := auditEventColumns[r.Field]
+			if !ok {
+				return nil, errors.WithCode(code.ErrValidation, "unsupported field selector: %s", r.Field)
+			}
+
+			if r.Operator == fields.NotEquals {
+				d = d.Where(column+" <> ?", r.Value)
+			} else {
+				d = d.Where(column+" = ?", r.Value)
+			}
+		}
+	}
+
+	d = d.Offset(ol.Offset).
+		Limit(ol.Limit).
+		Order("id desc").
+		Find(&ret.Items).
+		Offset(-1).
+		Limit(-1).
+		Count(&ret.TotalCount)
+	if d.Error != nil {
+		return nil, errors.WithCode(code.ErrDatabase, d.Error.Error())
+	}
+
+	return ret, nil
+}
+
+// Prune deletes the audit events recorded before the given time.
+func (a *auditEvents) Prune(ctx context.Context, before time.Time) (int64, error) {
+	d := a.db.WithContext(ctx).Where("timestamp < ?", before).Delete(&v1.AuditEvent{})
+
+	return d.RowsAffected, d.Error
+}
@@ -0,0 +1,89 @@
+package mysql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/apiserver/store/mysql/mysqltest"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+func TestAuditEvents_List(t *testing.T) {
+	factory := mysqltest.NewFactory(t)
+	ctx := context.Background()
+
+	start := time.Date(2022, 5, 10, 0, 0, 0, 0, time.UTC)
+	for i, e := range []struct {
+		name string
+		verb string
+	}{
+		{"p1", "create"},
+		{"p1,p2", "delete"},
+		{"p10", "create"},
+		{"p2,p1,p3", "delete"},
+		{"p%", "create"},
+	} {
+		event := &v1.AuditEvent{
+			Timestamp: start.Add(time.Duration(i) * time.Hour),
+			Kind:      "policies",
+			Name:      e.name,
+			Verb:      e.verb,
+		}
+		if err := factory.AuditEvents().Create(ctx, event); err != nil {
+			t.Fatalf("create audit event: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		selector string
+		want     []string
+		wantCode int
+	}{
+		{"all", "", []string{"p%", "p2,p1,p3", "p10", "p1,p2", "p1"}, 0},
+		{"name among collection names", "name=p1", []string{"p2,p1,p3", "p1,p2", "p1"}, 0},
+		{"name not among collection names", "name!=p1", []string{"p%", "p10"}, 0},
+		{"escaped collection name", `name=p1\,p2`, []string{"p1,p2"}, 0},
+		{"wildcard name", "name=p%", []string{"p%"}, 0},
+		{"since", "since=2022-05-10T03:00:00Z", []string{"p%", "p2,p1,p3"}, 0},
+		{"until", "until=2022-05-10T01:00:00Z", []string{"p1"}, 0},
+		{"since and until", "since=2022-05-10T01:00:00Z,until=2022-05-10T03:00:00Z", []string{"p10", "p1,p2"}, 0},
+		{"column", "verb=delete,name!=p3", []string{"p1,p2"}, 0},
+		{"not equal since", "since!=2022-05-10T03:00:00Z", nil, code.ErrValidation},
+		{"not equal until", "until!=2022-05-10T03:00:00Z", nil, code.ErrValidation},
+		{"invalid time", "since=yesterday", nil, code.ErrValidation},
+		{"unsupported field", "clientIP=127.0.0.1", nil, code.ErrValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := factory.AuditEvents().List(ctx, metav1.ListOptions{FieldSelector: tt.selector})
+			if tt.wantCode != 0 {
+				if !errors.IsCode(err, tt.wantCode) {
+					t.Fatalf("List() error = %v, want code %d", err, tt.wantCode)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+
+			got := make([]string, 0, len(events.Items))
+			for _, event := range events.Items {
+				got = append(got, event.Name)
+			}
+			if len(got) != len(tt.want) || int(events.TotalCount) != len(tt.want) {
+				t.Fatalf("List() = %v (total %d), want %v", got, events.TotalCount, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("List() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package mysql
+
+import (
+	"context"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/db"
+	"github.com/rose839/IAM/pkg/errors"
+	"github.com/rose839/IAM/pkg/fields"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type groups struct {
+	db *gorm.DB
+}
+
+func newGroups(ds *dataStore) *groups {
+	return &groups{db: ds.db}
+}
+
+// Create creates a new group.
+func (g *groups) Create(ctx context.Context, group *v1.Group, opts metav1.CreateOptions) error {
+	return g.db.WithContext(ctx).Create(group).Error
+}
+
+// Update updates a group information.
+func (g *groups) Update(ctx context.Context, group *v1.Group, opts metav1.UpdateOptions) error {
+	return g.db.WithContext(ctx).Save(group).Error
+}
+
+// Delete deletes the group by the group identifier, memberships are deleted by the database.
+func (g *groups) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	d := g.db.WithContext(ctx)
+	if opts.Unscoped {
+		d = d.Unscoped()
+	}
+
+	err := d.Where("name = ?", name).Delete(&v1.Group{}).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return nil
+}
+
+// DeleteCollection batch deletes the groups.
+func (g *groups) DeleteCollection(ctx context.Context, names []string, opts metav1.DeleteOptions) error {
+	d := g.db.WithContext(ctx)
+	if opts.Unscoped {
+		d = d.Unscoped()
+	}
+
+	return d.Where("name in (?)", names).Delete(&v1.Group{}).Error
+}
+
+// Get return a group by the group identifier.
+func (g *groups) Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Group, error) {
+	group := &v1.Group{}
+	err := g.db.WithContext(ctx).Where("name = ?", name).First(group).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.WithCode(code.ErrGroupNotFound, err.Error())
+		}
+
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return group, nil
+}
+
+// List return all groups.
+func (g *groups) List(ctx context.Context, opts metav1.ListOptions) (*v1.GroupList, error) {
+	ret := &v1.GroupList{}
+	ol := db.Unpointer(opts.Offset, opts.Limit)
+
+	selector, _ := fields.ParseSelector(opts.FieldSelector)
+	name, _ := selector.RequiresExactMatch("name")
+	d := g.db.WithContext(ctx).Where("name like ?", "%"+name+"%").
+		Offset(ol.Offset).
+		Limit(ol.Limit).
+		Order("id desc").
+		Find(&ret.Items).
+		Offset(-1).
+		Limit(-1).
+		Count(&ret.TotalCount)
+
+	return ret, d.Error
+}
+
+// AddMembers adds users to a group, users which are already members are skipped.
+func (g *groups) AddMembers(ctx context.Context, name string, usernames []string) error {
+	if len(usernames) == 0 {
+		return nil
+	}
+
+	members := make([]*v1.GroupMember, 0, len(usernames))
+	for _, username := range usernames {
+		members = append(members, &v1.GroupMember{GroupName: name, Username: username})
+	}
+
+	return g.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&members).Error
+}
+
+// RemoveMembers removes users from a group.
+func (g *groups) RemoveMembers(ctx context.Context, name string, usernames []string) error {
+	return g.db.WithContext(ctx).Where("groupName = ? and username in (?)", name, usernames).Delete(&v1.GroupMember{}).Error
+}
+
+// ListMembers return the usernames of the members of a group.
+func (g *groups) ListMembers(ctx context.Context, name string) ([]string, error) {
+	ret := make([]string, 0)
+	err := g.db.WithContext(ctx).Model(&v1.GroupMember{}).
+		Where("groupName = ?", name).
+		Order("username").
+		Pluck("username", &ret).Error
+
+	return ret, err
+}
+
+// ListByUsers return the group names of every given user, keyed by username.
+func (g *groups) ListByUsers(ctx context.Context, usernames []string) (map[string][]string, error) {
+	ret := make(map[string][]string)
+	if len(usernames) == 0 {
+		return ret, nil
+	}
+
+	var members []*v1.GroupMember
+	err := g.db.WithContext(ctx).Where("username in (?)", usernames).Order("groupName").Find(&members).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, member := range members {
+		ret[member.Username] = append(ret[member.Username], member.GroupName)
+	}
+
+	return ret, nil
+}
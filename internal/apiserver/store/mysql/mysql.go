@@ -1,11 +1,14 @@
 package mysql
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	v1 "github.com/rose839/IAM/api/apiserver/v1"
 	"github.com/rose839/IAM/internal/apiserver/store"
+	"github.com/rose839/IAM/internal/pkg/metrics"
 	genericoptions "github.com/rose839/IAM/internal/pkg/options"
 	"github.com/rose839/IAM/pkg/db"
 	"github.com/rose839/IAM/pkg/errors"
@@ -32,6 +35,50 @@ func (ds *dataStore) Policies() store.PolicyStore {
 	return newPolicies(ds)
 }
 
+func (ds *dataStore) PasswordHistories() store.PasswordHistoryStore {
+	return newPasswordHistories(ds)
+}
+
+func (ds *dataStore) Groups() store.GroupStore {
+	return newGroups(ds)
+}
+
+func (ds *dataStore) ServiceAccounts() store.ServiceAccountStore {
+	return newServiceAccounts(ds)
+}
+
+func (ds *dataStore) AuditEvents() store.AuditEventStore {
+	return newAuditEvents(ds)
+}
+
+func (ds *dataStore) Webhooks() store.WebhookStore {
+	return newWebhooks(ds)
+}
+
+func (ds *dataStore) WebhookDeliveries() store.WebhookDeliveryStore {
+	return newWebhookDeliveries(ds)
+}
+
+func (ds *dataStore) Snapshots() store.SnapshotStore {
+	return newSnapshots(ds)
+}
+
+func (ds *dataStore) Transaction(ctx context.Context, fn func(factory store.Factory) error) error {
+	return ds.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&dataStore{db: tx})
+	})
+}
+
+// Ping verifies the connection to the database is still alive.
+func (ds *dataStore) Ping(ctx context.Context) error {
+	db, err := ds.db.DB()
+	if err != nil {
+		return errors.Wrap(err, "get gorm db instance failed")
+	}
+
+	return db.PingContext(ctx)
+}
+
 func (ds *dataStore) Close() error {
 	db, err := ds.db.DB()
 	if err != nil {
@@ -68,9 +115,16 @@ func GetMySQLFactoryOr(opts *genericoptions.MySQLOptions) (store.Factory, error)
 
 		dbIns, err = db.New(options)
 
+		// export the connection pool stats with the iam metrics
+		if err == nil {
+			if sqlDB, err := dbIns.DB(); err == nil {
+				metrics.Registry.MustRegister(collectors.NewDBStatsCollector(sqlDB, opts.Database))
+			}
+		}
+
 		// uncomment the following line if you need auto migration the given models
 		// not suggested in production environment.
-		// migrateDatabase(dbIns)
+		// MigrateDatabase(dbIns)
 
 		mysqlFactory = &dataStore{dbIns}
 	})
@@ -93,22 +147,66 @@ func cleanDatabase(db *gorm.DB) error {
 	if err := db.Migrator().DropTable(&v1.Secret{}); err != nil {
 		return errors.Wrap(err, "drop secret table failed")
 	}
+	if err := db.Migrator().DropTable(&v1.PasswordHistory{}); err != nil {
+		return errors.Wrap(err, "drop password history table failed")
+	}
+	if err := db.Migrator().DropTable(&v1.GroupMember{}); err != nil {
+		return errors.Wrap(err, "drop group member table failed")
+	}
+	if err := db.Migrator().DropTable(&v1.Group{}); err != nil {
+		return errors.Wrap(err, "drop group table failed")
+	}
+	if err := db.Migrator().DropTable(&v1.AuditEvent{}); err != nil {
+		return errors.Wrap(err, "drop audit event table failed")
+	}
+	if err := db.Migrator().DropTable(&v1.WebhookDelivery{}); err != nil {
+		return errors.Wrap(err, "drop webhook delivery table failed")
+	}
+	if err := db.Migrator().DropTable(&v1.Webhook{}); err != nil {
+		return errors.Wrap(err, "drop webhook table failed")
+	}
 
 	return nil
 }
 
-// migrateDatabase run auto migration for given models, will only add missing fields,
+// NewFactory create a store factory using the given database connection.
+func NewFactory(db *gorm.DB) store.Factory {
+	return &dataStore{db: db}
+}
+
+// MigrateDatabase run auto migration for given models, will only add missing fields,
 // won't delete/change current data.
-func migrateDatabase(db *gorm.DB) error {
+func MigrateDatabase(db *gorm.DB) error {
 	if err := db.AutoMigrate(&v1.User{}); err != nil {
 		return errors.Wrap(err, "migrate user model failed")
 	}
+	if err := db.AutoMigrate(&v1.ServiceAccount{}); err != nil {
+		return errors.Wrap(err, "migrate service account model failed")
+	}
 	if err := db.AutoMigrate(&v1.Policy{}); err != nil {
 		return errors.Wrap(err, "migrate policy model failed")
 	}
 	if err := db.AutoMigrate(&v1.Secret{}); err != nil {
 		return errors.Wrap(err, "migrate secret model failed")
 	}
+	if err := db.AutoMigrate(&v1.PasswordHistory{}); err != nil {
+		return errors.Wrap(err, "migrate password history model failed")
+	}
+	if err := db.AutoMigrate(&v1.Group{}); err != nil {
+		return errors.Wrap(err, "migrate group model failed")
+	}
+	if err := db.AutoMigrate(&v1.GroupMember{}); err != nil {
+		return errors.Wrap(err, "migrate group member model failed")
+	}
+	if err := db.AutoMigrate(&v1.AuditEvent{}); err != nil {
+		return errors.Wrap(err, "migrate audit event model failed")
+	}
+	if err := db.AutoMigrate(&v1.Webhook{}); err != nil {
+		return errors.Wrap(err, "migrate webhook model failed")
+	}
+	if err := db.AutoMigrate(&v1.WebhookDelivery{}); err != nil {
+		return errors.Wrap(err, "migrate webhook delivery model failed")
+	}
 
 	return nil
 }
@@ -118,7 +216,7 @@ func resetDatabase(db *gorm.DB) error {
 	if err := cleanDatabase(db); err != nil {
 		return err
 	}
-	if err := migrateDatabase(db); err != nil {
+	if err := MigrateDatabase(db); err != nil {
 		return err
 	}
 
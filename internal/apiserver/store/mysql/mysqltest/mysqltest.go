@@ -0,0 +1,41 @@
+// Package mysqltest provides a store factory backed by an in-memory sqlite database,
+// with the tables of the mysql store migrated, for tests of the services and controllers.
+package mysqltest
+
+import (
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/rose839/IAM/internal/apiserver/store"
+	"github.com/rose839/IAM/internal/apiserver/store/mysql"
+)
+
+// NewFactory returns a store factory on a new empty database, closed when the test ends.
+func NewFactory(t testing.TB) store.Factory {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+
+	// every connection opens a database of its own, the pool must hold a single one.
+	// Queries outside of a running transaction block, like they would wait for its locks.
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("get database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	if err := mysql.MigrateDatabase(db); err != nil {
+		t.Fatalf("migrate database: %v", err)
+	}
+
+	factory := mysql.NewFactory(db)
+	t.Cleanup(func() { _ = factory.Close() })
+
+	return factory
+}
@@ -0,0 +1,48 @@
+package mysql
+
+import (
+	"context"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	"gorm.io/gorm"
+)
+
+type passwordHistories struct {
+	db *gorm.DB
+}
+
+func newPasswordHistories(ds *dataStore) *passwordHistories {
+	return &passwordHistories{db: ds.db}
+}
+
+// Create records a password hash of an user.
+func (p *passwordHistories) Create(ctx context.Context, history *v1.PasswordHistory) error {
+	return p.db.WithContext(ctx).Create(history).Error
+}
+
+// List return the latest limit password hashes of an user, newest first.
+func (p *passwordHistories) List(ctx context.Context, username string, limit int) ([]*v1.PasswordHistory, error) {
+	ret := make([]*v1.PasswordHistory, 0)
+	d := p.db.WithContext(ctx).Where("username = ?", username).
+		Order("id desc").
+		Limit(limit).
+		Find(&ret)
+
+	return ret, d.Error
+}
+
+// Prune deletes all but the latest keep password hashes of an user.
+func (p *passwordHistories) Prune(ctx context.Context, username string, keep int) error {
+	var ids []uint64
+	err := p.db.WithContext(ctx).Model(&v1.PasswordHistory{}).
+		Where("username = ?", username).
+		Order("id desc").
+		Offset(keep).
+		Limit(1).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	return p.db.WithContext(ctx).Where("username = ? and id <= ?", username, ids[0]).Delete(&v1.PasswordHistory{}).Error
+}
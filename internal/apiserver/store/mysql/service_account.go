@@ -0,0 +1,128 @@
+package mysql
+
+import (
+	"context"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/db"
+	"github.com/rose839/IAM/pkg/errors"
+	"github.com/rose839/IAM/pkg/fields"
+	"gorm.io/gorm"
+)
+
+type serviceAccounts struct {
+	db *gorm.DB
+}
+
+func newServiceAccounts(ds *dataStore) *serviceAccounts {
+	return &serviceAccounts{db: ds.db}
+}
+
+// scope restricts queries on the user table to service accounts.
+func (s *serviceAccounts) scope(ctx context.Context) *gorm.DB {
+	return s.db.WithContext(ctx).Where("type = ?", v1.UserTypeServiceAccount)
+}
+
+// Create creates a new service account.
+func (s *serviceAccounts) Create(ctx context.Context, sa *v1.ServiceAccount, opts metav1.CreateOptions) error {
+	return s.db.WithContext(ctx).Create(sa).Error
+}
+
+// Update updates a service account information.
+func (s *serviceAccounts) Update(ctx context.Context, sa *v1.ServiceAccount, opts metav1.UpdateOptions) error {
+	return s.db.WithContext(ctx).Save(sa).Error
+}
+
+// Delete deletes the service account by the service account identifier, together
+// with the secrets and policies it owns.
+func (s *serviceAccounts) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if opts.Unscoped {
+			// a new session, so that the conditions of the statements do not add up.
+			tx = tx.Unscoped().Session(&gorm.Session{})
+		}
+
+		if err := tx.Where("username = ?", name).Delete(&v1.Secret{}).Error; err != nil {
+			return errors.WithCode(code.ErrDatabase, err.Error())
+		}
+
+		if err := tx.Where("username = ?", name).Delete(&v1.Policy{}).Error; err != nil {
+			return errors.WithCode(code.ErrDatabase, err.Error())
+		}
+
+		err := tx.Where("type = ? and name = ?", v1.UserTypeServiceAccount, name).Delete(&v1.ServiceAccount{}).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.WithCode(code.ErrDatabase, err.Error())
+		}
+
+		return nil
+	})
+}
+
+// Get return a service account by the service account identifier.
+func (s *serviceAccounts) Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.ServiceAccount, error) {
+	sa := &v1.ServiceAccount{}
+	err := s.scope(ctx).Where("name = ?", name).First(sa).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.WithCode(code.ErrServiceAccountNotFound, err.Error())
+		}
+
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return sa, nil
+}
+
+// List return the service accounts owned by any of owners, or all service accounts if owners is nil.
+func (s *serviceAccounts) List(
+	ctx context.Context,
+	owners []string,
+	opts metav1.ListOptions,
+) (*v1.ServiceAccountList, error) {
+	ret := &v1.ServiceAccountList{}
+	ol := db.Unpointer(opts.Offset, opts.Limit)
+
+	d := s.scope(ctx)
+	if owners != nil {
+		d = d.Where("owner in (?)", owners)
+	}
+
+	selector, _ := fields.ParseSelector(opts.FieldSelector)
+	name, _ := selector.RequiresExactMatch("name")
+	if owner, found := selector.RequiresExactMatch("owner"); found {
+		d = d.Where("owner = ?", owner)
+	}
+
+	d = d.Where("name like ?", "%"+name+"%").
+		Offset(ol.Offset).
+		Limit(ol.Limit).
+		Order("id desc").
+		Find(&ret.Items).
+		Offset(-1).
+		Limit(-1).
+		Count(&ret.TotalCount)
+
+	return ret, d.Error
+}
+
+// ListByNames return the service accounts among names, keyed by name. Names of people are skipped.
+func (s *serviceAccounts) ListByNames(ctx context.Context, names []string) (map[string]*v1.ServiceAccount, error) {
+	ret := make(map[string]*v1.ServiceAccount)
+	if len(names) == 0 {
+		return ret, nil
+	}
+
+	var sas []*v1.ServiceAccount
+	if err := s.scope(ctx).Where("name in (?)", names).Find(&sas).Error; err != nil {
+		return nil, err
+	}
+
+	for _, sa := range sas {
+		ret[sa.Name] = sa
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,104 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	"github.com/rose839/IAM/internal/apiserver/store"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type snapshots struct {
+	db *gorm.DB
+}
+
+func newSnapshots(ds *dataStore) *snapshots {
+	return &snapshots{db: ds.db}
+}
+
+// Export reads all users, secrets and policies in one read only repeatable read
+// transaction, so they are consistent with each other.
+func (s *snapshots) Export(ctx context.Context) (*v1.Snapshot, error) {
+	snapshot := &v1.Snapshot{}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Order("id").Find(&snapshot.Users).Error; err != nil {
+			return err
+		}
+		if err := tx.Order("id").Find(&snapshot.Secrets).Error; err != nil {
+			return err
+		}
+
+		return tx.Order("id").Find(&snapshot.Policies).Error
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return snapshot, nil
+}
+
+// Apply deletes, creates and updates the objects of the plan in one transaction.
+// Dependent secrets and policies are deleted before and created after their users.
+func (s *snapshots) Apply(ctx context.Context, plan *store.SnapshotPlan) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, pol := range plan.Delete.Policies {
+			if err := tx.Delete(pol).Error; err != nil {
+				return err
+			}
+		}
+		for _, secret := range plan.Delete.Secrets {
+			if err := tx.Delete(secret).Error; err != nil {
+				return err
+			}
+		}
+		for _, user := range plan.Delete.Users {
+			if err := tx.Delete(user).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, user := range plan.Create.Users {
+			if err := tx.Create(user).Error; err != nil {
+				return err
+			}
+		}
+		for _, user := range plan.Update.Users {
+			if err := tx.Select("*").Omit("id", "instanceID").Updates(user).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, secret := range plan.Create.Secrets {
+			if err := tx.Create(secret).Error; err != nil {
+				return err
+			}
+		}
+		for _, secret := range plan.Update.Secrets {
+			if err := tx.Select("*").Omit("id", "instanceID").Updates(secret).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, pol := range plan.Create.Policies {
+			if err := tx.Create(pol).Error; err != nil {
+				return err
+			}
+		}
+		for _, pol := range plan.Update.Policies {
+			if err := tx.Select("*").Omit("id", "instanceID").Updates(pol).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return nil
+}
@@ -22,53 +22,99 @@ func newUsers(ds *dataStore) *users {
 
 // Create creates a new user account.
 func (u *users) Create(ctx context.Context, user *v1.User, opts metav1.CreateOptions) error {
-	return u.db.Create(&user).Error
+	return u.db.WithContext(ctx).Create(&user).Error
 }
 
 // Update updates an user account information.
 func (u *users) Update(ctx context.Context, user *v1.User, opts metav1.UpdateOptions) error {
-	return u.db.Save(user).Error
+	return u.db.WithContext(ctx).Save(user).Error
 }
 
-// Delete deletes the user by the user identifier.
+// Delete deletes the user by the user identifier, together with the secrets and
+// policies it owns and its service accounts.
 func (u *users) Delete(ctx context.Context, username string, opts metav1.DeleteOptions) error {
-	// delete related policy first
-	pol := newPolicies(&dataStore{u.db})
-	if err := pol.DeleteByUser(ctx, username, opts); err != nil {
-		return err
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if opts.Unscoped {
+			// a new session, so that the conditions of the statements do not add up.
+			tx = tx.Unscoped().Session(&gorm.Session{})
+		}
+
+		if err := deleteOwned(tx, []string{username}); err != nil {
+			return err
+		}
+
+		err := tx.Where("name = ?", username).Delete(&v1.User{}).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.WithCode(code.ErrDatabase, err.Error())
+		}
+
+		return nil
+	})
+}
+
+// DeleteCollection batch deletes the users, together with the secrets and policies
+// they own and their service accounts.
+func (u *users) DeleteCollection(ctx context.Context, usernames []string, opts metav1.DeleteOptions) error {
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if opts.Unscoped {
+			// a new session, so that the conditions of the statements do not add up.
+			tx = tx.Unscoped().Session(&gorm.Session{})
+		}
+
+		if err := deleteOwned(tx, usernames); err != nil {
+			return err
+		}
+
+		if err := tx.Where("name in (?)", usernames).Delete(&v1.User{}).Error; err != nil {
+			return errors.WithCode(code.ErrDatabase, err.Error())
+		}
+
+		return nil
+	})
+}
+
+// deleteOwned deletes the service accounts owned by the users, and the secrets and
+// policies of both the users and their service accounts, so that none of them is left
+// without owner.
+func deleteOwned(tx *gorm.DB, usernames []string) error {
+	owners := make([]string, 0, len(usernames))
+	for _, username := range usernames {
+		owners = append(owners, v1.UserOwnerPrefix+username)
 	}
 
-	if opts.Unscoped {
-		u.db = u.db.Unscoped()
+	var accounts []string
+	err := tx.Model(&v1.ServiceAccount{}).
+		Where("type = ? and owner in (?)", v1.UserTypeServiceAccount, owners).
+		Pluck("name", &accounts).Error
+	if err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
 	}
 
-	err := u.db.Where("name = ?", username).Delete(&v1.User{}).Error
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+	subjects := append(append([]string{}, usernames...), accounts...)
+	if err := tx.Where("username in (?)", subjects).Delete(&v1.Secret{}).Error; err != nil {
 		return errors.WithCode(code.ErrDatabase, err.Error())
 	}
 
-	return nil
-}
+	if err := tx.Where("username in (?)", subjects).Delete(&v1.Policy{}).Error; err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
 
-// DeleteCollection batch deletes the users.
-func (u *users) DeleteCollection(ctx context.Context, usernames []string, opts metav1.DeleteOptions) error {
-	// delete related policy first
-	pol := newPolicies(&dataStore{u.db})
-	if err := pol.DeleteCollectionByUser(ctx, usernames, opts); err != nil {
-		return err
+	if len(accounts) == 0 {
+		return nil
 	}
 
-	if opts.Unscoped {
-		u.db = u.db.Unscoped()
+	err = tx.Where("type = ? and name in (?)", v1.UserTypeServiceAccount, accounts).Delete(&v1.ServiceAccount{}).Error
+	if err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
 	}
 
-	return u.db.Where("name in (?)", usernames).Delete(&v1.User{}).Error
+	return nil
 }
 
 // Get return an user by the user identifier.
 func (u *users) Get(ctx context.Context, username string, opts metav1.GetOptions) (*v1.User, error) {
 	user := &v1.User{}
-	err := u.db.Where("name = ?", username).First(user).Error
+	err := u.db.WithContext(ctx).Where("name = ?", username).First(user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.WithCode(code.ErrUserNotFound, err.Error())
@@ -87,8 +133,12 @@ func (u *users) List(ctx context.Context, opts metav1.ListOptions) (*v1.UserList
 
 	selector, _ := fields.ParseSelector(opts.FieldSelector)
 	username, _ := selector.RequiresExactMatch("name")
-	d := u.db.Where("name like ?", "%"+username+"%").
-		Offset(ol.Offset).
+	d := u.db.WithContext(ctx).Where("name like ?", "%"+username+"%")
+	if userType, found := selector.RequiresExactMatch("type"); found {
+		d = d.Where("type = ?", userType)
+	}
+
+	d = d.Offset(ol.Offset).
 		Limit(ol.Limit).
 		Order("id desc").
 		Find(&ret.Items).
@@ -114,7 +164,7 @@ func (u *users) ListOptional(ctx context.Context, opts metav1.ListOptions) (*v1.
 		where.Name = username
 	}
 
-	d := u.db.Where(where).
+	d := u.db.WithContext(ctx).Where(where).
 		Not(whereNot).
 		Offset(ol.Offset).
 		Limit(ol.Limit).
@@ -0,0 +1,137 @@
+package mysql
+
+import (
+	"context"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/db"
+	"github.com/rose839/IAM/pkg/errors"
+	"github.com/rose839/IAM/pkg/fields"
+	"gorm.io/gorm"
+)
+
+type webhooks struct {
+	db *gorm.DB
+}
+
+func newWebhooks(ds *dataStore) *webhooks {
+	return &webhooks{db: ds.db}
+}
+
+// Create creates a new webhook.
+func (w *webhooks) Create(ctx context.Context, webhook *v1.Webhook, opts metav1.CreateOptions) error {
+	return w.db.WithContext(ctx).Create(webhook).Error
+}
+
+// Update updates a webhook information.
+func (w *webhooks) Update(ctx context.Context, webhook *v1.Webhook, opts metav1.UpdateOptions) error {
+	return w.db.WithContext(ctx).Save(webhook).Error
+}
+
+// Delete deletes the webhook by the webhook identifier, deliveries are deleted by the database.
+func (w *webhooks) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	d := w.db.WithContext(ctx)
+	if opts.Unscoped {
+		d = d.Unscoped()
+	}
+
+	err := d.Where("name = ?", name).Delete(&v1.Webhook{}).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return nil
+}
+
+// Get return a webhook by the webhook identifier.
+func (w *webhooks) Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Webhook, error) {
+	webhook := &v1.Webhook{}
+	err := w.db.WithContext(ctx).Where("name = ?", name).First(webhook).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.WithCode(code.ErrWebhookNotFound, err.Error())
+		}
+
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return webhook, nil
+}
+
+// List return all webhooks.
+func (w *webhooks) List(ctx context.Context, opts metav1.ListOptions) (*v1.WebhookList, error) {
+	ret := &v1.WebhookList{}
+	ol := db.Unpointer(opts.Offset, opts.Limit)
+
+	selector, _ := fields.ParseSelector(opts.FieldSelector)
+	name, _ := selector.RequiresExactMatch("name")
+	d := w.db.WithContext(ctx).Where("name like ?", "%"+name+"%").
+		Offset(ol.Offset).
+		Limit(ol.Limit).
+		Order("id desc").
+		Find(&ret.Items).
+		Offset(-1).
+		Limit(-1).
+		Count(&ret.TotalCount)
+
+	return ret, d.Error
+}
+
+type webhookDeliveries struct {
+	db *gorm.DB
+}
+
+func newWebhookDeliveries(ds *dataStore) *webhookDeliveries {
+	return &webhookDeliveries{db: ds.db}
+}
+
+// Create creates a new webhook delivery.
+func (w *webhookDeliveries) Create(ctx context.Context, delivery *v1.WebhookDelivery) error {
+	return w.db.WithContext(ctx).Create(delivery).Error
+}
+
+// Update saves the outcome of a delivery attempt.
+func (w *webhookDeliveries) Update(ctx context.Context, delivery *v1.WebhookDelivery) error {
+	return w.db.WithContext(ctx).Save(delivery).Error
+}
+
+// List return the deliveries of a webhook, newest first. The field selector filters
+// by status and event.
+func (w *webhookDeliveries) List(
+	ctx context.Context,
+	webhook string,
+	opts metav1.ListOptions,
+) (*v1.WebhookDeliveryList, error) {
+	ret := &v1.WebhookDeliveryList{}
+	ol := db.Unpointer(opts.Offset, opts.Limit)
+
+	d := w.db.WithContext(ctx).Where("webhook = ?", webhook)
+
+	selector, _ := fields.ParseSelector(opts.FieldSelector)
+	if status, ok := selector.RequiresExactMatch("status"); ok {
+		d = d.Where("status = ?", status)
+	}
+	if event, ok := selector.RequiresExactMatch("event"); ok {
+		d = d.Where("event = ?", event)
+	}
+
+	d = d.Offset(ol.Offset).
+		Limit(ol.Limit).
+		Order("id desc").
+		Find(&ret.Items).
+		Offset(-1).
+		Limit(-1).
+		Count(&ret.TotalCount)
+
+	return ret, d.Error
+}
+
+// ListPending return the pending deliveries of all webhooks, oldest first.
+func (w *webhookDeliveries) ListPending(ctx context.Context) ([]*v1.WebhookDelivery, error) {
+	ret := make([]*v1.WebhookDelivery, 0)
+	err := w.db.WithContext(ctx).Where("status = ?", v1.WebhookDeliveryPending).Order("id").Find(&ret).Error
+
+	return ret, err
+}
@@ -0,0 +1,14 @@
+package store
+
+import (
+	"context"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+)
+
+// PasswordHistoryStore defines the password history storage interface.
+type PasswordHistoryStore interface {
+	Create(ctx context.Context, history *v1.PasswordHistory) error
+	List(ctx context.Context, username string, limit int) ([]*v1.PasswordHistory, error)
+	Prune(ctx context.Context, username string, keep int) error
+}
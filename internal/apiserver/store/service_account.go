@@ -0,0 +1,18 @@
+package store
+
+import (
+	"context"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+)
+
+// ServiceAccountStore defines the service account storage interface.
+type ServiceAccountStore interface {
+	Create(ctx context.Context, sa *v1.ServiceAccount, opts metav1.CreateOptions) error
+	Update(ctx context.Context, sa *v1.ServiceAccount, opts metav1.UpdateOptions) error
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.ServiceAccount, error)
+	List(ctx context.Context, owners []string, opts metav1.ListOptions) (*v1.ServiceAccountList, error)
+	ListByNames(ctx context.Context, names []string) (map[string]*v1.ServiceAccount, error)
+}
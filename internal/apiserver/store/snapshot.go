@@ -0,0 +1,23 @@
+package store
+
+import (
+	"context"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+)
+
+// SnapshotPlan holds the objects a snapshot import creates, updates and deletes.
+type SnapshotPlan struct {
+	Create v1.Snapshot
+	Update v1.Snapshot
+	Delete v1.Snapshot
+}
+
+// SnapshotStore defines the snapshot storage interface.
+type SnapshotStore interface {
+	// Export reads all users, secrets and policies in one consistent read.
+	Export(ctx context.Context) (*v1.Snapshot, error)
+
+	// Apply applies a plan in one transaction, either all or none of its changes are made.
+	Apply(ctx context.Context, plan *SnapshotPlan) error
+}
@@ -1,5 +1,7 @@
 package store
 
+import "context"
+
 var client Factory
 
 // Factory defines the iam platform storage interface.
@@ -7,6 +9,17 @@ type Factory interface {
 	Users() UserStore
 	Secrets() SecretStore
 	Policies() PolicyStore
+	PasswordHistories() PasswordHistoryStore
+	Groups() GroupStore
+	ServiceAccounts() ServiceAccountStore
+	AuditEvents() AuditEventStore
+	Webhooks() WebhookStore
+	WebhookDeliveries() WebhookDeliveryStore
+	Snapshots() SnapshotStore
+	// Transaction runs fn with a factory whose stores share one database transaction,
+	// which is committed when fn returns nil and rolled back otherwise.
+	Transaction(ctx context.Context, fn func(factory Factory) error) error
+	Ping(ctx context.Context) error
 	Close() error
 }
 
@@ -0,0 +1,25 @@
+package store
+
+import (
+	"context"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+)
+
+// WebhookStore defines the webhook storage interface.
+type WebhookStore interface {
+	Create(ctx context.Context, webhook *v1.Webhook, opts metav1.CreateOptions) error
+	Update(ctx context.Context, webhook *v1.Webhook, opts metav1.UpdateOptions) error
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Webhook, error)
+	List(ctx context.Context, opts metav1.ListOptions) (*v1.WebhookList, error)
+}
+
+// WebhookDeliveryStore defines the webhook delivery storage interface.
+type WebhookDeliveryStore interface {
+	Create(ctx context.Context, delivery *v1.WebhookDelivery) error
+	Update(ctx context.Context, delivery *v1.WebhookDelivery) error
+	List(ctx context.Context, webhook string, opts metav1.ListOptions) (*v1.WebhookDeliveryList, error)
+	ListPending(ctx context.Context) ([]*v1.WebhookDelivery, error)
+}
@@ -0,0 +1,157 @@
+// Package verification issues single-use, time-limited tokens which prove that a
+// user owns an email address, and delivers them through a notifier. Tokens are kept
+// in redis hashed, so a leaked redis dump can not be used to take over accounts.
+package verification
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	"github.com/rose839/IAM/internal/pkg/code"
+	genericoptions "github.com/rose839/IAM/internal/pkg/options"
+	"github.com/rose839/IAM/pkg/errors"
+	"github.com/rose839/IAM/pkg/idutil"
+	"github.com/rose839/IAM/pkg/notifier"
+	"github.com/rose839/IAM/pkg/storage"
+)
+
+const keyPrefix = "iam-verification-"
+
+// Purpose defines what a token can be used for.
+type Purpose string
+
+const (
+	// PasswordReset tokens allow to set a new password without knowing the old one.
+	PasswordReset Purpose = "password-reset"
+
+	// EmailVerification tokens confirm the email address of an user.
+	EmailVerification Purpose = "email-verification"
+)
+
+// Claim is the information bound to a token.
+type Claim struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+// Verifier issues, checks and consumes tokens.
+type Verifier struct {
+	opts     *genericoptions.VerificationOptions
+	notifier notifier.Notifier
+	store    *storage.RedisCluster
+}
+
+var (
+	verifier *Verifier
+	once     sync.Once
+)
+
+// GetVerifierOr return verifier instance with given options and notifier.
+func GetVerifierOr(opts *genericoptions.VerificationOptions, n notifier.Notifier) (*Verifier, error) {
+	if opts != nil && n != nil {
+		once.Do(func() {
+			verifier = &Verifier{
+				opts:     opts,
+				notifier: n,
+				store:    &storage.RedisCluster{KeyPrefix: keyPrefix},
+			}
+		})
+	}
+
+	if verifier == nil {
+		return nil, fmt.Errorf("got nil verifier")
+	}
+
+	return verifier, nil
+}
+
+// Send issues a token for purpose and delivers it to the email address of user.
+func (v *Verifier) Send(ctx context.Context, purpose Purpose, user *v1.User) error {
+	token := idutil.NewSecretKey()
+	claim, _ := json.Marshal(&Claim{Username: user.Name, Email: user.Email})
+	ttl := v.ttl(purpose)
+
+	if err := v.store.WithContext(ctx).SetKey(key(purpose, token), string(claim), ttl); err != nil {
+		return errors.WithCode(code.ErrUnknown, "store %s token failed: %s", purpose, err.Error())
+	}
+
+	if err := v.notifier.Notify(ctx, message(purpose, user, token, ttl)); err != nil {
+		v.store.DeleteKey(key(purpose, token))
+
+		return errors.WithCode(code.ErrUnknown, "send %s token failed: %s", purpose, err.Error())
+	}
+
+	return nil
+}
+
+// Peek returns the claim of token without consuming it.
+func (v *Verifier) Peek(purpose Purpose, token string) (*Claim, error) {
+	value, err := v.store.GetKey(key(purpose, token))
+	if err != nil {
+		return nil, errors.WithCode(code.ErrVerificationTokenInvalid, "Token not found.")
+	}
+
+	claim := &Claim{}
+	if err := json.Unmarshal([]byte(value), claim); err != nil {
+		return nil, errors.WithCode(code.ErrVerificationTokenInvalid, err.Error())
+	}
+
+	return claim, nil
+}
+
+// Consume returns the claim of token and invalidates it. Only one of concurrent
+// callers consuming the same token succeeds.
+func (v *Verifier) Consume(purpose Purpose, token string) (*Claim, error) {
+	claim, err := v.Peek(purpose, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if !v.store.DeleteKey(key(purpose, token)) {
+		return nil, errors.WithCode(code.ErrVerificationTokenInvalid, "Token already used.")
+	}
+
+	return claim, nil
+}
+
+func (v *Verifier) ttl(purpose Purpose) time.Duration {
+	if purpose == PasswordReset {
+		return v.opts.PasswordResetTTL
+	}
+
+	return v.opts.EmailTTL
+}
+
+func key(purpose Purpose, token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return string(purpose) + "-" + hex.EncodeToString(sum[:])
+}
+
+func message(purpose Purpose, user *v1.User, token string, ttl time.Duration) *notifier.Message {
+	msg := &notifier.Message{To: []string{user.Email}, Secrets: []string{token}}
+
+	switch purpose {
+	case PasswordReset:
+		msg.Subject = "Reset your IAM password"
+		msg.Body = fmt.Sprintf("Hi %s,\n\n"+
+			"Somebody requested to reset the password of IAM user `%s`. To choose a new password, "+
+			"send the following token to /v1/password-reset/confirm within %s:\n\n%s\n\n"+
+			"If you did not request a password reset, you can ignore this email.\n",
+			user.Nickname, user.Name, ttl, token)
+	case EmailVerification:
+		msg.Subject = "Verify your IAM email address"
+		msg.Body = fmt.Sprintf("Hi %s,\n\n"+
+			"To verify %s as the email address of IAM user `%s`, "+
+			"send the following token to /v1/email-verification/confirm within %s:\n\n%s\n",
+			user.Nickname, user.Email, user.Name, ttl, token)
+	}
+
+	return msg
+}
@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+)
+
+// Headers of a webhook delivery.
+const (
+	// EventHeader carries the event type, e.g. policies.update.
+	EventHeader = "X-IAM-Event"
+
+	// DeliveryHeader carries the delivery identifier, retries of a delivery keep it.
+	DeliveryHeader = "X-IAM-Delivery"
+
+	// TimestampHeader carries the unix time the delivery attempt was signed at.
+	TimestampHeader = "X-IAM-Timestamp"
+
+	// SignatureHeader carries the signature of the payload, see Sign.
+	SignatureHeader = "X-IAM-Signature"
+)
+
+// signaturePrefix prefixes the hex encoded HMAC-SHA256 signature.
+const signaturePrefix = "sha256="
+
+// maxResponseSize is the size of the response body drained to reuse connections.
+const maxResponseSize = 64 << 10
+
+// Sign returns the signature of a payload, the HMAC-SHA256 of `<timestamp>.<payload>`
+// keyed with the webhook secret. Receivers should check the timestamp is recent to
+// reject replayed deliveries.
+func Sign(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of the payload signed at timestamp.
+func Verify(secret, timestamp string, payload []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, payload)), []byte(signature))
+}
+
+// send posts the payload of the delivery to the webhook, responses other than 2xx are failures.
+func (d *Dispatcher) send(ctx context.Context, webhook *v1.Webhook, delivery *v1.WebhookDelivery) (int, error) {
+	payload := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "iam-apiserver-webhook")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(delivery.ID, 10))
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, timestamp, payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseSize))
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
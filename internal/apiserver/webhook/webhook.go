@@ -0,0 +1,326 @@
+// Package webhook delivers resource change events to the registered webhooks. Events
+// are published by the audit middleware, whether auditing is enabled or not, every
+// matching webhook gets a delivery which is retried with exponential backoff and kept
+// in the delivery log. Deliveries still pending when the server stops are resumed
+// when it starts again.
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlekSi/pointer"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/apiserver/store"
+	"github.com/rose839/IAM/internal/pkg/code"
+	genericoptions "github.com/rose839/IAM/internal/pkg/options"
+	"github.com/rose839/IAM/pkg/errors"
+	"github.com/rose839/IAM/pkg/log"
+)
+
+// maxErrorLength is the size of the error column of the delivery log.
+const maxErrorLength = 1024
+
+// task is a delivery of an event to a webhook.
+type task struct {
+	webhook  *v1.Webhook
+	delivery *v1.WebhookDelivery
+}
+
+// Dispatcher turns the events of successful requests into webhook deliveries.
+type Dispatcher struct {
+	opts     *genericoptions.WebhookOptions
+	client   *http.Client
+	events   chan *v1.AuditEvent
+	tasks    chan *task
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+var (
+	dispatcher *Dispatcher
+	once       sync.Once
+)
+
+// GetDispatcherOr return dispatcher instance with given options.
+func GetDispatcherOr(opts *genericoptions.WebhookOptions) (*Dispatcher, error) {
+	if opts != nil {
+		once.Do(func() {
+			dispatcher = newDispatcher(opts)
+		})
+	}
+
+	if dispatcher == nil {
+		return nil, fmt.Errorf("got nil webhook dispatcher")
+	}
+
+	return dispatcher, nil
+}
+
+func newDispatcher(opts *genericoptions.WebhookOptions) *Dispatcher {
+	return &Dispatcher{
+		opts:   opts,
+		client: &http.Client{Timeout: opts.Timeout},
+		events: make(chan *v1.AuditEvent, opts.QueueSize),
+		tasks:  make(chan *task, opts.QueueSize),
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Start starts dispatching the queued events and the delivery workers, and resumes
+// the deliveries left pending by the previous run.
+func (d *Dispatcher) Start() {
+	if !d.opts.Enabled {
+		return
+	}
+
+	// listed before dispatching, so that none of the new deliveries is resumed.
+	pending, err := store.Client().WebhookDeliveries().ListPending(context.Background())
+	if err != nil {
+		log.Errorf("List pending webhook deliveries failed: %s", err.Error())
+	}
+
+	d.wg.Add(1)
+	go d.dispatchLoop()
+
+	for i := 0; i < d.opts.Workers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+
+	if len(pending) != 0 {
+		d.wg.Add(1)
+		go d.resume(pending)
+	}
+}
+
+// Write queues the event of a successful request, so the dispatcher can subscribe to
+// the events of the auditor.
+func (d *Dispatcher) Write(event *v1.AuditEvent) error {
+	if !d.opts.Enabled || event.HTTPStatus != http.StatusOK {
+		return nil
+	}
+
+	select {
+	case d.events <- event:
+		return nil
+	default:
+		return fmt.Errorf("webhook event queue is full, event %s dropped", EventName(event))
+	}
+}
+
+// Close stops the dispatcher and waits for the running deliveries, deliveries
+// waiting for a retry stay pending in the delivery log.
+func (d *Dispatcher) Close() error {
+	d.stopOnce.Do(func() {
+		close(d.stopCh)
+	})
+	d.wg.Wait()
+
+	return nil
+}
+
+// EventName returns the webhook event type of an audit event, `<kind>.<verb>` or
+// `<kind>.<subresource>.<verb>`, e.g. policies.update or users.change-password.update.
+func EventName(event *v1.AuditEvent) string {
+	parts := []string{event.Kind}
+	if event.Subresource != "" {
+		parts = append(parts, event.Subresource)
+	}
+
+	return strings.Join(append(parts, event.Verb), ".")
+}
+
+// Matches reports whether the event matches one of the shell patterns of a webhook.
+func Matches(patterns []string, event string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, event); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (d *Dispatcher) dispatchLoop() {
+	defer d.wg.Done()
+
+	for {
+		select {
+		case event := <-d.events:
+			d.dispatch(event)
+		case <-d.stopCh:
+			return
+		}
+	}
+}
+
+// dispatch records a delivery of the event for every subscribed webhook and queues it.
+func (d *Dispatcher) dispatch(event *v1.AuditEvent) {
+	ctx := requestContext(event.RequestID)
+	name := EventName(event)
+
+	webhooks, err := store.Client().Webhooks().List(ctx, metav1.ListOptions{Limit: pointer.ToInt64(-1)})
+	if err != nil {
+		log.L(ctx).Errorf("List webhooks for event %s of request `%s` failed: %s", name, event.RequestID, err.Error())
+
+		return
+	}
+
+	var payload []byte
+	for _, webhook := range webhooks.Items {
+		if !Matches(webhook.Events, name) {
+			continue
+		}
+
+		if payload == nil {
+			if payload, err = json.Marshal(&v1.WebhookPayload{Event: name, Data: event}); err != nil {
+				log.L(ctx).Errorf("Marshal webhook payload of request `%s` failed: %s", event.RequestID, err.Error())
+
+				return
+			}
+		}
+
+		delivery := &v1.WebhookDelivery{
+			Webhook:   webhook.Name,
+			Event:     name,
+			RequestID: event.RequestID,
+			Payload:   string(payload),
+			Status:    v1.WebhookDeliveryPending,
+		}
+		if err := store.Client().WebhookDeliveries().Create(ctx, delivery); err != nil {
+			log.L(ctx).Errorf("Create delivery of event %s to webhook %s failed: %s", name, webhook.Name, err.Error())
+
+			continue
+		}
+
+		select {
+		case d.tasks <- &task{webhook: webhook, delivery: delivery}:
+		default:
+			delivery.Status = v1.WebhookDeliveryFailed
+			delivery.Error = "webhook delivery queue is full"
+			d.save(delivery)
+		}
+	}
+}
+
+// resume queues the pending deliveries, the ones of deleted webhooks fail.
+func (d *Dispatcher) resume(deliveries []*v1.WebhookDelivery) {
+	defer d.wg.Done()
+
+	log.Infof("Resume %d pending webhook deliveries", len(deliveries))
+
+	webhooks := make(map[string]*v1.Webhook)
+	for _, delivery := range deliveries {
+		webhook, ok := webhooks[delivery.Webhook]
+		if !ok {
+			var err error
+			webhook, err = store.Client().Webhooks().Get(requestContext(delivery.RequestID), delivery.Webhook, metav1.GetOptions{})
+			if err != nil && !errors.IsCode(err, code.ErrWebhookNotFound) {
+				log.Errorf("Get webhook %s to resume delivery %d failed: %s", delivery.Webhook, delivery.ID, err.Error())
+
+				continue
+			}
+			webhooks[delivery.Webhook] = webhook
+		}
+
+		if webhook == nil {
+			delivery.Status = v1.WebhookDeliveryFailed
+			delivery.Error = "webhook deleted"
+			d.save(delivery)
+
+			continue
+		}
+
+		select {
+		case d.tasks <- &task{webhook: webhook, delivery: delivery}:
+		case <-d.stopCh:
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+
+	for {
+		select {
+		case t := <-d.tasks:
+			d.attempt(t)
+		case <-d.stopCh:
+			return
+		}
+	}
+}
+
+// attempt delivers the event once, failed attempts are retried with exponential
+// backoff until the maximum number of attempts is reached.
+func (d *Dispatcher) attempt(t *task) {
+	t.delivery.Attempts++
+	status, err := d.send(requestContext(t.delivery.RequestID), t.webhook, t.delivery)
+	t.delivery.ResponseStatus = status
+
+	switch {
+	case err == nil:
+		t.delivery.Status = v1.WebhookDeliverySucceeded
+		t.delivery.Error = ""
+	case t.delivery.Attempts >= d.opts.MaxAttempts:
+		t.delivery.Status = v1.WebhookDeliveryFailed
+		t.delivery.Error = truncate(err.Error())
+	default:
+		t.delivery.Error = truncate(err.Error())
+		time.AfterFunc(d.backoff(t.delivery.Attempts), func() {
+			select {
+			case d.tasks <- t:
+			case <-d.stopCh:
+			}
+		})
+	}
+
+	d.save(t.delivery)
+}
+
+func (d *Dispatcher) save(delivery *v1.WebhookDelivery) {
+	ctx := requestContext(delivery.RequestID)
+	if err := store.Client().WebhookDeliveries().Update(ctx, delivery); err != nil {
+		log.L(ctx).Errorf("Update delivery %d to webhook %s failed: %s", delivery.ID, delivery.Webhook, err.Error())
+	}
+}
+
+// backoff returns the delay before the retry following the given attempt, the
+// minimum backoff doubled for every previous retry and capped at the maximum backoff.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.opts.MinBackoff
+	for i := 1; i < attempts && delay < d.opts.MaxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > d.opts.MaxBackoff {
+		delay = d.opts.MaxBackoff
+	}
+
+	return delay
+}
+
+func truncate(s string) string {
+	if len(s) > maxErrorLength {
+		return s[:maxErrorLength]
+	}
+
+	return s
+}
+
+// requestContext returns a context carrying the id of the request which raised an event,
+// so that the logs of its deliveries can be correlated with the ones of the request.
+func requestContext(requestID string) context.Context {
+	return log.WithField(context.Background(), log.KeyRequestID, requestID)
+}
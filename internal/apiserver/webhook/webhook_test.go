@@ -0,0 +1,196 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/apiserver/store"
+	"github.com/rose839/IAM/internal/apiserver/store/mysql/mysqltest"
+	genericoptions "github.com/rose839/IAM/internal/pkg/options"
+)
+
+func TestSend(t *testing.T) {
+	const secret = "0123456789abcdef"
+
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "accepted", status: http.StatusOK},
+		{name: "no content", status: http.StatusNoContent},
+		{name: "server error", status: http.StatusInternalServerError, wantErr: true},
+		{name: "redirect", status: http.StatusFound, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var verified bool
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				verified = r.Header.Get(EventHeader) == "policies.update" &&
+					r.Header.Get(DeliveryHeader) == "7" &&
+					Verify(secret, r.Header.Get(TimestampHeader), body, r.Header.Get(SignatureHeader))
+				w.WriteHeader(tt.status)
+			}))
+			defer receiver.Close()
+
+			d := newDispatcher(genericoptions.NewWebhookOptions())
+			webhook := &v1.Webhook{URL: receiver.URL, Secret: secret}
+			delivery := &v1.WebhookDelivery{ID: 7, Event: "policies.update", Payload: `{"event":"policies.update"}`}
+
+			status, err := d.send(context.Background(), webhook, delivery)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if status != tt.status {
+				t.Errorf("send() status = %d, want %d", status, tt.status)
+			}
+			if !verified {
+				t.Errorf("receiver could not verify the delivery")
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	payload := []byte(`{"event":"users.create"}`)
+	signature := Sign("secret", "1652176800", payload)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		payload   []byte
+		want      bool
+	}{
+		{name: "valid", secret: "secret", timestamp: "1652176800", payload: payload, want: true},
+		{name: "wrong secret", secret: "other", timestamp: "1652176800", payload: payload},
+		{name: "replayed timestamp", secret: "secret", timestamp: "1652176801", payload: payload},
+		{name: "tampered payload", secret: "secret", timestamp: "1652176800", payload: []byte(`{}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, tt.timestamp, tt.payload, signature); got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		event    string
+		want     bool
+	}{
+		{patterns: []string{"*"}, event: "secrets.delete", want: true},
+		{patterns: []string{"policies.*"}, event: "policies.update", want: true},
+		{patterns: []string{"users.create", "users.delete"}, event: "users.delete", want: true},
+		{patterns: []string{"users.*"}, event: "users.change-password.update", want: true},
+		{patterns: []string{"users.*.update"}, event: "users.update"},
+		{patterns: []string{"policies.*"}, event: "secrets.create"},
+		{patterns: nil, event: "users.create"},
+	}
+
+	for _, tt := range tests {
+		if got := Matches(tt.patterns, tt.event); got != tt.want {
+			t.Errorf("Matches(%v, %s) = %v, want %v", tt.patterns, tt.event, got, tt.want)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	opts := genericoptions.NewWebhookOptions()
+	opts.MinBackoff = time.Second
+	opts.MaxBackoff = 10 * time.Second
+	d := newDispatcher(opts)
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: time.Second},
+		{attempts: 2, want: 2 * time.Second},
+		{attempts: 4, want: 8 * time.Second},
+		{attempts: 5, want: 10 * time.Second},
+		{attempts: 100, want: 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := d.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestDispatcher_ResumesPending(t *testing.T) {
+	factory := mysqltest.NewFactory(t)
+	store.SetClient(factory)
+
+	received := make(chan string, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Get(DeliveryHeader)
+	}))
+	defer receiver.Close()
+
+	ctx := context.Background()
+	webhook := &v1.Webhook{
+		ObjectMeta: metav1.ObjectMeta{Name: "audit"},
+		URL:        receiver.URL,
+		Events:     []string{"*"},
+		Secret:     "0123456789abcdef",
+	}
+	if err := factory.Webhooks().Create(ctx, webhook, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+
+	// left pending by the previous run, the second webhook has been deleted since.
+	deliveries := []*v1.WebhookDelivery{
+		{Webhook: "audit", Event: "users.create", Payload: "{}", Status: v1.WebhookDeliveryPending, Attempts: 1},
+		{Webhook: "deleted", Event: "users.create", Payload: "{}", Status: v1.WebhookDeliveryPending, Attempts: 1},
+		{Webhook: "audit", Event: "users.delete", Payload: "{}", Status: v1.WebhookDeliverySucceeded, Attempts: 1},
+	}
+	for _, delivery := range deliveries {
+		if err := factory.WebhookDeliveries().Create(ctx, delivery); err != nil {
+			t.Fatalf("create delivery: %v", err)
+		}
+	}
+
+	d := newDispatcher(genericoptions.NewWebhookOptions())
+	d.Start()
+
+	select {
+	case id := <-received:
+		if id != "1" {
+			t.Errorf("resumed delivery %s, want 1", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("pending delivery was not resumed")
+	}
+
+	_ = d.Close()
+
+	pending, err := factory.WebhookDeliveries().ListPending(ctx)
+	if err != nil {
+		t.Fatalf("list pending deliveries: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("%d deliveries still pending", len(pending))
+	}
+
+	list, err := factory.WebhookDeliveries().List(ctx, "deleted", metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("list deliveries: %v", err)
+	}
+	if len(list.Items) != 1 || list.Items[0].Status != v1.WebhookDeliveryFailed {
+		t.Errorf("delivery of the deleted webhook = %+v, want failed", list.Items)
+	}
+}
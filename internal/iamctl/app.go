@@ -0,0 +1,49 @@
+// Package iamctl is the command line client of the iam api server.
+package iamctl
+
+import (
+	"github.com/rose839/IAM/internal/iamctl/client"
+	"github.com/rose839/IAM/internal/iamctl/config"
+	"github.com/rose839/IAM/internal/iamctl/options"
+	"github.com/rose839/IAM/pkg/app"
+)
+
+const commandDesc = `iamctl controls the IAM platform, it creates, gets, updates and deletes
+the users, secrets and policies of the IAM API server.
+
+Run iamctl login first, it saves the server address and the token of the user
+in ~/.iam/config.`
+
+// NewApp creates the iamctl application.
+func NewApp(basename string) *app.App {
+	return app.NewApp(
+		"IAM command line client",
+		basename,
+		app.WithDescription(commandDesc),
+		app.WithNoConfig(),
+		app.WithNoVersion(),
+		app.WithSilence(),
+		app.WithCommands(
+			newLoginCommand(),
+			newGetCommand(),
+			newCreateCommand(),
+			newUpdateCommand(),
+			newDeleteCommand(),
+		),
+	)
+}
+
+// newClient returns a client configured by the configuration file, refreshed tokens are
+// saved to it.
+func newClient(opts *options.ClientOptions) (*client.Client, error) {
+	cfg, err := config.Load(opts.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Server != "" {
+		cfg.Server = opts.Server
+	}
+
+	return client.New(cfg, opts.Config)
+}
@@ -0,0 +1,240 @@
+// Package client is the rest client of the iam api server used by iamctl. It logs in with
+// the credentials of the configuration, caches the token in it and refreshes the token
+// before it expires.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rose839/IAM/internal/iamctl/config"
+)
+
+// refreshWindow is how long before it expires a cached token is refreshed.
+const refreshWindow = 5 * time.Minute
+
+// ErrLoginRequired is returned when there is no valid token and no password to log in with.
+var ErrLoginRequired = errors.New("not logged in or the token has expired, run iamctl login")
+
+// StatusError is returned for the responses with a failure http status.
+type StatusError struct {
+	Status  int    `json:"-"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *StatusError) Error() string {
+	if e.Code != 0 {
+		return fmt.Sprintf("%s (http status %d, code %d)", e.Message, e.Status, e.Code)
+	}
+
+	return fmt.Sprintf("%s (http status %d)", e.Message, e.Status)
+}
+
+// Client sends requests to the iam api server on behalf of the configured user.
+type Client struct {
+	cfg  *config.Config
+	path string
+	http *http.Client
+	now  func() time.Time
+}
+
+// New returns a client for the given configuration. Refreshed tokens are saved to the
+// configuration file at path, they are only kept in memory when path is empty.
+func New(cfg *config.Config, path string) (*Client, error) {
+	if cfg.Server == "" {
+		return nil, fmt.Errorf("the server is not configured, run iamctl login --server")
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipTLSVerify, //nolint:gosec // explicitly requested by the user
+	}
+	if cfg.CertificateAuthority != "" {
+		pem, err := os.ReadFile(cfg.CertificateAuthority)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", cfg.CertificateAuthority)
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &Client{
+		cfg:  cfg,
+		path: path,
+		http: &http.Client{Transport: transport, Timeout: 30 * time.Second},
+		now:  time.Now,
+	}, nil
+}
+
+// Login logs in with a username and password and caches the token.
+func (c *Client) Login(ctx context.Context, username, password string) error {
+	basic := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	if err := c.token(ctx, "/login", "Basic "+basic); err != nil {
+		return err
+	}
+
+	c.cfg.Username = username
+
+	return c.save()
+}
+
+// Do sends a request authenticated with the cached token, the json encoding of in is sent
+// as body and the json response is decoded into out, both are optional. The user logs in
+// again once when the token is refused and a password is configured.
+func (c *Client) Do(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	if err := c.authenticate(ctx); err != nil {
+		return err
+	}
+
+	err := c.do(ctx, method, path, query, in, out)
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.Status == http.StatusUnauthorized && c.cfg.Password != "" {
+		if err := c.Login(ctx, c.cfg.Username, c.cfg.Password); err != nil {
+			return err
+		}
+
+		return c.do(ctx, method, path, query, in, out)
+	}
+
+	return err
+}
+
+// authenticate makes sure a valid token is cached: a token about to expire is refreshed,
+// and the user logs in when there is no token or it can not be refreshed anymore.
+func (c *Client) authenticate(ctx context.Context) error {
+	if c.cfg.Token != "" && c.now().Add(refreshWindow).Before(c.cfg.Expire()) {
+		return nil
+	}
+
+	if c.cfg.Token != "" {
+		if err := c.token(ctx, "/refresh", "Bearer "+c.cfg.Token); err == nil {
+			return c.save()
+		}
+	}
+
+	if c.cfg.Username == "" || c.cfg.Password == "" {
+		return ErrLoginRequired
+	}
+
+	return c.Login(ctx, c.cfg.Username, c.cfg.Password)
+}
+
+// token requests a token from the login or refresh endpoint and caches it.
+func (c *Client) token(ctx context.Context, path, authorization string) error {
+	req, err := c.newRequest(ctx, http.MethodPost, path, nil, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", authorization)
+
+	var resp struct {
+		Token  string `json:"token"`
+		Expire string `json:"expire"`
+	}
+	if err := c.send(req, &resp); err != nil {
+		return err
+	}
+
+	expire, err := time.Parse(time.RFC3339, resp.Expire)
+	if err != nil {
+		return fmt.Errorf("invalid token expire time %q: %w", resp.Expire, err)
+	}
+	c.cfg.SetToken(resp.Token, expire)
+
+	return nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	req, err := c.newRequest(ctx, method, path, query, in)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+
+	return c.send(req, out)
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, in interface{}) (*http.Request, error) {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	u := strings.TrimSuffix(c.cfg.Server, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return req, nil
+}
+
+// send sends a request and decodes the json response into out, failures are returned as
+// StatusError carrying the business error code when there is one.
+func (c *Client) send(req *http.Request, out interface{}) error {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		statusErr := &StatusError{Status: resp.StatusCode}
+		if json.Unmarshal(data, statusErr) != nil || statusErr.Message == "" {
+			statusErr.Message = http.StatusText(resp.StatusCode)
+		}
+
+		return statusErr
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(data, out)
+}
+
+// save writes the configuration holding the new token.
+func (c *Client) save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	return config.Save(c.path, c.cfg)
+}
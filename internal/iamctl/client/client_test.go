@@ -0,0 +1,145 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rose839/IAM/internal/iamctl/config"
+)
+
+// testServer issues the token "login" on login, "refreshed" on refresh and accepts both.
+func testServer(t *testing.T, calls map[string]int) *httptest.Server {
+	t.Helper()
+
+	token := func(w http.ResponseWriter, token string) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"token":  token,
+			"expire": time.Now().Add(time.Hour).Format(time.RFC3339),
+		})
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		calls["login"]++
+		if username, password, ok := r.BasicAuth(); !ok || username != "admin" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message": "incorrect Username or Password"}`))
+
+			return
+		}
+		token(w, "login")
+	})
+	mux.HandleFunc("/refresh", func(w http.ResponseWriter, r *http.Request) {
+		calls["refresh"]++
+		if r.Header.Get("Authorization") != "Bearer expiring" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+		token(w, "refreshed")
+	})
+	mux.HandleFunc("/v1/users/admin", func(w http.ResponseWriter, r *http.Request) {
+		calls["get"]++
+		if auth := r.Header.Get("Authorization"); auth != "Bearer login" && auth != "Bearer refreshed" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+		_, _ = w.Write([]byte(`{"metadata": {"name": "admin"}}`))
+	})
+	mux.HandleFunc("/v1/users/nobody", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"code": 110001, "message": "User not found"}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestDo(t *testing.T) {
+	tests := []struct {
+		name      string
+		token     string
+		expire    time.Duration
+		password  string
+		wantToken string
+		wantCalls map[string]int
+		wantErr   error
+	}{
+		{"valid token", "login", time.Hour, "", "login", map[string]int{"get": 1}, nil},
+		{"expiring token", "expiring", time.Minute, "", "refreshed", map[string]int{"refresh": 1, "get": 1}, nil},
+		{
+			"expired token with password", "expired", -time.Hour, "secret", "login",
+			map[string]int{"refresh": 1, "login": 1, "get": 1}, nil,
+		},
+		{"expired token", "expired", -time.Hour, "", "expired", map[string]int{"refresh": 1}, ErrLoginRequired},
+		{"refused token", "revoked", time.Hour, "secret", "login", map[string]int{"get": 2, "login": 1}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := map[string]int{}
+			path := filepath.Join(t.TempDir(), "config")
+			cfg := &config.Config{Server: testServer(t, calls).URL, Username: "admin", Password: tt.password}
+			cfg.SetToken(tt.token, time.Now().Add(tt.expire))
+
+			c, err := New(cfg, path)
+			if err != nil {
+				t.Fatalf("New() error: %v", err)
+			}
+
+			var user struct {
+				Metadata struct {
+					Name string `json:"name"`
+				} `json:"metadata"`
+			}
+			err = c.Do(context.Background(), http.MethodGet, "/v1/users/admin", nil, nil, &user)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Do() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && user.Metadata.Name != "admin" {
+				t.Errorf("Do() decoded %+v", user)
+			}
+
+			if cfg.Token != tt.wantToken {
+				t.Errorf("token = %s, want %s", cfg.Token, tt.wantToken)
+			}
+			if len(calls) != len(tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+			for k, v := range tt.wantCalls {
+				if calls[k] != v {
+					t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+				}
+			}
+
+			if saved, _ := config.Load(path); tt.wantToken != tt.token && saved.Token != tt.wantToken {
+				t.Errorf("saved token = %s, want %s", saved.Token, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestDoStatusError(t *testing.T) {
+	cfg := &config.Config{Server: testServer(t, map[string]int{}).URL}
+	cfg.SetToken("login", time.Now().Add(time.Hour))
+
+	c, _ := New(cfg, "")
+	err := c.Do(context.Background(), http.MethodGet, "/v1/users/nobody", nil, nil, nil)
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.Status != http.StatusNotFound || statusErr.Code != 110001 {
+		t.Fatalf("Do() error = %#v, want a not found StatusError with code 110001", err)
+	}
+	if want := "User not found (http status 404, code 110001)"; err.Error() != want {
+		t.Errorf("Do() error = %q, want %q", err, want)
+	}
+}
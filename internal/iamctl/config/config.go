@@ -0,0 +1,93 @@
+// Package config reads and writes the iamctl configuration file, which holds the address
+// of the iam api server, the credentials of the user and the cached token.
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/rose839/IAM/pkg/homedir"
+)
+
+// EnvConfig is the environment variable holding the path of the configuration file.
+const EnvConfig = "IAMCONFIG"
+
+// Config is the iamctl configuration.
+type Config struct {
+	// Server is the address of the iam api server, like https://127.0.0.1:8443.
+	Server string `json:"server"`
+
+	// CertificateAuthority is the path of the ca file the server certificate is verified with.
+	CertificateAuthority string `json:"certificate-authority,omitempty"`
+
+	// InsecureSkipTLSVerify skips the verification of the server certificate.
+	InsecureSkipTLSVerify bool `json:"insecure-skip-tls-verify,omitempty"`
+
+	// Username and Password log in when the token can not be refreshed.
+	// Password is optional, login asks for it.
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+
+	// Token is the cached jwt token, it is refreshed before it expires.
+	Token       string `json:"token,omitempty"`
+	TokenExpire string `json:"token-expire,omitempty"`
+}
+
+// DefaultPath returns the path of the configuration file, $IAMCONFIG or ~/.iam/config.
+func DefaultPath() string {
+	if path := os.Getenv(EnvConfig); path != "" {
+		return path
+	}
+
+	return filepath.Join(homedir.HomeDir(), ".iam", "config")
+}
+
+// Load reads the configuration file, an empty configuration is returned when it does not exist.
+func Load(path string) (*Config, error) {
+	cfg := &Config{}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Save writes the configuration file, it is only readable by the user as it holds credentials.
+func Save(path string, cfg *Config) error {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o600)
+}
+
+// Expire returns the expire time of the cached token, zero when there is none.
+func (c *Config) Expire() time.Time {
+	expire, _ := time.Parse(time.RFC3339, c.TokenExpire)
+
+	return expire
+}
+
+// SetToken caches a token.
+func (c *Config) SetToken(token string, expire time.Time) {
+	c.Token = token
+	c.TokenExpire = expire.Format(time.RFC3339)
+}
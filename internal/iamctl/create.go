@@ -0,0 +1,87 @@
+package iamctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/rose839/IAM/internal/iamctl/options"
+	"github.com/rose839/IAM/internal/iamctl/printer"
+	"github.com/rose839/IAM/pkg/app"
+)
+
+// newCreateCommand returns the create command creating an object from a file.
+func newCreateCommand() *app.Command {
+	opts := options.NewFileOptions()
+
+	return app.NewCommand(
+		"create RESOURCE -f FILE",
+		"Create an user, secret or policy from a yaml or json file",
+		app.WithCommandOptions(opts),
+		app.WithCommandRunFunc(func(args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("create requires a resource, got %q", args)
+			}
+
+			r, err := findResource(args[0])
+			if err != nil {
+				return err
+			}
+
+			return sendFile(r, opts, http.MethodPost, "/v1/"+r.name, "created")
+		}),
+	)
+}
+
+// sendFile sends the object of the file given by the options to the server and prints
+// the returned object, or a message with the given verb.
+func sendFile(r *resource, opts *options.FileOptions, method, path, verb string) error {
+	body, err := readObjectFile(r, opts.File)
+	if err != nil {
+		return err
+	}
+
+	c, err := newClient(opts.ClientOptions)
+	if err != nil {
+		return err
+	}
+
+	obj := r.newObject()
+	if err := c.Do(context.Background(), method, path, nil, body, obj); err != nil {
+		return err
+	}
+
+	if opts.Output != "" {
+		return printer.Print(os.Stdout, opts.Output, obj, r.table(obj))
+	}
+
+	fmt.Printf("%s/%s %s\n", r.name, r.fields(obj)["name"], verb)
+
+	return nil
+}
+
+// readObjectFile reads a yaml or json object of the resource and returns it as json. The
+// object is checked to only hold known fields.
+func readObjectFile(r *resource, file string) (json.RawMessage, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.UnmarshalStrict(data, r.newObject()); err != nil {
+		return nil, fmt.Errorf("decode %s failed: %w", file, err)
+	}
+
+	return yaml.YAMLToJSON(data)
+}
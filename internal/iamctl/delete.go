@@ -0,0 +1,47 @@
+package iamctl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/rose839/IAM/internal/iamctl/options"
+	"github.com/rose839/IAM/pkg/app"
+)
+
+// newDeleteCommand returns the delete command deleting objects by name.
+func newDeleteCommand() *app.Command {
+	opts := options.NewDeleteOptions()
+
+	return app.NewCommand(
+		"delete RESOURCE NAME...",
+		"Delete users, secrets or policies",
+		app.WithCommandOptions(opts),
+		app.WithCommandRunFunc(func(args []string) error {
+			if len(args) < 2 {
+				return fmt.Errorf("delete requires a resource and at least a name, got %q", args)
+			}
+
+			r, err := findResource(args[0])
+			if err != nil {
+				return err
+			}
+
+			c, err := newClient(opts.ClientOptions)
+			if err != nil {
+				return err
+			}
+
+			for _, name := range args[1:] {
+				if err := c.Do(context.Background(), http.MethodDelete, "/v1/"+r.name+"/"+url.PathEscape(name), nil, nil, nil); err != nil {
+					return err
+				}
+
+				fmt.Printf("%s/%s deleted\n", r.name, name)
+			}
+
+			return nil
+		}),
+	)
+}
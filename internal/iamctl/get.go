@@ -0,0 +1,128 @@
+package iamctl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/rose839/IAM/internal/iamctl/client"
+	"github.com/rose839/IAM/internal/iamctl/options"
+	"github.com/rose839/IAM/internal/iamctl/printer"
+	"github.com/rose839/IAM/pkg/app"
+	"github.com/rose839/IAM/pkg/fields"
+)
+
+// listPageSize is the number of objects listed per request when the objects are
+// selected by iamctl.
+const listPageSize = 500
+
+// newGetCommand returns the get command printing an object or listing the objects of a resource.
+func newGetCommand() *app.Command {
+	opts := options.NewGetOptions()
+
+	return app.NewCommand(
+		"get RESOURCE [NAME]",
+		"Print an object, or list the objects of users, secrets or policies",
+		app.WithCommandOptions(opts),
+		app.WithCommandRunFunc(func(args []string) error {
+			if len(args) == 0 || len(args) > 2 {
+				return fmt.Errorf("get requires a resource and an optional name, got %q", args)
+			}
+
+			r, err := findResource(args[0])
+			if err != nil {
+				return err
+			}
+
+			c, err := newClient(opts.ClientOptions)
+			if err != nil {
+				return err
+			}
+
+			if len(args) == 2 {
+				obj := r.newObject()
+				if err := c.Do(context.Background(), http.MethodGet, "/v1/"+r.name+"/"+url.PathEscape(args[1]), nil, nil, obj); err != nil {
+					return err
+				}
+
+				return printer.Print(os.Stdout, opts.Output, obj, r.table(obj))
+			}
+
+			items, err := list(context.Background(), c, r, opts)
+			if err != nil {
+				return err
+			}
+
+			return printer.Print(os.Stdout, opts.Output, items, r.table(items...))
+		}),
+	)
+}
+
+// list returns the objects of a resource selected by the field selector of opts.
+// The server only selects by name, and by substring, so the objects are selected here
+// and the offset and limit are applied to the selected objects: all pages of objects
+// are listed then.
+func list(ctx context.Context, c *client.Client, r *resource, opts *options.GetOptions) ([]interface{}, error) {
+	selector, err := fields.ParseSelector(opts.FieldSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	page := func(offset, limit int64) ([]interface{}, error) {
+		query := url.Values{}
+		query.Set("offset", strconv.FormatInt(offset, 10))
+		query.Set("limit", strconv.FormatInt(limit, 10))
+		if opts.FieldSelector != "" {
+			query.Set("fieldSelector", opts.FieldSelector)
+		}
+
+		resp := r.newList()
+		if err := c.Do(ctx, http.MethodGet, "/v1/"+r.name, query, nil, resp); err != nil {
+			return nil, err
+		}
+
+		return r.items(resp), nil
+	}
+
+	if selector.Empty() {
+		items, err := page(opts.Offset, opts.Limit)
+		if items == nil {
+			items = []interface{}{}
+		}
+
+		return items, err
+	}
+
+	var skipped int64
+	items := []interface{}{}
+	for offset := int64(0); ; offset += listPageSize {
+		objs, err := page(offset, listPageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range objs {
+			if !selector.Matches(r.fields(obj)) {
+				continue
+			}
+
+			if skipped < opts.Offset {
+				skipped++
+
+				continue
+			}
+
+			items = append(items, obj)
+			if int64(len(items)) == opts.Limit {
+				return items, nil
+			}
+		}
+
+		if int64(len(objs)) < listPageSize {
+			return items, nil
+		}
+	}
+}
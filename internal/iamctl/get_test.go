@@ -0,0 +1,90 @@
+package iamctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/iamctl/client"
+	"github.com/rose839/IAM/internal/iamctl/config"
+	"github.com/rose839/IAM/internal/iamctl/options"
+)
+
+func TestList(t *testing.T) {
+	// every third user is a service user, the server ignores the field selector.
+	var users []*v1.User
+	for i := 0; i < 2*listPageSize; i++ {
+		userType := "human"
+		if i%3 == 0 {
+			userType = "service"
+		}
+		users = append(users, &v1.User{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("user%04d", i)}, Type: userType})
+	}
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		end := offset + limit
+		if end > len(users) {
+			end = len(users)
+		}
+		_ = json.NewEncoder(w).Encode(&v1.UserList{ListMeta: metav1.ListMeta{TotalCount: int64(len(users))}, Items: users[offset:end]})
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{Server: server.URL}
+	cfg.SetToken("token", time.Now().Add(time.Hour))
+	c, err := client.New(cfg, "")
+	if err != nil {
+		t.Fatalf("client.New() error = %v", err)
+	}
+
+	r, _ := findResource("users")
+
+	tests := []struct {
+		name         string
+		selector     string
+		offset       int64
+		limit        int64
+		wantFirst    string
+		wantCount    int
+		wantRequests int
+	}{
+		{"paged by the server", "", 10, 20, "user0010", 20, 1},
+		{"selected across pages", "type=service", 100, 1000, "user0300", 234, 3},
+		{"limit reached on the first page", "type=human", 0, 5, "user0001", 5, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = 0
+			opts := options.NewGetOptions()
+			opts.FieldSelector, opts.Offset, opts.Limit = tt.selector, tt.offset, tt.limit
+
+			items, err := list(context.Background(), c, r, opts)
+			if err != nil {
+				t.Fatalf("list() error = %v", err)
+			}
+
+			if len(items) != tt.wantCount {
+				t.Fatalf("list() returned %d objects, want %d", len(items), tt.wantCount)
+			}
+			if name := items[0].(*v1.User).Name; name != tt.wantFirst {
+				t.Errorf("first object = %s, want %s", name, tt.wantFirst)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("sent %d requests, want %d", requests, tt.wantRequests)
+			}
+		})
+	}
+}
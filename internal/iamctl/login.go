@@ -0,0 +1,107 @@
+package iamctl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/rose839/IAM/internal/iamctl/client"
+	"github.com/rose839/IAM/internal/iamctl/config"
+	"github.com/rose839/IAM/internal/iamctl/options"
+	"github.com/rose839/IAM/pkg/app"
+)
+
+// newLoginCommand returns the login command saving the server and the token of the user
+// in the configuration file.
+func newLoginCommand() *app.Command {
+	opts := options.NewLoginOptions()
+
+	return app.NewCommand(
+		"login",
+		"Log in to the iam api server and save the token",
+		app.WithCommandOptions(opts),
+		app.WithCommandRunFunc(func(args []string) error {
+			cfg, err := config.Load(opts.ClientOptions.Config)
+			if err != nil {
+				return err
+			}
+
+			if opts.ClientOptions.Server != "" {
+				cfg.Server = opts.ClientOptions.Server
+			}
+			if opts.CertificateAuthority != "" {
+				cfg.CertificateAuthority = opts.CertificateAuthority
+			}
+			if opts.InsecureSkipTLSVerify {
+				cfg.InsecureSkipTLSVerify = true
+			}
+
+			username := opts.Username
+			if username == "" {
+				username = cfg.Username
+			}
+			if username == "" {
+				return fmt.Errorf("--username can not be empty")
+			}
+
+			// a saved password belongs to the saved user
+			if username != cfg.Username {
+				cfg.Password = ""
+			}
+
+			password := opts.Password
+			if password == "" {
+				password = cfg.Password
+			}
+			if password == "" {
+				if password, err = readPassword(); err != nil {
+					return err
+				}
+			}
+
+			if opts.SavePassword {
+				cfg.Password = password
+			}
+
+			c, err := client.New(cfg, opts.ClientOptions.Config)
+			if err != nil {
+				return err
+			}
+
+			if err := c.Login(context.Background(), username, password); err != nil {
+				return err
+			}
+
+			fmt.Printf("Logged in to %s as %s, token saved in %s\n", cfg.Server, username, opts.ClientOptions.Config)
+
+			return nil
+		}),
+	)
+}
+
+// readPassword asks for the password on the terminal without echoing it, or reads it
+// from the first line of stdin when it is not a terminal.
+func readPassword() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("read password from stdin failed: %w", err)
+		}
+
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+
+	return string(password), nil
+}
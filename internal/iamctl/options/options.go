@@ -0,0 +1,202 @@
+// Package options contains the flags and options of the iamctl commands.
+package options
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+
+	"github.com/rose839/IAM/internal/iamctl/config"
+	"github.com/rose839/IAM/internal/iamctl/printer"
+	cliflag "github.com/rose839/IAM/pkg/app"
+	"github.com/rose839/IAM/pkg/fields"
+)
+
+// ClientOptions selects the configuration file and the server of all commands.
+type ClientOptions struct {
+	Config string `json:"iamconfig"`
+	Server string `json:"server"`
+}
+
+// NewClientOptions creates a new ClientOptions object with default parameters.
+func NewClientOptions() *ClientOptions {
+	return &ClientOptions{
+		Config: config.DefaultPath(),
+	}
+}
+
+// AddFlags adds flags related to the client to the specified FlagSet.
+func (o *ClientOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.Config, "iamconfig", o.Config, ""+
+		"Path of the iamctl configuration file, defaults to $"+config.EnvConfig+" or ~/.iam/config.")
+	fs.StringVar(&o.Server, "server", o.Server, ""+
+		"Address of the iam api server, like https://127.0.0.1:8443, overrides the configured one.")
+}
+
+// Validate checks ClientOptions and return a slice of found errs.
+func (o *ClientOptions) Validate() []error {
+	var errs []error
+
+	if o.Config == "" {
+		errs = append(errs, fmt.Errorf("--iamconfig can not be empty"))
+	}
+
+	return errs
+}
+
+// LoginOptions runs the login command.
+type LoginOptions struct {
+	ClientOptions         *ClientOptions `json:"client"`
+	Username              string         `json:"username"`
+	Password              string         `json:"password"`
+	SavePassword          bool           `json:"save-password"`
+	CertificateAuthority  string         `json:"certificate-authority"`
+	InsecureSkipTLSVerify bool           `json:"insecure-skip-tls-verify"`
+}
+
+// NewLoginOptions creates a new LoginOptions object with default parameters.
+func NewLoginOptions() *LoginOptions {
+	return &LoginOptions{
+		ClientOptions: NewClientOptions(),
+	}
+}
+
+// Flags returns flags of the login command by section name.
+func (o *LoginOptions) Flags() (fss cliflag.NamedFlagSets) {
+	o.ClientOptions.AddFlags(fss.FlagSet("client"))
+
+	fs := fss.FlagSet("login")
+	fs.StringVarP(&o.Username, "username", "u", o.Username, "Username to log in with, defaults to the configured one.")
+	fs.StringVarP(&o.Password, "password", "p", o.Password, "Password to log in with, it is asked for when empty.")
+	fs.BoolVar(&o.SavePassword, "save-password", o.SavePassword, ""+
+		"Save the password in the configuration file to log in again when the token can not be refreshed anymore.")
+	fs.StringVar(&o.CertificateAuthority, "certificate-authority", o.CertificateAuthority, ""+
+		"Path of the ca file the server certificate is verified with.")
+	fs.BoolVar(&o.InsecureSkipTLSVerify, "insecure-skip-tls-verify", o.InsecureSkipTLSVerify, ""+
+		"Do not verify the server certificate, this makes the connection insecure.")
+
+	return fss
+}
+
+// Validate checks LoginOptions and return a slice of found errs.
+func (o *LoginOptions) Validate() []error {
+	return o.ClientOptions.Validate()
+}
+
+// GetOptions runs the get command.
+type GetOptions struct {
+	ClientOptions *ClientOptions `json:"client"`
+	Output        string         `json:"output"`
+	FieldSelector string         `json:"field-selector"`
+	Offset        int64          `json:"offset"`
+	Limit         int64          `json:"limit"`
+}
+
+// NewGetOptions creates a new GetOptions object with default parameters.
+func NewGetOptions() *GetOptions {
+	return &GetOptions{
+		ClientOptions: NewClientOptions(),
+		Output:        printer.FormatTable,
+		Limit:         1000,
+	}
+}
+
+// Flags returns flags of the get command by section name.
+func (o *GetOptions) Flags() (fss cliflag.NamedFlagSets) {
+	o.ClientOptions.AddFlags(fss.FlagSet("client"))
+
+	fs := fss.FlagSet("get")
+	fs.StringVarP(&o.Output, "output", "o", o.Output, "Output format, one of table, json or yaml.")
+	fs.StringVar(&o.FieldSelector, "field-selector", o.FieldSelector, ""+
+		"Select the listed objects by their fields, like type=human,name!=admin. Users have the name, nickname, "+
+		"email, phone, type and owner fields, secrets name, username, secretID and description, "+
+		"policies name, username and effect.")
+	fs.Int64Var(&o.Offset, "offset", o.Offset, "Number of objects skipped before the listed ones.")
+	fs.Int64Var(&o.Limit, "limit", o.Limit, "Maximum number of listed objects.")
+
+	return fss
+}
+
+// Validate checks GetOptions and return a slice of found errs.
+func (o *GetOptions) Validate() []error {
+	errs := o.ClientOptions.Validate()
+
+	if !printer.Formats.Has(o.Output) {
+		errs = append(errs, fmt.Errorf("--output must be one of %v", printer.Formats.List()))
+	}
+
+	if _, err := fields.ParseSelector(o.FieldSelector); err != nil {
+		errs = append(errs, fmt.Errorf("--field-selector is invalid: %w", err))
+	}
+
+	if o.Offset < 0 || o.Limit <= 0 {
+		errs = append(errs, fmt.Errorf("--offset can not be negative and --limit must be positive"))
+	}
+
+	return errs
+}
+
+// FileOptions runs the commands creating or updating an object from a file.
+type FileOptions struct {
+	ClientOptions *ClientOptions `json:"client"`
+	File          string         `json:"file"`
+	Output        string         `json:"output"`
+}
+
+// NewFileOptions creates a new FileOptions object with default parameters.
+func NewFileOptions() *FileOptions {
+	return &FileOptions{
+		ClientOptions: NewClientOptions(),
+	}
+}
+
+// Flags returns flags of the command by section name.
+func (o *FileOptions) Flags() (fss cliflag.NamedFlagSets) {
+	o.ClientOptions.AddFlags(fss.FlagSet("client"))
+
+	fs := fss.FlagSet("file")
+	fs.StringVarP(&o.File, "file", "f", o.File, "Yaml or json file of the object, - for stdin.")
+	fs.StringVarP(&o.Output, "output", "o", o.Output, ""+
+		"Print the object in the given format, one of table, json or yaml, instead of a message.")
+
+	return fss
+}
+
+// Validate checks FileOptions and return a slice of found errs.
+func (o *FileOptions) Validate() []error {
+	errs := o.ClientOptions.Validate()
+
+	if o.File == "" {
+		errs = append(errs, fmt.Errorf("--file can not be empty"))
+	}
+
+	if o.Output != "" && !printer.Formats.Has(o.Output) {
+		errs = append(errs, fmt.Errorf("--output must be one of %v", printer.Formats.List()))
+	}
+
+	return errs
+}
+
+// DeleteOptions runs the delete command.
+type DeleteOptions struct {
+	ClientOptions *ClientOptions `json:"client"`
+}
+
+// NewDeleteOptions creates a new DeleteOptions object with default parameters.
+func NewDeleteOptions() *DeleteOptions {
+	return &DeleteOptions{
+		ClientOptions: NewClientOptions(),
+	}
+}
+
+// Flags returns flags of the delete command by section name.
+func (o *DeleteOptions) Flags() (fss cliflag.NamedFlagSets) {
+	o.ClientOptions.AddFlags(fss.FlagSet("client"))
+
+	return fss
+}
+
+// Validate checks DeleteOptions and return a slice of found errs.
+func (o *DeleteOptions) Validate() []error {
+	return o.ClientOptions.Validate()
+}
@@ -0,0 +1,74 @@
+// Package printer prints the objects of the iam api server as a table, json or yaml.
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gosuri/uitable"
+	"sigs.k8s.io/yaml"
+
+	"github.com/rose839/IAM/pkg/sets"
+)
+
+// Output formats.
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+)
+
+// Formats are the supported output formats.
+var Formats = sets.NewString(FormatTable, FormatJSON, FormatYAML)
+
+// Table is the tabular view of objects.
+type Table struct {
+	Columns []string
+	Rows    [][]interface{}
+}
+
+// Print prints obj as json or yaml, or prints its table.
+func Print(w io.Writer, format string, obj interface{}, table *Table) error {
+	switch format {
+	case FormatTable:
+		t := uitable.New()
+		t.Separator = "  "
+		t.MaxColWidth = 60
+
+		header := make([]interface{}, 0, len(table.Columns))
+		for _, column := range table.Columns {
+			header = append(header, strings.ToUpper(column))
+		}
+		t.AddRow(header...)
+
+		for _, row := range table.Rows {
+			t.AddRow(row...)
+		}
+
+		_, err := fmt.Fprintln(w, t)
+
+		return err
+	case FormatJSON:
+		data, err := json.MarshalIndent(obj, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(w, string(data))
+
+		return err
+	case FormatYAML:
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+
+		_, err = w.Write(data)
+
+		return err
+	default:
+		return fmt.Errorf("unsupported output format %s, must be one of %v", format, Formats.List())
+	}
+}
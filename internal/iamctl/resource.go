@@ -0,0 +1,167 @@
+package iamctl
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	"github.com/rose839/IAM/internal/iamctl/printer"
+	"github.com/rose839/IAM/pkg/fields"
+	"github.com/rose839/IAM/pkg/sets"
+)
+
+// resource describes a rest resource of the iam api server managed by iamctl.
+type resource struct {
+	name      string
+	aliases   []string
+	columns   []string
+	newObject func() interface{}
+	newList   func() interface{}
+	items     func(list interface{}) []interface{}
+
+	// fields returns the fields the objects can be selected by, row the table row.
+	fields func(obj interface{}) fields.Set
+	row    func(obj interface{}) []interface{}
+}
+
+var resources = []*resource{
+	{
+		name:      "users",
+		aliases:   []string{"user"},
+		columns:   []string{"name", "nickname", "email", "type", "admin", "policies", "age"},
+		newObject: func() interface{} { return &v1.User{} },
+		newList:   func() interface{} { return &v1.UserList{} },
+		items: func(list interface{}) []interface{} {
+			var items []interface{}
+			for _, u := range list.(*v1.UserList).Items {
+				items = append(items, u)
+			}
+
+			return items
+		},
+		fields: func(obj interface{}) fields.Set {
+			u := obj.(*v1.User)
+
+			return fields.Set{
+				"name":     u.Name,
+				"nickname": u.Nickname,
+				"email":    u.Email,
+				"phone":    u.Phone,
+				"type":     u.Type,
+				"owner":    u.Owner,
+			}
+		},
+		row: func(obj interface{}) []interface{} {
+			u := obj.(*v1.User)
+
+			return []interface{}{u.Name, u.Nickname, u.Email, u.Type, u.IsAdmin == 1, u.TotalPolicy, age(u.CreatedAt)}
+		},
+	},
+	{
+		name:      "secrets",
+		aliases:   []string{"secret"},
+		columns:   []string{"name", "username", "secretID", "expires", "description", "age"},
+		newObject: func() interface{} { return &v1.Secret{} },
+		newList:   func() interface{} { return &v1.SecretList{} },
+		items: func(list interface{}) []interface{} {
+			var items []interface{}
+			for _, s := range list.(*v1.SecretList).Items {
+				items = append(items, s)
+			}
+
+			return items
+		},
+		fields: func(obj interface{}) fields.Set {
+			s := obj.(*v1.Secret)
+
+			return fields.Set{
+				"name":        s.Name,
+				"username":    s.Username,
+				"secretID":    s.SecretID,
+				"description": s.Description,
+			}
+		},
+		row: func(obj interface{}) []interface{} {
+			s := obj.(*v1.Secret)
+
+			expires := "never"
+			if s.Expires != 0 {
+				expires = time.Unix(s.Expires, 0).Format(time.RFC3339)
+			}
+
+			return []interface{}{s.Name, s.Username, s.SecretID, expires, s.Description, age(s.CreatedAt)}
+		},
+	},
+	{
+		name:      "policies",
+		aliases:   []string{"policy"},
+		columns:   []string{"name", "username", "effect", "description", "age"},
+		newObject: func() interface{} { return &v1.Policy{} },
+		newList:   func() interface{} { return &v1.PolicyList{} },
+		items: func(list interface{}) []interface{} {
+			var items []interface{}
+			for _, p := range list.(*v1.PolicyList).Items {
+				items = append(items, p)
+			}
+
+			return items
+		},
+		fields: func(obj interface{}) fields.Set {
+			p := obj.(*v1.Policy)
+
+			return fields.Set{
+				"name":     p.Name,
+				"username": p.Username,
+				"effect":   p.Policy.Effect,
+			}
+		},
+		row: func(obj interface{}) []interface{} {
+			p := obj.(*v1.Policy)
+
+			return []interface{}{p.Name, p.Username, p.Policy.Effect, p.Policy.Description, age(p.CreatedAt)}
+		},
+	},
+}
+
+// findResource returns the resource with the given name or alias.
+func findResource(name string) (*resource, error) {
+	names := sets.NewString()
+	for _, r := range resources {
+		if r.name == name || sets.NewString(r.aliases...).Has(name) {
+			return r, nil
+		}
+		names.Insert(r.name)
+	}
+
+	return nil, fmt.Errorf("unknown resource %q, must be one of %v", name, names.List())
+}
+
+// age returns how long ago t was in a short human readable form, like 3d or 5h.
+func age(t time.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+
+	d := time.Since(t)
+	switch {
+	case d >= 48*time.Hour:
+		return strconv.Itoa(int(d.Hours()/24)) + "d"
+	case d >= 2*time.Hour:
+		return strconv.Itoa(int(d.Hours())) + "h"
+	case d >= 2*time.Minute:
+		return strconv.Itoa(int(d.Minutes())) + "m"
+	default:
+		return strconv.Itoa(int(d.Seconds())) + "s"
+	}
+}
+
+// table returns the table of the given objects.
+func (r *resource) table(objs ...interface{}) *printer.Table {
+	t := &printer.Table{Columns: r.columns}
+	for _, obj := range objs {
+		t.Rows = append(t.Rows, r.row(obj))
+	}
+
+	return t
+}
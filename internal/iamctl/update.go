@@ -0,0 +1,33 @@
+package iamctl
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/rose839/IAM/internal/iamctl/options"
+	"github.com/rose839/IAM/pkg/app"
+)
+
+// newUpdateCommand returns the update command replacing an object by the one of a file.
+func newUpdateCommand() *app.Command {
+	opts := options.NewFileOptions()
+
+	return app.NewCommand(
+		"update RESOURCE NAME -f FILE",
+		"Update an user, secret or policy from a yaml or json file",
+		app.WithCommandOptions(opts),
+		app.WithCommandRunFunc(func(args []string) error {
+			if len(args) != 2 {
+				return fmt.Errorf("update requires a resource and a name, got %q", args)
+			}
+
+			r, err := findResource(args[0])
+			if err != nil {
+				return err
+			}
+
+			return sendFile(r, opts, http.MethodPut, "/v1/"+r.name+"/"+url.PathEscape(args[1]), "updated")
+		}),
+	)
+}
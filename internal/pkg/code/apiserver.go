@@ -7,6 +7,15 @@ const (
 
 	// ErrUserAlreadyExist - 400: User already exist.
 	ErrUserAlreadyExist
+
+	// ErrAccountLocked - 403: Account is locked due to too many failed login attempts.
+	ErrAccountLocked
+
+	// ErrPasswordExpired - 401: Password has expired and must be changed.
+	ErrPasswordExpired
+
+	// ErrVerificationTokenInvalid - 400: Verification token is invalid or expired.
+	ErrVerificationTokenInvalid
 )
 
 // iam-apiserver: secret errors.
@@ -23,3 +32,21 @@ const (
 	// ErrPolicyNotFound - 404: Policy not found.
 	ErrPolicyNotFound int = iota + 110201
 )
+
+// iam-apiserver: group errors.
+const (
+	// ErrGroupNotFound - 404: Group not found.
+	ErrGroupNotFound int = iota + 110301
+)
+
+// iam-apiserver: service account errors.
+const (
+	// ErrServiceAccountNotFound - 404: Service account not found.
+	ErrServiceAccountNotFound int = iota + 110401
+)
+
+// iam-apiserver: webhook errors.
+const (
+	// ErrWebhookNotFound - 404: Webhook not found.
+	ErrWebhookNotFound int = iota + 110501
+)
@@ -4,9 +4,15 @@ package code
 func init() {
 	register(ErrUserNotFound, 404, "User not found")
 	register(ErrUserAlreadyExist, 400, "User already exist")
+	register(ErrAccountLocked, 403, "Account is locked due to too many failed login attempts")
+	register(ErrPasswordExpired, 401, "Password has expired and must be changed")
+	register(ErrVerificationTokenInvalid, 400, "Verification token is invalid or expired")
 	register(ErrReachMaxCount, 400, "Secret reach the max count")
 	register(ErrSecretNotFound, 404, "Secret not found")
 	register(ErrPolicyNotFound, 404, "Policy not found")
+	register(ErrGroupNotFound, 404, "Group not found")
+	register(ErrServiceAccountNotFound, 404, "Service account not found")
+	register(ErrWebhookNotFound, 404, "Webhook not found")
 	register(ErrSuccess, 200, "OK")
 	register(ErrUnknown, 500, "Internal server error")
 	register(ErrBind, 400, "Error occurred while binding the request body to the struct")
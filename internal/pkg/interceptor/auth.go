@@ -0,0 +1,153 @@
+package interceptor
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/rose839/IAM/pkg/log"
+	"github.com/rose839/IAM/pkg/sets"
+)
+
+// Authentication modes of the grpc server.
+const (
+	// AuthModeNone accepts every caller.
+	AuthModeNone = "none"
+
+	// AuthModeToken identifies callers by the bearer token in the authorization metadata.
+	AuthModeToken = "token"
+
+	// AuthModeMTLS identifies callers by the common name of their verified client certificate.
+	AuthModeMTLS = "mtls"
+)
+
+// healthServicePrefix prefixes the methods of the standard grpc health service.
+const healthServicePrefix = "/grpc.health.v1.Health/"
+
+// AuthConfig configures the caller authentication.
+type AuthConfig struct {
+	// Mode is one of AuthModeNone, AuthModeToken and AuthModeMTLS.
+	Mode string
+
+	// Tokens maps the caller names to their bearer tokens, used by AuthModeToken.
+	Tokens map[string]string
+
+	// AllowedCallers are the caller names allowed to call the server.
+	AllowedCallers []string
+}
+
+// UnaryAuth rejects calls of unauthenticated callers with Unauthenticated and of
+// callers not in the allowed list with PermissionDenied. The caller name is stored
+// in the context for log.L.
+func UnaryAuth(cfg AuthConfig) grpc.UnaryServerInterceptor {
+	allowed := sets.NewString(cfg.AllowedCallers...)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authorize(ctx, cfg, allowed, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuth is the stream version of UnaryAuth.
+func StreamAuth(cfg AuthConfig) grpc.StreamServerInterceptor {
+	allowed := sets.NewString(cfg.AllowedCallers...)
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(ss.Context(), cfg, allowed, info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// CallerFrom returns the authenticated caller of a call.
+func CallerFrom(ctx context.Context) string {
+	caller, _ := log.FieldFrom(ctx, log.KeyUsername).(string)
+
+	return caller
+}
+
+func authorize(ctx context.Context, cfg AuthConfig, allowed sets.String, method string) (context.Context, error) {
+	// health probes of orchestrators and load balancers carry no credentials.
+	if strings.HasPrefix(method, healthServicePrefix) {
+		return ctx, nil
+	}
+
+	var caller string
+	switch cfg.Mode {
+	case AuthModeToken:
+		caller = tokenCaller(ctx, cfg.Tokens)
+	case AuthModeMTLS:
+		caller = certificateCaller(ctx)
+	default:
+		return ctx, nil
+	}
+
+	if caller == "" {
+		log.L(ctx).Warnf("Reject unauthenticated call of %s", method)
+
+		return ctx, status.Error(codes.Unauthenticated, "caller is not authenticated")
+	}
+
+	if !allowed.Has(caller) {
+		log.L(ctx).Warnf("Reject call of %s by caller `%s` which is not allowed", method, caller)
+
+		return ctx, status.Errorf(codes.PermissionDenied, "caller `%s` is not allowed", caller)
+	}
+
+	return log.WithField(ctx, log.KeyUsername, caller), nil
+}
+
+// tokenCaller returns the name of the caller owning the bearer token of the call.
+func tokenCaller(ctx context.Context, tokens map[string]string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return ""
+	}
+
+	token := strings.TrimSpace(strings.TrimPrefix(values[0], "Bearer "))
+	if token == "" {
+		return ""
+	}
+
+	for caller, t := range tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+			return caller
+		}
+	}
+
+	return ""
+}
+
+// certificateCaller returns the common name of the verified client certificate of the call.
+func certificateCaller(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return ""
+	}
+
+	return info.State.VerifiedChains[0][0].Subject.CommonName
+}
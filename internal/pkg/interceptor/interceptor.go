@@ -0,0 +1,61 @@
+// Package interceptor provides the grpc server interceptors of the iam services:
+// tracing, request id propagation, logging, metrics, panic recovery and caller authentication.
+package interceptor
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// UnaryServerInterceptors returns the unary interceptors in the order they must be
+// chained, authentication comes last so that rejected calls are logged and measured.
+func UnaryServerInterceptors(auth AuthConfig) []grpc.UnaryServerInterceptor {
+	return []grpc.UnaryServerInterceptor{
+		UnaryTracing(),
+		UnaryRequestID(),
+		UnaryLogger(),
+		UnaryMetrics(),
+		UnaryRecovery(),
+		UnaryAuth(auth),
+	}
+}
+
+// StreamServerInterceptors returns the stream interceptors in the order they must be chained.
+func StreamServerInterceptors(auth AuthConfig) []grpc.StreamServerInterceptor {
+	return []grpc.StreamServerInterceptor{
+		StreamTracing(),
+		StreamRequestID(),
+		StreamLogger(),
+		StreamMetrics(),
+		StreamRecovery(),
+		StreamAuth(auth),
+	}
+}
+
+// ChainUnaryServer chains the unary interceptors into one, the first one is the outermost.
+// It runs calls served outside of a grpc server, e.g. by an http gateway, through the
+// same interceptors as the grpc server.
+func ChainUnaryServer(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		chained := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], chained
+			chained = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, next)
+			}
+		}
+
+		return chained(ctx, req)
+	}
+}
+
+// serverStream overrides the context of a grpc.ServerStream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
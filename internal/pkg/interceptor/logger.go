@@ -0,0 +1,50 @@
+package interceptor
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/rose839/IAM/pkg/log"
+)
+
+// UnaryLogger logs every call with its peer, status code and latency.
+func UnaryLogger() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, info.FullMethod, start, err)
+
+		return resp, err
+	}
+}
+
+// StreamLogger is the stream version of UnaryLogger.
+func StreamLogger() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(ss.Context(), info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+func logCall(ctx context.Context, method string, start time.Time, err error) {
+	var addr string
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr.String()
+	}
+
+	code := status.Code(err)
+	if err != nil {
+		log.L(ctx).Warnf("%s | %s | %13v | %s | %s", code, addr, time.Since(start), method, err.Error())
+
+		return
+	}
+
+	log.L(ctx).Infof("%s | %s | %13v | %s", code, addr, time.Since(start), method)
+}
@@ -0,0 +1,116 @@
+package interceptor
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/rose839/IAM/internal/pkg/metrics"
+)
+
+// sizeBuckets are the buckets of the payload sizes, from 64 bytes to 1MiB.
+var sizeBuckets = prometheus.ExponentialBuckets(64, 4, 8)
+
+var (
+	handledCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "iam",
+		Subsystem: "grpc_server",
+		Name:      "handled_total",
+		Help:      "Total number of grpc calls completed on the server, regardless of success or failure.",
+	}, []string{"method", "code"})
+
+	handlingSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "iam",
+		Subsystem: "grpc_server",
+		Name:      "handling_seconds",
+		Help:      "Latency of grpc calls handled by the server.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	receivedBytes = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "iam",
+		Subsystem: "grpc_server",
+		Name:      "msg_received_bytes",
+		Help:      "Size of the messages received by the server.",
+		Buckets:   sizeBuckets,
+	}, []string{"method"})
+
+	sentBytes = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "iam",
+		Subsystem: "grpc_server",
+		Name:      "msg_sent_bytes",
+		Help:      "Size of the messages sent by the server.",
+		Buckets:   sizeBuckets,
+	}, []string{"method"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(handledCounter, handlingSeconds, receivedBytes, sentBytes)
+}
+
+// UnaryMetrics counts the calls by method and status code and measures their latency
+// and payload sizes, the metrics are exported by the /metrics endpoint of the rest server.
+func UnaryMetrics() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		observeSize(receivedBytes, info.FullMethod, req)
+
+		resp, err := handler(ctx, req)
+		observe(info.FullMethod, start, err)
+		if err == nil {
+			observeSize(sentBytes, info.FullMethod, resp)
+		}
+
+		return resp, err
+	}
+}
+
+// StreamMetrics is the stream version of UnaryMetrics.
+func StreamMetrics() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, &measuredStream{ServerStream: ss, method: info.FullMethod})
+		observe(info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+func observe(method string, start time.Time, err error) {
+	handledCounter.WithLabelValues(method, status.Code(err).String()).Inc()
+	handlingSeconds.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+func observeSize(histogram *prometheus.HistogramVec, method string, msg interface{}) {
+	if m, ok := msg.(proto.Message); ok {
+		histogram.WithLabelValues(method).Observe(float64(proto.Size(m)))
+	}
+}
+
+// measuredStream measures the sizes of the messages of a stream.
+type measuredStream struct {
+	grpc.ServerStream
+	method string
+}
+
+func (s *measuredStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		observeSize(sentBytes, s.method, m)
+	}
+
+	return err
+}
+
+func (s *measuredStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		observeSize(receivedBytes, s.method, m)
+	}
+
+	return err
+}
@@ -0,0 +1,67 @@
+package interceptor
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/rose839/IAM/internal/pkg/tracing"
+	"github.com/rose839/IAM/pkg/log"
+)
+
+// RequestIDKey is the metadata key carrying the request id, the lower cased X-Request-ID header.
+const RequestIDKey = "x-request-id"
+
+// UnaryRequestID takes the request id from the incoming metadata, or generates one,
+// stores it in the context for log.L and returns it in the response header. The trace
+// id of the caller, or a new one, and the full method name are stored as well.
+func UnaryRequestID() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(withRequestID(ctx, info.FullMethod), req)
+	}
+}
+
+// StreamRequestID is the stream version of UnaryRequestID.
+func StreamRequestID() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &serverStream{ServerStream: ss, ctx: withRequestID(ss.Context(), info.FullMethod)})
+	}
+}
+
+// RequestIDFrom returns the request id of a call.
+func RequestIDFrom(ctx context.Context) string {
+	requestID, _ := log.FieldFrom(ctx, log.KeyRequestID).(string)
+
+	return requestID
+}
+
+func withRequestID(ctx context.Context, method string) context.Context {
+	var requestID, traceparent string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDKey); len(values) > 0 {
+			requestID = strings.TrimSpace(values[0])
+		}
+		if values := md.Get(tracing.TraceParentHeader); len(values) > 0 {
+			traceparent = values[0]
+		}
+	}
+
+	if requestID == "" {
+		requestID = uuid.New().String()
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDKey, requestID))
+
+	for key, value := range map[string]string{
+		log.KeyRequestID: requestID,
+		log.KeyTraceID:   tracing.TraceID(ctx, traceparent),
+		log.KeyRoute:     method,
+	} {
+		ctx = log.WithField(ctx, key, value)
+	}
+
+	return ctx
+}
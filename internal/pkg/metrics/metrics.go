@@ -0,0 +1,92 @@
+// Package metrics defines the business metrics of iam. The metrics are registered in a
+// dedicated registry, which is exported together with the default registry holding the
+// http metrics of gin-prometheus by the /metrics endpoint of the generic api server.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Label values of the business metrics.
+const (
+	StrategyBasic = "basic"
+	StrategyJWT   = "jwt"
+
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+	ResultLocked  = "locked"
+	ResultExpired = "expired"
+
+	TokenIssue   = "issue"
+	TokenRefresh = "refresh"
+
+	SubjectUser = "user"
+	SubjectIP   = "ip"
+)
+
+// Registry is the registry of the iam metrics.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// Logins counts the password logins by strategy and result.
+	Logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "iam",
+		Subsystem: "apiserver",
+		Name:      "logins_total",
+		Help:      "Total number of password logins by strategy and result.",
+	}, []string{"strategy", "result"})
+
+	// Tokens counts the jwt tokens issued by login and refresh.
+	Tokens = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "iam",
+		Subsystem: "apiserver",
+		Name:      "tokens_total",
+		Help:      "Total number of jwt tokens issued by operation, one of issue or refresh.",
+	}, []string{"operation"})
+
+	// Lockouts counts the usernames and client addresses locked out after failed logins.
+	Lockouts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "iam",
+		Subsystem: "apiserver",
+		Name:      "lockouts_total",
+		Help:      "Total number of lockouts by subject, one of user or ip.",
+	}, []string{"subject"})
+
+	// Notifications counts the notifications published to the redis pub/sub channel.
+	Notifications = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "iam",
+		Subsystem: "apiserver",
+		Name:      "notifications_published_total",
+		Help:      "Total number of notifications published to the redis pub/sub channel by command and result.",
+	}, []string{"command", "result"})
+
+	// AuditWrites counts the audit events written to the audit sinks.
+	AuditWrites = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "iam",
+		Subsystem: "apiserver",
+		Name:      "audit_sink_writes_total",
+		Help:      "Total number of audit events written to the audit sinks by result.",
+	}, []string{"result"})
+)
+
+func init() {
+	Registry.MustRegister(Logins, Tokens, Lockouts, Notifications, AuditWrites)
+}
+
+// Result returns the result label of an operation which returned err.
+func Result(err error) string {
+	if err != nil {
+		return ResultFailure
+	}
+
+	return ResultSuccess
+}
+
+// Handler returns the handler of the /metrics endpoint, which exports the metrics of
+// the default registry and of Registry.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(prometheus.Gatherers{prometheus.DefaultGatherer, Registry}, promhttp.HandlerOpts{})
+}
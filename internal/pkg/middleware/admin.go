@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/core"
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+// Admin make sure only administrators can access the resource.
+func Admin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isAdmin(c) {
+			core.WriteResponse(c, errors.WithCode(code.ErrPermissionDenied, ""), nil)
+			c.Abort()
+
+			return
+		}
+
+		c.Next()
+	}
+}
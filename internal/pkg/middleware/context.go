@@ -1,17 +1,52 @@
 package middleware
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/rose839/IAM/internal/pkg/tracing"
+	"github.com/rose839/IAM/pkg/log"
+)
 
 const (
 	RequestIDKey = "requestID"
 	UsernameKey  = "username"
+
+	// OperatorKey holds the authenticated user when a request acts on behalf of
+	// another identity, which is then stored under UsernameKey.
+	OperatorKey = "operator"
+
+	// TraceIDKey holds the W3C trace id of the request.
+	TraceIDKey = "traceID"
+
+	// RouteKey holds the route pattern the request matched, e.g. /v1/users/:name.
+	RouteKey = "route"
 )
 
-// Context is a middleware that injects common prefix fields to gin.Context.
+// Context is a middleware that injects common prefix fields to gin.Context, so that
+// log.L(c) attaches them to every line logged for the request. The request id, trace id
+// and route are also stored in the context of the http request, for the code which is
+// only given c.Request.Context().
 func Context() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Set(RequestIDKey, c.GetString(XRequestIDKey))
+		requestID := c.GetString(XRequestIDKey)
+		traceID := tracing.TraceID(c.Request.Context(), c.GetHeader(tracing.TraceParentHeader))
+		route := c.FullPath()
+
+		c.Set(RequestIDKey, requestID)
 		c.Set(UsernameKey, c.GetString(UsernameKey))
+		c.Set(TraceIDKey, traceID)
+		c.Set(RouteKey, route)
+
+		ctx := c.Request.Context()
+		for key, value := range map[string]string{
+			log.KeyRequestID: requestID,
+			log.KeyTraceID:   traceID,
+			log.KeyRoute:     route,
+		} {
+			ctx = log.WithField(ctx, key, value)
+		}
+		c.Request = c.Request.WithContext(ctx)
+
 		c.Next()
 	}
 }
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -11,15 +12,42 @@ const (
 	maxAge = 12
 )
 
-// Cors add cors headers.
+// exposeHeaders are the response headers readable by browsers, including the rate limit headers.
+var exposeHeaders = []string{
+	"Content-Length", "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset",
+}
+
+// corsHandler holds the gin.HandlerFunc of the allowed origins, replaced by SetCorsOrigins.
+var corsHandler atomic.Value
+
+func init() {
+	SetCorsOrigins([]string{"*"})
+}
+
+// Cors add cors headers, for the origins given to SetCorsOrigins which defaults to all origins.
 func Cors() gin.HandlerFunc {
-	return cors.New(
-		cors.Config{
-			AllowOrigins:     []string{"*"},
-			AllowMethods:     []string{"PUT", "PATCH", "GET", "POST", "OPTIONS", "DELETE"},
-			AllowHeaders:     []string{"Origin", "Authorization", "Content-Type", "Accept"},
-			ExposeHeaders:    []string{"Content-Length"},
-			AllowCredentials: true,
-			MaxAge:           maxAge * time.Hour,
-		})
+	return func(c *gin.Context) {
+		corsHandler.Load().(gin.HandlerFunc)(c)
+	}
+}
+
+// SetCorsOrigins changes the origins allowed by the cors middleware at runtime, an origin
+// may contain a * wildcard, like https://*.example.com. The origins must be valid, see
+// CorsConfig.
+func SetCorsOrigins(origins []string) {
+	corsHandler.Store(cors.New(CorsConfig(origins)))
+}
+
+// CorsConfig returns the cors config allowing the given origins, its Validate method
+// reports invalid origins.
+func CorsConfig(origins []string) cors.Config {
+	return cors.Config{
+		AllowOrigins:     origins,
+		AllowMethods:     []string{"PUT", "PATCH", "GET", "POST", "OPTIONS", "DELETE"},
+		AllowHeaders:     []string{"Origin", "Authorization", "Content-Type", "Accept", ImpersonateUserHeader},
+		ExposeHeaders:    exposeHeaders,
+		AllowCredentials: true,
+		AllowWildcard:    true,
+		MaxAge:           maxAge * time.Hour,
+	}
 }
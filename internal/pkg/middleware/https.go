@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/core"
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+// HTTPSOnly make sure the resource is only served over https, requests received by the
+// insecure server are answered like unknown routes.
+func HTTPSOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.TLS == nil {
+			core.WriteResponse(c, errors.WithCode(code.ErrPageNotFound, "Page not found."), nil)
+			c.Abort()
+
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rose839/IAM/pkg/core"
+	"github.com/rose839/IAM/pkg/log"
+)
+
+// ImpersonateUserHeader names the user an administrator wants to act as.
+const ImpersonateUserHeader = "Impersonate-User"
+
+// AuthorizeImpersonation returns an error if operator is not allowed to act as target.
+type AuthorizeImpersonation func(ctx context.Context, operator, target string) error
+
+// Impersonate lets users permitted by authorize act as the user named by the
+// Impersonate-User header. The target user is stored under UsernameKey, so the
+// request is handled with its permissions, and the real user is kept under
+// OperatorKey. It must be installed after the authentication middleware.
+func Impersonate(authorize AuthorizeImpersonation) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		target := c.GetHeader(ImpersonateUserHeader)
+		if target == "" {
+			c.Next()
+
+			return
+		}
+
+		operator := c.GetString(UsernameKey)
+		if err := authorize(c, operator, target); err != nil {
+			log.L(c).Warnf("User `%s` failed to impersonate `%s`: %s", operator, target, err.Error())
+			core.WriteResponse(c, err, nil)
+			c.Abort()
+
+			return
+		}
+
+		c.Set(OperatorKey, operator)
+		c.Set(UsernameKey, target)
+		log.L(c).Infof("User `%s` impersonates `%s` for %s %s", operator, target, c.Request.Method, c.Request.URL.Path)
+
+		c.Next()
+	}
+}
@@ -1,7 +1,16 @@
 package middleware
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
 	"github.com/gin-gonic/gin"
+
+	"github.com/rose839/IAM/internal/pkg/metrics"
+	"github.com/rose839/IAM/pkg/log"
+	"github.com/rose839/IAM/pkg/storage"
 )
 
 // Define Redis pub/sub events.
@@ -9,15 +18,75 @@ const (
 	RedisPubSubChannel  = "iam.cluster.notifications"
 	NoticePolicyChanged = "PolicyChanged"
 	NoticeSecretChanged = "SecretChanged"
+	NoticeGroupChanged  = "GroupChanged"
 )
 
+// Notification is the message published to the redis pub/sub channel.
+type Notification struct {
+	Command string `json:"command"`
+}
+
+// resourceNotices are the notices published when the resources are changed,
+// a snapshot import replaces secrets and policies, users and service accounts are
+// deleted with their secrets and policies.
+var resourceNotices = map[string][]string{
+	"policies":        {NoticePolicyChanged},
+	"secrets":         {NoticeSecretChanged},
+	"groups":          {NoticeGroupChanged},
+	"snapshot":        {NoticeSecretChanged, NoticePolicyChanged},
+	"users":           {NoticeSecretChanged, NoticePolicyChanged},
+	"serviceaccounts": {NoticeSecretChanged, NoticePolicyChanged},
+}
+
 // Publish publish a redis event to specified redis channel when some action occurred.
 func Publish() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Next()
+
+		if c.Writer.Status() < http.StatusOK || c.Writer.Status() >= http.StatusMultipleChoices {
+			log.L(c).Debugf("Request failed with http status code `%d`, ignore publish message", c.Writer.Status())
+
+			return
+		}
+
+		// the last resource of the route decides, e.g. /v1/serviceaccounts/:name/secrets
+		var notices []string
+		for _, segment := range strings.Split(c.FullPath(), "/") {
+			if n, ok := resourceNotices[segment]; ok {
+				notices = n
+			}
+		}
+
+		for _, notice := range notices {
+			notify(c, c.Request.Method, notice)
+		}
+	}
+}
+
+func notify(ctx context.Context, method string, command string) {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return
 	}
+
+	_ = Notify(ctx, command)
 }
 
-func notify(method string, command string) {
+// Notify publishes a notice to the redis pub/sub channel, for changes made outside of
+// the request handlers, e.g. by the subcommands of the api server.
+func Notify(ctx context.Context, command string) error {
+	message, _ := json.Marshal(Notification{Command: command})
+	redisStore := &storage.RedisCluster{}
+	err := redisStore.WithContext(ctx).Publish(RedisPubSubChannel, string(message))
+	metrics.Notifications.WithLabelValues(command, metrics.Result(err)).Inc()
+	if err != nil {
+		log.L(ctx).Errorw("Publish redis message failed", "command", command, "error", err.Error())
+
+		return err
+	}
+
+	log.L(ctx).Debugw("Publish redis message", "command", command)
 
+	return nil
 }
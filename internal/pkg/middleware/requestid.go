@@ -17,12 +17,13 @@ func RequestID() gin.HandlerFunc {
 		rid := c.GetHeader(XRequestIDKey)
 
 		if rid == "" {
-			uuidNew, _ := uuid.NewV4()
-			rid = uuid.Must(uuidNew, nil).String()
+			rid = uuid.NewV4().String()
 			c.Request.Header.Set(XRequestIDKey, rid)
-			c.Set(XRequestIDKey, rid)
 		}
 
+		// keep the id for the Context middleware, including the one sent by the client
+		c.Set(XRequestIDKey, rid)
+
 		// Set XRequestIDKey response header
 		c.Writer.Header().Set(XRequestIDKey, rid)
 		c.Next()
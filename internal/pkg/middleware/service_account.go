@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "github.com/rose839/IAM/api/apiserver/v1"
+	metav1 "github.com/rose839/IAM/api/meta/v1"
+	"github.com/rose839/IAM/internal/apiserver/store"
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/core"
+	"github.com/rose839/IAM/pkg/errors"
+	"github.com/rose839/IAM/pkg/log"
+)
+
+// ServiceAccount make sure only administrators and owners of the service account
+// `:name` can access it.
+func ServiceAccount() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username := c.GetString(UsernameKey)
+		sa, err := store.Client().ServiceAccounts().Get(c, c.Param("name"), metav1.GetOptions{})
+		if err != nil {
+			core.WriteResponse(c, err, nil)
+			c.Abort()
+
+			return
+		}
+
+		if !isAdmin(c) && !ownsServiceAccount(c, username, sa) {
+			core.WriteResponse(c, errors.WithCode(code.ErrPermissionDenied, ""), nil)
+			c.Abort()
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ActAsServiceAccount switches the request to the identity of the service account
+// `:name`, so that the secrets and policies nested below a service account are handled
+// by the common secret and policy controllers. The `:resource` parameter is exposed
+// as `:name` to them. It must be installed after ServiceAccount.
+func ActAsServiceAccount() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// keep the administrator of an impersonated request as the operator.
+		if c.GetString(OperatorKey) == "" {
+			c.Set(OperatorKey, c.GetString(UsernameKey))
+		}
+		c.Set(UsernameKey, c.Param("name"))
+		c.Params = scopeParams(c.Params)
+
+		c.Next()
+	}
+}
+
+// ownsServiceAccount reports whether username is the owner of sa or a member of the owner group.
+func ownsServiceAccount(c *gin.Context, username string, sa *v1.ServiceAccount) bool {
+	prefix, owner := v1.ParseOwner(sa.Owner)
+	switch prefix {
+	case v1.UserOwnerPrefix:
+		return owner == username
+	case v1.GroupSubjectPrefix:
+		groups, err := store.Client().Groups().ListByUsers(c, []string{username})
+		if err != nil {
+			log.L(c).Errorf("List groups of user `%s` failed: %s", username, err.Error())
+
+			return false
+		}
+
+		for _, group := range groups[username] {
+			if group == owner {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// scopeParams drops the service account name and renames `resource` to `name`.
+func scopeParams(params gin.Params) gin.Params {
+	scoped := make(gin.Params, 0, len(params))
+	for _, param := range params {
+		switch param.Key {
+		case "name":
+			continue
+		case "resource":
+			param.Key = "name"
+		}
+
+		scoped = append(scoped, param)
+	}
+
+	return scoped
+}
@@ -26,7 +26,13 @@ func Validation() gin.HandlerFunc {
 
 					return
 				}
-			case "/v1/users/:name", "/v1/users/:name/change_password":
+			case "/v1/users/:name/unlock":
+				// non-admin user can't unlock user
+				core.WriteResponse(c, errors.WithCode(code.ErrPermissionDenied, ""), nil)
+				c.Abort()
+
+				return
+			case "/v1/users/:name", "/v1/users/:name/change-password", "/v1/users/:name/email-verification":
 				// non-admin user can't delete user, and can't modify user info that not belong to itself
 				username := c.GetString("username")
 				if c.Request.Method == http.MethodDelete ||
@@ -49,7 +55,7 @@ func isAdmin(c *gin.Context) bool {
 	username := c.GetString(UsernameKey)
 	user, err := store.Client().Users().Get(c, username, metav1.GetOptions{})
 	if err != nil {
-		log.Errorf("Check whether user is admin error: %s", err.Error())
+		log.L(c).Errorf("Check whether user is admin error: %s", err.Error())
 		return false
 	}
 
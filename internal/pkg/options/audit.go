@@ -0,0 +1,144 @@
+package options
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/rose839/IAM/pkg/sets"
+)
+
+var (
+	auditLevels = sets.NewString("none", "metadata", "diff")
+	auditSinks  = sets.NewString("file", "redis", "mysql")
+)
+
+// AuditOptions contains configuration items related to the audit log of mutating requests.
+type AuditOptions struct {
+	Enabled        bool              `json:"enabled"         mapstructure:"enabled"`
+	Level          string            `json:"level"           mapstructure:"level"`
+	ResourceLevels map[string]string `json:"resource-levels" mapstructure:"resource-levels"`
+	Sinks          []string          `json:"sinks"           mapstructure:"sinks"`
+	File           string            `json:"file"            mapstructure:"file"`
+	MaxSize        int               `json:"max-size"        mapstructure:"max-size"`
+	MaxBackups     int               `json:"max-backups"     mapstructure:"max-backups"`
+	MaxAge         int               `json:"max-age"         mapstructure:"max-age"`
+	Compress       bool              `json:"compress"        mapstructure:"compress"`
+	RedisKey       string            `json:"redis-key"       mapstructure:"redis-key"`
+	Retention      time.Duration     `json:"retention"       mapstructure:"retention"`
+	PruneInterval  time.Duration     `json:"prune-interval"  mapstructure:"prune-interval"`
+}
+
+// NewAuditOptions creates an AuditOptions object with default parameters.
+func NewAuditOptions() *AuditOptions {
+	return &AuditOptions{
+		Enabled:        true,
+		Level:          "diff",
+		ResourceLevels: map[string]string{},
+		Sinks:          []string{"file", "mysql"},
+		File:           "/var/log/iam/iam-apiserver-audit.log",
+		MaxSize:        100,
+		MaxBackups:     10,
+		MaxAge:         30,
+		Compress:       false,
+		RedisKey:       "iam-audit-events",
+		Retention:      90 * 24 * time.Hour,
+		PruneInterval:  time.Hour,
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *AuditOptions) Validate() []error {
+	var errs []error
+
+	if !o.Enabled {
+		return errs
+	}
+
+	if !auditLevels.Has(o.Level) {
+		errs = append(errs, fmt.Errorf("--audit.level must be one of %v", auditLevels.List()))
+	}
+
+	for resource, level := range o.ResourceLevels {
+		if !auditLevels.Has(level) {
+			errs = append(errs, fmt.Errorf("--audit.resource-levels level of %s must be one of %v",
+				resource, auditLevels.List()))
+		}
+	}
+
+	for _, sink := range o.Sinks {
+		if !auditSinks.Has(sink) {
+			errs = append(errs, fmt.Errorf("--audit.sinks %s is not one of %v", sink, auditSinks.List()))
+		}
+	}
+
+	if sets.NewString(o.Sinks...).Has("file") && o.File == "" {
+		errs = append(errs, fmt.Errorf("--audit.file can not be empty when the file sink is enabled"))
+	}
+
+	if o.MaxSize <= 0 {
+		errs = append(errs, fmt.Errorf("--audit.max-size must be greater than 0"))
+	}
+
+	if o.MaxBackups < 0 || o.MaxAge < 0 {
+		errs = append(errs, fmt.Errorf("--audit.max-backups and --audit.max-age can not be negative"))
+	}
+
+	if sets.NewString(o.Sinks...).Has("redis") && o.RedisKey == "" {
+		errs = append(errs, fmt.Errorf("--audit.redis-key can not be empty when the redis sink is enabled"))
+	}
+
+	if o.Retention < 0 {
+		errs = append(errs, fmt.Errorf("--audit.retention can not be negative"))
+	}
+
+	if o.Retention > 0 && o.PruneInterval <= 0 {
+		errs = append(errs, fmt.Errorf("--audit.prune-interval must be greater than 0"))
+	}
+
+	return errs
+}
+
+// AddFlags adds flags related to audit for a specific api server to the
+// specified FlagSet.
+func (o *AuditOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.BoolVar(&o.Enabled, "audit.enabled", o.Enabled, "Record every mutating request in the audit log.")
+
+	fs.StringVar(&o.Level, "audit.level", o.Level, ""+
+		"Default audit level of resources, one of none, metadata and diff. metadata records who did what "+
+		"with which outcome, diff also records the redacted changes of the resource.")
+
+	fs.StringToStringVar(&o.ResourceLevels, "audit.resource-levels", o.ResourceLevels, ""+
+		"Audit level per resource kind overriding --audit.level, e.g. secrets=diff,groups=none.")
+
+	fs.StringSliceVar(&o.Sinks, "audit.sinks", o.Sinks, ""+
+		"Sinks audit events are written to, supported: file, redis, mysql. Only events written to "+
+		"mysql can be queried through the api.")
+
+	fs.StringVar(&o.File, "audit.file", o.File, "Path of the json lines audit file.")
+
+	fs.IntVar(&o.MaxSize, "audit.max-size", o.MaxSize, "Maximum size in megabytes of the audit file before it is rotated.")
+
+	fs.IntVar(&o.MaxBackups, "audit.max-backups", o.MaxBackups, ""+
+		"Maximum number of rotated audit files to keep. Set to zero to keep all of them.")
+
+	fs.IntVar(&o.MaxAge, "audit.max-age", o.MaxAge, ""+
+		"Maximum number of days to keep rotated audit files. Set to zero to keep them forever.")
+
+	fs.BoolVar(&o.Compress, "audit.compress", o.Compress, "Compress rotated audit files with gzip.")
+
+	fs.StringVar(&o.RedisKey, "audit.redis-key", o.RedisKey, ""+
+		"Redis list audit events are pushed to, from where they can be shipped to other systems.")
+
+	fs.DurationVar(&o.Retention, "audit.retention", o.Retention, ""+
+		"How long audit events are kept in mysql. Set to zero to keep them forever.")
+
+	fs.DurationVar(&o.PruneInterval, "audit.prune-interval", o.PruneInterval, ""+
+		"Interval of deleting audit events older than --audit.retention from mysql.")
+}
@@ -4,23 +4,34 @@ import (
 	"fmt"
 
 	"github.com/spf13/pflag"
+
+	"github.com/rose839/IAM/internal/pkg/interceptor"
+	"github.com/rose839/IAM/pkg/sets"
 )
 
-// GRPCOptions are for creating an unauthenticated, unauthorized, insecure port.
-// No one should be using these anymore.
+var grpcAuthModes = sets.NewString(interceptor.AuthModeNone, interceptor.AuthModeToken, interceptor.AuthModeMTLS)
+
+// GRPCOptions contains configuration items related to the grpc server and the
+// authentication of its callers.
 type GRPCOptions struct {
-	BindAddress string `json:"bind-address" mapstructure:"bind-address"`
-	BindPort    int    `json:"bind-port" mapstructure:"bind-port"`
-	MaxMsgSize  int    `json:"max-msg-size" mapstructure:"max-msg-size"`
+	BindAddress    string            `json:"bind-address"    mapstructure:"bind-address"`
+	BindPort       int               `json:"bind-port"       mapstructure:"bind-port"`
+	MaxMsgSize     int               `json:"max-msg-size"    mapstructure:"max-msg-size"`
+	AuthMode       string            `json:"auth-mode"       mapstructure:"auth-mode"`
+	Tokens         map[string]string `json:"tokens"          mapstructure:"tokens"`
+	ClientCAFile   string            `json:"client-ca-file"  mapstructure:"client-ca-file"`
+	AllowedCallers []string          `json:"allowed-callers" mapstructure:"allowed-callers"`
 }
 
-// NewGRPCOptions is for creating an unauthenticated, unauthorized, insecure port.
-// No one should be using these anymore.
+// NewGRPCOptions creates a GRPCOptions object with default parameters.
 func NewGRPCOptions() *GRPCOptions {
 	return &GRPCOptions{
-		BindAddress: "0.0.0.0",
-		BindPort:    8081,
-		MaxMsgSize:  4 * 1024 * 1024,
+		BindAddress:    "0.0.0.0",
+		BindPort:       8081,
+		MaxMsgSize:     4 * 1024 * 1024,
+		AuthMode:       interceptor.AuthModeToken,
+		Tokens:         map[string]string{},
+		AllowedCallers: []string{},
 	}
 }
 
@@ -39,6 +50,28 @@ func (s *GRPCOptions) Validate() []error {
 		)
 	}
 
+	if !grpcAuthModes.Has(s.AuthMode) {
+		errors = append(errors, fmt.Errorf("--grpc.auth-mode must be one of %v", grpcAuthModes.List()))
+	}
+
+	if s.AuthMode != interceptor.AuthModeNone && len(s.AllowedCallers) == 0 {
+		errors = append(errors, fmt.Errorf("--grpc.allowed-callers can not be empty when --grpc.auth-mode is %s", s.AuthMode))
+	}
+
+	if s.AuthMode == interceptor.AuthModeToken && len(s.Tokens) == 0 {
+		errors = append(errors, fmt.Errorf("--grpc.tokens can not be empty when --grpc.auth-mode is token"))
+	}
+
+	for caller, token := range s.Tokens {
+		if len(token) < 16 {
+			errors = append(errors, fmt.Errorf("--grpc.tokens token of caller %s must be at least 16 characters", caller))
+		}
+	}
+
+	if s.AuthMode == interceptor.AuthModeMTLS && s.ClientCAFile == "" {
+		errors = append(errors, fmt.Errorf("--grpc.client-ca-file can not be empty when --grpc.auth-mode is mtls"))
+	}
+
 	return errors
 }
 
@@ -55,4 +88,19 @@ func (s *GRPCOptions) AddFlags(fs *pflag.FlagSet) {
 		"port. This is performed by nginx in the default setup. Set to zero to disable.")
 
 	fs.IntVar(&s.MaxMsgSize, "grpc.max-msg-size", s.MaxMsgSize, "gRPC max message size.")
+
+	fs.StringVar(&s.AuthMode, "grpc.auth-mode", s.AuthMode, ""+
+		"Authentication of grpc callers, one of none, token and mtls. token identifies callers by the "+
+		"bearer token in the authorization metadata, mtls by the common name of their client certificate. "+
+		"none accepts any caller and must only be used in development environments.")
+
+	fs.StringToStringVar(&s.Tokens, "grpc.tokens", s.Tokens, ""+
+		"Bearer tokens of the grpc callers keyed by caller name, e.g. iam-authz-server=<token>.")
+
+	fs.StringVar(&s.ClientCAFile, "grpc.client-ca-file", s.ClientCAFile, ""+
+		"File containing the PEM-encoded certificate authorities verifying the grpc client certificates "+
+		"when --grpc.auth-mode is mtls.")
+
+	fs.StringSliceVar(&s.AllowedCallers, "grpc.allowed-callers", s.AllowedCallers, ""+
+		"Names of the callers allowed to call the grpc services, required unless --grpc.auth-mode is none.")
 }
@@ -0,0 +1,48 @@
+package options
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// ImpersonationOptions contains configuration items related to administrators acting as other users.
+type ImpersonationOptions struct {
+	Enabled bool   `json:"enabled" mapstructure:"enabled"`
+	Group   string `json:"group"   mapstructure:"group"`
+}
+
+// NewImpersonationOptions creates an ImpersonationOptions object with default parameters.
+func NewImpersonationOptions() *ImpersonationOptions {
+	return &ImpersonationOptions{
+		Enabled: false,
+		Group:   "impersonators",
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *ImpersonationOptions) Validate() []error {
+	var errs []error
+
+	if o.Enabled && o.Group == "" {
+		errs = append(errs, fmt.Errorf("--impersonation.group can not be empty when impersonation is enabled"))
+	}
+
+	return errs
+}
+
+// AddFlags adds flags related to impersonation for a specific api server to the
+// specified FlagSet.
+func (o *ImpersonationOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.BoolVar(&o.Enabled, "impersonation.enabled", o.Enabled, ""+
+		"Allow administrators to act as another user with the Impersonate-User header.")
+
+	fs.StringVar(&o.Group, "impersonation.group", o.Group, ""+
+		"Group an administrator must be a member of to impersonate users. "+
+		"Other administrators can never be impersonated.")
+}
@@ -0,0 +1,92 @@
+package options
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// LockoutOptions contains configuration items related to login brute-force protection.
+type LockoutOptions struct {
+	Enabled       bool          `json:"enabled"         mapstructure:"enabled"`
+	MaxAttempts   int           `json:"max-attempts"    mapstructure:"max-attempts"`
+	IPMaxAttempts int           `json:"ip-max-attempts" mapstructure:"ip-max-attempts"`
+	FailureWindow time.Duration `json:"failure-window"  mapstructure:"failure-window"`
+	Duration      time.Duration `json:"duration"        mapstructure:"duration"`
+	MaxDuration   time.Duration `json:"max-duration"    mapstructure:"max-duration"`
+}
+
+// NewLockoutOptions creates a LockoutOptions object with default parameters.
+func NewLockoutOptions() *LockoutOptions {
+	return &LockoutOptions{
+		Enabled:       true,
+		MaxAttempts:   5,
+		IPMaxAttempts: 20,
+		FailureWindow: 15 * time.Minute,
+		Duration:      1 * time.Minute,
+		MaxDuration:   1 * time.Hour,
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *LockoutOptions) Validate() []error {
+	var errs []error
+
+	if !o.Enabled {
+		return errs
+	}
+
+	if o.MaxAttempts < 0 {
+		errs = append(errs, fmt.Errorf("--lockout.max-attempts can not be negative"))
+	}
+
+	if o.IPMaxAttempts < 0 {
+		errs = append(errs, fmt.Errorf("--lockout.ip-max-attempts can not be negative"))
+	}
+
+	if o.FailureWindow <= 0 {
+		errs = append(errs, fmt.Errorf("--lockout.failure-window must be greater than 0"))
+	}
+
+	if o.Duration <= 0 {
+		errs = append(errs, fmt.Errorf("--lockout.duration must be greater than 0"))
+	}
+
+	if o.MaxDuration < o.Duration {
+		errs = append(errs, fmt.Errorf("--lockout.max-duration must not be less than --lockout.duration"))
+	}
+
+	return errs
+}
+
+// AddFlags adds flags related to login lockout for a specific api server to the
+// specified FlagSet.
+func (o *LockoutOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.BoolVar(&o.Enabled, "lockout.enabled", o.Enabled,
+		"Lock out usernames and client addresses after too many failed login attempts.")
+
+	fs.IntVar(&o.MaxAttempts, "lockout.max-attempts", o.MaxAttempts, ""+
+		"Number of failed login attempts for a username within --lockout.failure-window "+
+		"before it is locked. Set to zero to disable per-user lockout.")
+
+	fs.IntVar(&o.IPMaxAttempts, "lockout.ip-max-attempts", o.IPMaxAttempts, ""+
+		"Number of failed login attempts from a client address within --lockout.failure-window "+
+		"before it is locked. Set to zero to disable per-address lockout.")
+
+	fs.DurationVar(&o.FailureWindow, "lockout.failure-window", o.FailureWindow,
+		"Time window in which failed login attempts are counted.")
+
+	fs.DurationVar(&o.Duration, "lockout.duration", o.Duration, ""+
+		"Duration of the first lockout. Every subsequent lockout doubles it, "+
+		"up to --lockout.max-duration.")
+
+	fs.DurationVar(&o.MaxDuration, "lockout.max-duration", o.MaxDuration, ""+
+		"Upper bound of a single lockout. The backoff is reset once no lockout "+
+		"happened for this long.")
+}
@@ -0,0 +1,101 @@
+package options
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+
+	"github.com/rose839/IAM/pkg/notifier"
+)
+
+// NotifierOptions contains configuration items related to user notifications.
+type NotifierOptions struct {
+	Type         string `json:"type"          mapstructure:"type"`
+	File         string `json:"file"          mapstructure:"file"`
+	SMTPHost     string `json:"smtp-host"     mapstructure:"smtp-host"`
+	SMTPPort     int    `json:"smtp-port"     mapstructure:"smtp-port"`
+	SMTPUsername string `json:"smtp-username" mapstructure:"smtp-username"`
+	SMTPPassword string `json:"smtp-password" mapstructure:"smtp-password"`
+	From         string `json:"from"          mapstructure:"from"`
+}
+
+// NewNotifierOptions creates a NotifierOptions object with default parameters.
+func NewNotifierOptions() *NotifierOptions {
+	return &NotifierOptions{
+		Type:         "log",
+		File:         "",
+		SMTPHost:     "127.0.0.1",
+		SMTPPort:     25,
+		SMTPUsername: "",
+		SMTPPassword: "",
+		From:         "iam@localhost",
+	}
+}
+
+// NewNotifier create notifier with the given options.
+func (o *NotifierOptions) NewNotifier() (notifier.Notifier, error) {
+	switch o.Type {
+	case "smtp":
+		return notifier.NewSMTPNotifier(&notifier.SMTPConfig{
+			Host:     o.SMTPHost,
+			Port:     o.SMTPPort,
+			Username: o.SMTPUsername,
+			Password: o.SMTPPassword,
+			From:     o.From,
+		}), nil
+	case "file":
+		return notifier.NewFileNotifier(o.File), nil
+	case "log":
+		return notifier.NewLogNotifier(), nil
+	default:
+		return nil, fmt.Errorf("unknown notifier type `%s`", o.Type)
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *NotifierOptions) Validate() []error {
+	var errs []error
+
+	switch o.Type {
+	case "smtp":
+		if o.SMTPHost == "" {
+			errs = append(errs, fmt.Errorf("--notifier.smtp-host must be set when --notifier.type is smtp"))
+		}
+
+		if o.SMTPPort <= 0 || o.SMTPPort > 65535 {
+			errs = append(errs, fmt.Errorf("--notifier.smtp-port %v must be between 1 and 65535, inclusive", o.SMTPPort))
+		}
+
+		if o.From == "" {
+			errs = append(errs, fmt.Errorf("--notifier.from must be set when --notifier.type is smtp"))
+		}
+	case "file":
+		if o.File == "" {
+			errs = append(errs, fmt.Errorf("--notifier.file must be set when --notifier.type is file"))
+		}
+	case "log":
+	default:
+		errs = append(errs, fmt.Errorf("--notifier.type must be one of smtp, file or log, got `%s`", o.Type))
+	}
+
+	return errs
+}
+
+// AddFlags adds flags related to notifications for a specific api server to the
+// specified FlagSet.
+func (o *NotifierOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.StringVar(&o.Type, "notifier.type", o.Type, ""+
+		"Notifier used to deliver password reset and email verification messages, one of smtp, file or log.")
+
+	fs.StringVar(&o.File, "notifier.file", o.File, "File messages are appended to when --notifier.type is file.")
+	fs.StringVar(&o.SMTPHost, "notifier.smtp-host", o.SMTPHost, "SMTP server host.")
+	fs.IntVar(&o.SMTPPort, "notifier.smtp-port", o.SMTPPort, "SMTP server port.")
+	fs.StringVar(&o.SMTPUsername, "notifier.smtp-username", o.SMTPUsername, "Username for SMTP authentication.")
+	fs.StringVar(&o.SMTPPassword, "notifier.smtp-password", o.SMTPPassword, "Password for SMTP authentication.")
+	fs.StringVar(&o.From, "notifier.from", o.From, "Sender address of emails.")
+}
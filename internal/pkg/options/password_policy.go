@@ -0,0 +1,137 @@
+package options
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/rose839/IAM/pkg/sets"
+	"github.com/rose839/IAM/pkg/validation"
+)
+
+// bcrypt ignores everything after the 72nd byte.
+const maxPasswordLength = 72
+
+// PasswordPolicyOptions contains configuration items related to user passwords.
+type PasswordPolicyOptions struct {
+	MinLength        int           `json:"min-length"        mapstructure:"min-length"`
+	MaxLength        int           `json:"max-length"        mapstructure:"max-length"`
+	RequireUppercase bool          `json:"require-uppercase" mapstructure:"require-uppercase"`
+	RequireLowercase bool          `json:"require-lowercase" mapstructure:"require-lowercase"`
+	RequireDigit     bool          `json:"require-digit"     mapstructure:"require-digit"`
+	RequireSpecial   bool          `json:"require-special"   mapstructure:"require-special"`
+	DisallowUsername bool          `json:"disallow-username" mapstructure:"disallow-username"`
+	DenylistFile     string        `json:"denylist-file"     mapstructure:"denylist-file"`
+	HistorySize      int           `json:"history-size"      mapstructure:"history-size"`
+	MaxAge           time.Duration `json:"max-age"           mapstructure:"max-age"`
+}
+
+// NewPasswordPolicyOptions creates a PasswordPolicyOptions object with default parameters.
+func NewPasswordPolicyOptions() *PasswordPolicyOptions {
+	defaults := validation.DefaultPasswordPolicy()
+
+	return &PasswordPolicyOptions{
+		MinLength:        defaults.MinLength,
+		MaxLength:        defaults.MaxLength,
+		RequireUppercase: defaults.RequireUpper,
+		RequireLowercase: defaults.RequireLower,
+		RequireDigit:     defaults.RequireNumber,
+		RequireSpecial:   defaults.RequireSpecial,
+		DisallowUsername: defaults.DisallowUsername,
+		DenylistFile:     "",
+		HistorySize:      5,
+		MaxAge:           0,
+	}
+}
+
+// NewPolicy create a password policy with the given options, the denylist file is loaded if set.
+func (o *PasswordPolicyOptions) NewPolicy() (*validation.PasswordPolicy, error) {
+	denylist := sets.NewString()
+	if o.DenylistFile != "" {
+		var err error
+		if denylist, err = validation.LoadPasswordDenylist(o.DenylistFile); err != nil {
+			return nil, fmt.Errorf("load password denylist failed: %w", err)
+		}
+	}
+
+	return &validation.PasswordPolicy{
+		MinLength:        o.MinLength,
+		MaxLength:        o.MaxLength,
+		RequireUpper:     o.RequireUppercase,
+		RequireLower:     o.RequireLowercase,
+		RequireNumber:    o.RequireDigit,
+		RequireSpecial:   o.RequireSpecial,
+		DisallowUsername: o.DisallowUsername,
+		Denylist:         denylist,
+		HistorySize:      o.HistorySize,
+		MaxAge:           o.MaxAge,
+	}, nil
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *PasswordPolicyOptions) Validate() []error {
+	var errs []error
+
+	if o.MinLength < 1 {
+		errs = append(errs, fmt.Errorf("--password-policy.min-length must be greater than 0"))
+	}
+
+	if o.MaxLength < o.MinLength || o.MaxLength > maxPasswordLength {
+		errs = append(errs, fmt.Errorf("--password-policy.max-length must be between --password-policy.min-length and %d",
+			maxPasswordLength))
+	}
+
+	if o.HistorySize < 0 {
+		errs = append(errs, fmt.Errorf("--password-policy.history-size can not be negative"))
+	}
+
+	if o.MaxAge < 0 {
+		errs = append(errs, fmt.Errorf("--password-policy.max-age can not be negative"))
+	}
+
+	if o.DenylistFile != "" {
+		if _, err := os.Stat(o.DenylistFile); err != nil {
+			errs = append(errs, fmt.Errorf("--password-policy.denylist-file %s can not be read: %w", o.DenylistFile, err))
+		}
+	}
+
+	return errs
+}
+
+// AddFlags adds flags related to password policy for a specific api server to the
+// specified FlagSet.
+func (o *PasswordPolicyOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.IntVar(&o.MinLength, "password-policy.min-length", o.MinLength, "Minimum number of characters of a password.")
+	fs.IntVar(&o.MaxLength, "password-policy.max-length", o.MaxLength, "Maximum number of characters of a password.")
+
+	fs.BoolVar(&o.RequireUppercase, "password-policy.require-uppercase", o.RequireUppercase,
+		"Require at least one uppercase letter in a password.")
+
+	fs.BoolVar(&o.RequireLowercase, "password-policy.require-lowercase", o.RequireLowercase,
+		"Require at least one lowercase letter in a password.")
+
+	fs.BoolVar(&o.RequireDigit, "password-policy.require-digit", o.RequireDigit,
+		"Require at least one digit in a password.")
+
+	fs.BoolVar(&o.RequireSpecial, "password-policy.require-special", o.RequireSpecial,
+		"Require at least one punctuation or symbol character in a password.")
+
+	fs.BoolVar(&o.DisallowUsername, "password-policy.disallow-username", o.DisallowUsername,
+		"Reject passwords which contain the username.")
+
+	fs.StringVar(&o.DenylistFile, "password-policy.denylist-file", o.DenylistFile, ""+
+		"File of common passwords which are rejected, one password per line.")
+
+	fs.IntVar(&o.HistorySize, "password-policy.history-size", o.HistorySize, ""+
+		"Number of previous passwords of a user which can not be reused. Set to zero to disable.")
+
+	fs.DurationVar(&o.MaxAge, "password-policy.max-age", o.MaxAge, ""+
+		"Maximum age of a password, users must change an expired password before login. Set to zero to disable.")
+}
@@ -0,0 +1,87 @@
+package options
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// RateLimitOptions contains configuration items related to the request rate limits of clients.
+type RateLimitOptions struct {
+	Enabled bool              `json:"enabled" mapstructure:"enabled"`
+	Quota   string            `json:"quota"   mapstructure:"quota"`
+	Routes  map[string]string `json:"routes"  mapstructure:"routes"`
+}
+
+// NewRateLimitOptions creates a RateLimitOptions object with default parameters.
+func NewRateLimitOptions() *RateLimitOptions {
+	return &RateLimitOptions{
+		Enabled: true,
+		Quota:   "600/1m",
+		Routes:  map[string]string{},
+	}
+}
+
+// ParseRateLimitQuota parses a quota like 600/1m into the number of requests and the window.
+func ParseRateLimitQuota(quota string) (int, time.Duration, error) {
+	parts := strings.SplitN(quota, "/", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("quota `%s` is not in <requests>/<window> format", quota)
+	}
+
+	requests, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || requests <= 0 {
+		return 0, 0, fmt.Errorf("requests of quota `%s` must be a positive integer", quota)
+	}
+
+	window, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+	if err != nil || window < time.Second {
+		return 0, 0, fmt.Errorf("window of quota `%s` must be a duration of at least 1s", quota)
+	}
+
+	return requests, window, nil
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *RateLimitOptions) Validate() []error {
+	var errs []error
+
+	if !o.Enabled {
+		return errs
+	}
+
+	if _, _, err := ParseRateLimitQuota(o.Quota); err != nil {
+		errs = append(errs, fmt.Errorf("--ratelimit.quota: %w", err))
+	}
+
+	for route, quota := range o.Routes {
+		if _, _, err := ParseRateLimitQuota(quota); err != nil {
+			errs = append(errs, fmt.Errorf("--ratelimit.routes %s: %w", route, err))
+		}
+	}
+
+	return errs
+}
+
+// AddFlags adds flags related to rate limiting for a specific api server to the
+// specified FlagSet.
+func (o *RateLimitOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.BoolVar(&o.Enabled, "ratelimit.enabled", o.Enabled, ""+
+		"Limit the request rate of every client, identified by the authenticated user or the client ip.")
+
+	fs.StringVar(&o.Quota, "ratelimit.quota", o.Quota, ""+
+		"Requests a client can send in a sliding window across all routes without an own quota, "+
+		"in <requests>/<window> format.")
+
+	fs.StringToStringVar(&o.Routes, "ratelimit.routes", o.Routes, ""+
+		"Quotas of single routes counted separately from --ratelimit.quota, keyed by `<METHOD> <route>` "+
+		"or `<route>` for all methods, e.g. \"POST /login=10/1m,/v1/users=100/1m\".")
+}
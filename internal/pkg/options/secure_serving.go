@@ -3,9 +3,12 @@ package options
 import (
 	"fmt"
 	"net"
+	"os"
 	"path"
 
 	"github.com/rose839/IAM/internal/pkg/server"
+	"github.com/rose839/IAM/pkg/certutil"
+	"github.com/rose839/IAM/pkg/log"
 	"github.com/spf13/pflag"
 )
 
@@ -39,9 +42,12 @@ type GeneratableKeyCert struct {
 
 	// CertDirectory specifies a directory to write generated certificates to if CertFile/KeyFile aren't explicitly set.
 	// PairName is used to determine the filenames within CertDirectory.
-	// If CertDirectory and PairName are not set, an in-memory certificate will be generated.
 	CertDirectory string `json:"cert-dir"  mapstructure:"cert-dir"`
 
+	// GenerateCert generates a self-signed certificate into CertDirectory when the files
+	// do not exist, for development environments only.
+	GenerateCert bool `json:"generate-cert" mapstructure:"generate-cert"`
+
 	// PairName is the name which will be used with CertDirectory to make a cert and key filenames.
 	// It becomes CertDirectory/PairName.crt and CertDirectory/PairName.key
 	PairName string `json:"pair-name" mapstructure:"pair-name"`
@@ -118,6 +124,10 @@ func (s *SecureServingOptions) AddFlags(fs *pflag.FlagSet) {
 		"If --secure.tls.cert-key.cert-file and --secure.tls.cert-key.private-key-file are provided, "+
 		"this flag will be ignored.")
 
+	fs.BoolVar(&s.ServerCert.GenerateCert, "secure.tls.generate-cert", s.ServerCert.GenerateCert, ""+
+		"Generate a self-signed cert into --secure.tls.cert-dir when the cert files do not exist. "+
+		"For development environments only, the clients must trust the generated certificate authority.")
+
 	fs.StringVar(&s.ServerCert.PairName, "secure.tls.pair-name", s.ServerCert.PairName, ""+
 		"The name which will be used with --secure.tls.cert-dir to make a cert and key filenames. "+
 		"It becomes <cert-dir>/<pair-name>.crt and <cert-dir>/<pair-name>.key")
@@ -131,6 +141,8 @@ func (s *SecureServingOptions) AddFlags(fs *pflag.FlagSet) {
 			"File containing the default x509 private key matching --secure.tls.cert-key.cert-file.")
 }
 
+// Complete sets the certificate files to the pair in CertDirectory when they are not set
+// explicitly.
 func (s *SecureServingOptions) Complete() error {
 	if s == nil || s.BindPort == 0 {
 		return nil
@@ -141,16 +153,72 @@ func (s *SecureServingOptions) Complete() error {
 		return nil
 	}
 
-	if len(s.ServerCert.CertDirectory) > 0 {
-		if len(s.ServerCert.PairName) > 0 {
-			if len(s.ServerCert.PairName) == 0 {
-				return fmt.Errorf("--secure.tls.pair-name is required if --secure.tls.cert-dir is set")
-			}
-		}
-		keyCert.CertFile = path.Join(s.ServerCert.CertDirectory, s.ServerCert.PairName+".crt")
-		keyCert.KeyFile = path.Join(s.ServerCert.CertDirectory, s.ServerCert.PairName+".key")
+	if len(s.ServerCert.CertDirectory) == 0 {
+		return nil
+	}
+
+	if len(s.ServerCert.PairName) == 0 {
+		return fmt.Errorf("--secure.tls.pair-name is required if --secure.tls.cert-dir is set")
+	}
+	keyCert.CertFile = path.Join(s.ServerCert.CertDirectory, s.ServerCert.PairName+".crt")
+	keyCert.KeyFile = path.Join(s.ServerCert.CertDirectory, s.ServerCert.PairName+".key")
+
+	return nil
+}
+
+// MaybeGenerateCertKey generates a self-signed certificate authority and serving certificate
+// into the pair in CertDirectory unless the files exist, when GenerateCert is set for
+// development environments. The certificate authority is written to <cert-dir>/<pair-name>-ca.crt
+// for the clients. Explicitly set certificate files are never generated. It must be called
+// after Complete.
+func (s *SecureServingOptions) MaybeGenerateCertKey() error {
+	if s == nil || !s.ServerCert.GenerateCert || s.BindPort == 0 || len(s.ServerCert.CertDirectory) == 0 {
+		return nil
+	}
+
+	keyCert := s.ServerCert.CertKey
+	if keyCert.CertFile != path.Join(s.ServerCert.CertDirectory, s.ServerCert.PairName+".crt") ||
+		keyCert.KeyFile != path.Join(s.ServerCert.CertDirectory, s.ServerCert.PairName+".key") {
+		return nil
+	}
+
+	canRead, err := certutil.CanReadCertAndKey(keyCert.CertFile, keyCert.KeyFile)
+	if err != nil || canRead {
+		return err
+	}
+
+	alternateIPs := []net.IP{net.ParseIP("127.0.0.1"), net.IPv6loopback}
+	if ip := net.ParseIP(s.BindAddress); ip != nil && !ip.IsUnspecified() && !ip.IsLoopback() {
+		alternateIPs = append(alternateIPs, ip)
+	}
+
+	alternateDNS := []string{}
+	if hostname, err := os.Hostname(); err == nil && hostname != "localhost" {
+		alternateDNS = append(alternateDNS, hostname)
+	}
+
+	cert, key, ca, err := certutil.GenerateSelfSignedCertKey("localhost", alternateIPs, alternateDNS)
+	if err != nil {
+		return fmt.Errorf("unable to generate self signed cert: %w", err)
 	}
 
+	caFile := path.Join(s.ServerCert.CertDirectory, s.ServerCert.PairName+"-ca.crt")
+	if err := certutil.WriteCert(caFile, ca); err != nil {
+		return err
+	}
+
+	if err := certutil.WriteKey(keyCert.KeyFile, key); err != nil {
+		return err
+	}
+
+	// the certificate is written last, the pair is complete once it exists
+	if err := certutil.WriteCert(keyCert.CertFile, cert); err != nil {
+		return err
+	}
+
+	log.Infof("Generated self signed cert %s and key %s, clients trust the authority in %s",
+		keyCert.CertFile, keyCert.KeyFile, caFile)
+
 	return nil
 }
 
@@ -0,0 +1,40 @@
+package options
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSecureServingOptions_MaybeGenerateCertKey(t *testing.T) {
+	tests := []struct {
+		name         string
+		generateCert bool
+		wantFiles    bool
+	}{
+		{name: "disabled", generateCert: false, wantFiles: false},
+		{name: "enabled", generateCert: true, wantFiles: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSecureServingOptions()
+			s.ServerCert.CertDirectory = t.TempDir()
+			s.ServerCert.GenerateCert = tt.generateCert
+			if err := s.Complete(); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := s.MaybeGenerateCertKey(); err != nil {
+				t.Fatalf("MaybeGenerateCertKey() error = %v", err)
+			}
+
+			for _, name := range []string{"iam.crt", "iam.key", "iam-ca.crt"} {
+				_, err := os.Stat(filepath.Join(s.ServerCert.CertDirectory, name))
+				if exists := err == nil; exists != tt.wantFiles {
+					t.Errorf("%s exists = %v, want %v", name, exists, tt.wantFiles)
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package options
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/rose839/IAM/internal/pkg/middleware"
 	"github.com/rose839/IAM/internal/pkg/server"
 	"github.com/spf13/pflag"
 )
@@ -10,6 +14,7 @@ type ServerRunOptions struct {
 	Mode        string   `json:"mode" mapstructure:"mode"`
 	Healthz     bool     `json:"healthz" mapstructure:"healthz"`
 	Middlewares []string `json:"middlewares" mapstructure:"middlewares"`
+	CorsOrigins []string `json:"cors-origins" mapstructure:"cors-origins"`
 }
 
 // NewServerRunOptions creates a new ServerRunOptions object with default parameters.
@@ -20,6 +25,7 @@ func NewServerRunOptions() *ServerRunOptions {
 		Mode:        defaults.Mode,
 		Healthz:     defaults.Healthz,
 		Middlewares: defaults.Middlewares,
+		CorsOrigins: defaults.CorsOrigins,
 	}
 }
 
@@ -28,6 +34,7 @@ func (s *ServerRunOptions) ApplyTo(c *server.Config) error {
 	c.Mode = s.Mode
 	c.Healthz = s.Healthz
 	c.Middlewares = s.Middlewares
+	c.CorsOrigins = s.CorsOrigins
 
 	return nil
 }
@@ -36,6 +43,21 @@ func (s *ServerRunOptions) ApplyTo(c *server.Config) error {
 func (s *ServerRunOptions) Validate() []error {
 	errors := []error{}
 
+	for _, m := range s.Middlewares {
+		if _, ok := middleware.Middlewares[m]; !ok {
+			errors = append(errors, fmt.Errorf("--server.middlewares: unknown middleware `%s`", m))
+		}
+	}
+
+	for _, origin := range s.CorsOrigins {
+		if strings.Count(origin, "*") > 1 {
+			errors = append(errors, fmt.Errorf("--server.cors-origins: origin `%s` has more than one *", origin))
+		}
+	}
+	if err := middleware.CorsConfig(s.CorsOrigins).Validate(); err != nil {
+		errors = append(errors, fmt.Errorf("--server.cors-origins: %w", err))
+	}
+
 	return errors
 }
 
@@ -45,8 +67,12 @@ func (s *ServerRunOptions) AddFlags(fs *pflag.FlagSet) {
 		"Start the server in a specified server mode. Supported server mode: debug, test, release.")
 
 	fs.BoolVar(&s.Healthz, "server.healthz", s.Healthz, ""+
-		"Add self readiness check and install /healthz router.")
+		"Add self readiness check and install the /livez, /readyz and /healthz routers.")
 
 	fs.StringSliceVar(&s.Middlewares, "server.middlewares", s.Middlewares, ""+
 		"List of allowed middlewares for server, comma separated. If this list is empty default middlewares will be used.")
+
+	fs.StringSliceVar(&s.CorsOrigins, "server.cors-origins", s.CorsOrigins, ""+
+		"List of origins allowed by the cors middleware, comma separated. An origin may contain one * wildcard, "+
+		"like https://*.example.com, * allows all origins.")
 }
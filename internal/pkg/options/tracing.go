@@ -0,0 +1,94 @@
+package options
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/rose839/IAM/internal/pkg/tracing"
+	"github.com/rose839/IAM/pkg/sets"
+)
+
+var tracingExporters = sets.NewString(tracing.ExporterStdout, tracing.ExporterFile)
+
+// TracingOptions contains configuration items related to the OpenTelemetry tracing.
+type TracingOptions struct {
+	Enabled       bool    `json:"enabled"        mapstructure:"enabled"`
+	ServiceName   string  `json:"service-name"   mapstructure:"service-name"`
+	Exporter      string  `json:"exporter"       mapstructure:"exporter"`
+	File          string  `json:"file"           mapstructure:"file"`
+	SamplingRatio float64 `json:"sampling-ratio" mapstructure:"sampling-ratio"`
+}
+
+// NewTracingOptions creates a TracingOptions object with default parameters.
+func NewTracingOptions() *TracingOptions {
+	return &TracingOptions{
+		Enabled:       false,
+		ServiceName:   "iam-apiserver",
+		Exporter:      tracing.ExporterStdout,
+		File:          "",
+		SamplingRatio: 1,
+	}
+}
+
+// NewTracerProvider create a tracer provider with the given options.
+func (o *TracingOptions) NewTracerProvider() (*sdktrace.TracerProvider, error) {
+	return tracing.NewTracerProvider(&tracing.Config{
+		ServiceName:   o.ServiceName,
+		Exporter:      o.Exporter,
+		File:          o.File,
+		SamplingRatio: o.SamplingRatio,
+	})
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *TracingOptions) Validate() []error {
+	var errs []error
+
+	if !o.Enabled {
+		return errs
+	}
+
+	if o.ServiceName == "" {
+		errs = append(errs, fmt.Errorf("--tracing.service-name can not be empty"))
+	}
+
+	if !tracingExporters.Has(o.Exporter) {
+		errs = append(errs, fmt.Errorf("--tracing.exporter must be one of %v", tracingExporters.List()))
+	}
+
+	if o.Exporter == tracing.ExporterFile && o.File == "" {
+		errs = append(errs, fmt.Errorf("--tracing.file must be set when --tracing.exporter is file"))
+	}
+
+	if o.SamplingRatio < 0 || o.SamplingRatio > 1 {
+		errs = append(errs, fmt.Errorf("--tracing.sampling-ratio %v must be between 0 and 1, inclusive", o.SamplingRatio))
+	}
+
+	return errs
+}
+
+// AddFlags adds flags related to tracing for a specific api server to the
+// specified FlagSet.
+func (o *TracingOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.BoolVar(&o.Enabled, "tracing.enabled", o.Enabled, ""+
+		"Trace the requests with OpenTelemetry, as part of the traces of the callers given by "+
+		"the W3C traceparent header.")
+
+	fs.StringVar(&o.ServiceName, "tracing.service-name", o.ServiceName, "Name of the service reported with the spans.")
+
+	fs.StringVar(&o.Exporter, "tracing.exporter", o.Exporter, ""+
+		"Exporter the spans are written by as json lines, one of stdout or file.")
+
+	fs.StringVar(&o.File, "tracing.file", o.File, "File spans are appended to when --tracing.exporter is file.")
+
+	fs.Float64Var(&o.SamplingRatio, "tracing.sampling-ratio", o.SamplingRatio, ""+
+		"Ratio of the traces started by the service which are sampled, from 0 to 1. "+
+		"The traces of callers are sampled as decided by the callers.")
+}
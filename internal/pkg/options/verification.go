@@ -0,0 +1,53 @@
+package options
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// VerificationOptions contains configuration items related to password reset and
+// email verification tokens.
+type VerificationOptions struct {
+	PasswordResetTTL time.Duration `json:"password-reset-ttl" mapstructure:"password-reset-ttl"`
+	EmailTTL         time.Duration `json:"email-ttl"          mapstructure:"email-ttl"`
+}
+
+// NewVerificationOptions creates a VerificationOptions object with default parameters.
+func NewVerificationOptions() *VerificationOptions {
+	return &VerificationOptions{
+		PasswordResetTTL: 30 * time.Minute,
+		EmailTTL:         24 * time.Hour,
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *VerificationOptions) Validate() []error {
+	var errs []error
+
+	if o.PasswordResetTTL <= 0 {
+		errs = append(errs, fmt.Errorf("--verification.password-reset-ttl must be greater than 0"))
+	}
+
+	if o.EmailTTL <= 0 {
+		errs = append(errs, fmt.Errorf("--verification.email-ttl must be greater than 0"))
+	}
+
+	return errs
+}
+
+// AddFlags adds flags related to verification tokens for a specific api server to the
+// specified FlagSet.
+func (o *VerificationOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.DurationVar(&o.PasswordResetTTL, "verification.password-reset-ttl", o.PasswordResetTTL,
+		"How long a password reset token stays valid.")
+
+	fs.DurationVar(&o.EmailTTL, "verification.email-ttl", o.EmailTTL,
+		"How long an email verification token stays valid.")
+}
@@ -0,0 +1,87 @@
+package options
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// WebhookOptions contains configuration items related to the delivery of webhooks.
+type WebhookOptions struct {
+	Enabled     bool          `json:"enabled"      mapstructure:"enabled"`
+	Workers     int           `json:"workers"      mapstructure:"workers"`
+	QueueSize   int           `json:"queue-size"   mapstructure:"queue-size"`
+	Timeout     time.Duration `json:"timeout"      mapstructure:"timeout"`
+	MaxAttempts int           `json:"max-attempts" mapstructure:"max-attempts"`
+	MinBackoff  time.Duration `json:"min-backoff"  mapstructure:"min-backoff"`
+	MaxBackoff  time.Duration `json:"max-backoff"  mapstructure:"max-backoff"`
+}
+
+// NewWebhookOptions creates a WebhookOptions object with default parameters.
+func NewWebhookOptions() *WebhookOptions {
+	return &WebhookOptions{
+		Enabled:     true,
+		Workers:     4,
+		QueueSize:   1000,
+		Timeout:     10 * time.Second,
+		MaxAttempts: 5,
+		MinBackoff:  time.Second,
+		MaxBackoff:  5 * time.Minute,
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *WebhookOptions) Validate() []error {
+	var errs []error
+
+	if !o.Enabled {
+		return errs
+	}
+
+	if o.Workers <= 0 || o.QueueSize <= 0 {
+		errs = append(errs, fmt.Errorf("--webhook.workers and --webhook.queue-size must be greater than 0"))
+	}
+
+	if o.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("--webhook.timeout must be greater than 0"))
+	}
+
+	if o.MaxAttempts <= 0 {
+		errs = append(errs, fmt.Errorf("--webhook.max-attempts must be greater than 0"))
+	}
+
+	if o.MinBackoff <= 0 || o.MaxBackoff < o.MinBackoff {
+		errs = append(errs, fmt.Errorf("--webhook.min-backoff must be greater than 0 and not greater than --webhook.max-backoff"))
+	}
+
+	return errs
+}
+
+// AddFlags adds flags related to webhooks for a specific api server to the
+// specified FlagSet.
+func (o *WebhookOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.BoolVar(&o.Enabled, "webhook.enabled", o.Enabled, ""+
+		"Deliver resource change events to the registered webhooks. Events are taken from the audit "+
+		"log, so resources audited at level none do not trigger webhooks.")
+
+	fs.IntVar(&o.Workers, "webhook.workers", o.Workers, "Number of workers delivering webhooks concurrently.")
+
+	fs.IntVar(&o.QueueSize, "webhook.queue-size", o.QueueSize, ""+
+		"Maximum number of queued events and deliveries, events are dropped when the queue is full.")
+
+	fs.DurationVar(&o.Timeout, "webhook.timeout", o.Timeout, "Timeout of a single delivery attempt.")
+
+	fs.IntVar(&o.MaxAttempts, "webhook.max-attempts", o.MaxAttempts, ""+
+		"Maximum number of attempts to deliver an event before the delivery is marked as failed.")
+
+	fs.DurationVar(&o.MinBackoff, "webhook.min-backoff", o.MinBackoff, ""+
+		"Delay before the first retry, it is doubled for every further retry.")
+
+	fs.DurationVar(&o.MaxBackoff, "webhook.max-backoff", o.MaxBackoff, "Maximum delay between two retries.")
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"sync"
+
+	"github.com/rose839/IAM/pkg/log"
+	"github.com/rose839/IAM/pkg/reload"
+	"github.com/rose839/IAM/pkg/reload/reloadmanagers/filewatcher"
+)
+
+// Certificate is a tls certificate loaded from a pair of files, which can be reloaded
+// while serving. Set GetCertificate as the tls.Config.GetCertificate of the listeners
+// sharing it.
+type Certificate struct {
+	mu      sync.RWMutex
+	certKey CertKey
+	cert    *tls.Certificate
+
+	// watchers of the certificate files, nil until Watch is called
+	watchers []*filewatcher.FileWatcherManager
+}
+
+// NewCertificate loads the certificate from the given files.
+func NewCertificate(certKey CertKey) (*Certificate, error) {
+	c := &Certificate{}
+	if err := c.Reload(certKey); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Reload loads the certificate from the given files, the current certificate is kept
+// when they can not be loaded. When the certificate is watched and the files differ from
+// the current ones, the given files are watched from now on.
+func (c *Certificate) Reload(certKey CertKey) error {
+	cert, err := tls.LoadX509KeyPair(certKey.CertFile, certKey.KeyFile)
+	if err != nil {
+		return fmt.Errorf("load certificate %s and key %s: %w", certKey.CertFile, certKey.KeyFile, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	moved := certKey != c.certKey
+	c.certKey = certKey
+	c.cert = &cert
+
+	if moved && c.watchers != nil {
+		if err := c.watch(); err != nil {
+			return fmt.Errorf("watch certificate %s and key %s: %w", certKey.CertFile, certKey.KeyFile, err)
+		}
+	}
+
+	return nil
+}
+
+// CertKey returns the files the certificate is loaded from.
+func (c *Certificate) CertKey() CertKey {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.certKey
+}
+
+// GetCertificate returns the current certificate for every tls handshake.
+func (c *Certificate) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.cert, nil
+}
+
+// Watch reloads the certificate every time its files change, like when they are renewed.
+// The files the certificate is currently loaded from are watched, a certificate which can
+// not be loaded is logged and the current one is kept.
+func (c *Certificate) Watch() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.watch()
+}
+
+// watch stops the current watchers and watches the current files, c.mu must be held.
+func (c *Certificate) watch() error {
+	for _, watcher := range c.watchers {
+		_ = watcher.Stop()
+	}
+	c.watchers = []*filewatcher.FileWatcherManager{
+		filewatcher.NewFileWatcherManager(c.certKey.CertFile),
+		filewatcher.NewFileWatcherManager(c.certKey.KeyFile),
+	}
+
+	gr := reload.New()
+	for _, watcher := range c.watchers {
+		gr.AddReloadManager(watcher)
+	}
+	gr.AddReloadCallback(reload.ReloadFunc(func(string) error {
+		certKey := c.CertKey()
+		if err := c.Reload(certKey); err != nil {
+			return err
+		}
+
+		log.Infof("Reloaded certificate %s and key %s", certKey.CertFile, certKey.KeyFile)
+
+		return nil
+	}))
+	gr.SetErrorHandler(reload.ErrorFunc(func(err error) {
+		log.Warnf("Reload certificate failed, keep the current one: %s", err.Error())
+	}))
+
+	return gr.Start()
+}
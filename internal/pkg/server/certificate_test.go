@@ -0,0 +1,109 @@
+package server
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rose839/IAM/pkg/certutil"
+)
+
+func writeCertKey(t *testing.T, certKey CertKey) []byte {
+	cert, key, _, err := certutil.GenerateSelfSignedCertKey("localhost", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := certutil.WriteKey(certKey.KeyFile, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := certutil.WriteCert(certKey.CertFile, cert); err != nil {
+		t.Fatal(err)
+	}
+
+	return cert
+}
+
+func servedCert(t *testing.T, c *Certificate) []byte {
+	cert, err := c.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cert.Certificate[0]
+}
+
+func TestCertificateWatch(t *testing.T) {
+	dir := t.TempDir()
+	certKey := CertKey{CertFile: filepath.Join(dir, "iam.crt"), KeyFile: filepath.Join(dir, "iam.key")}
+	writeCertKey(t, certKey)
+
+	c, err := NewCertificate(certKey)
+	if err != nil {
+		t.Fatalf("NewCertificate() error = %v", err)
+	}
+	if err := c.Watch(); err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	old := servedCert(t, c)
+
+	writeCertKey(t, certKey)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for bytes.Equal(servedCert(t, c), old) {
+		if time.Now().After(deadline) {
+			t.Fatal("certificate not reloaded after its files changed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCertificateReloadKeepsCurrent(t *testing.T) {
+	dir := t.TempDir()
+	certKey := CertKey{CertFile: filepath.Join(dir, "iam.crt"), KeyFile: filepath.Join(dir, "iam.key")}
+	writeCertKey(t, certKey)
+
+	c, err := NewCertificate(certKey)
+	if err != nil {
+		t.Fatalf("NewCertificate() error = %v", err)
+	}
+	old := servedCert(t, c)
+
+	if err := c.Reload(CertKey{CertFile: filepath.Join(dir, "missing.crt"), KeyFile: certKey.KeyFile}); err == nil {
+		t.Fatal("Reload() of a missing file succeeded")
+	}
+	if !bytes.Equal(servedCert(t, c), old) || c.CertKey() != certKey {
+		t.Error("Reload() failure replaced the current certificate")
+	}
+}
+
+func TestCertificateWatchFollowsReload(t *testing.T) {
+	oldCertKey := CertKey{CertFile: filepath.Join(t.TempDir(), "iam.crt"), KeyFile: filepath.Join(t.TempDir(), "iam.key")}
+	writeCertKey(t, oldCertKey)
+
+	c, err := NewCertificate(oldCertKey)
+	if err != nil {
+		t.Fatalf("NewCertificate() error = %v", err)
+	}
+	if err := c.Watch(); err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	dir := t.TempDir()
+	certKey := CertKey{CertFile: filepath.Join(dir, "iam.crt"), KeyFile: filepath.Join(dir, "iam.key")}
+	writeCertKey(t, certKey)
+	if err := c.Reload(certKey); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	old := servedCert(t, c)
+
+	writeCertKey(t, certKey)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for bytes.Equal(servedCert(t, c), old) {
+		if time.Now().After(deadline) {
+			t.Fatal("certificate not reloaded after its new files changed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
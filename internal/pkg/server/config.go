@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rose839/IAM/internal/pkg/middleware"
 	"github.com/rose839/IAM/pkg/homedir"
 	"github.com/rose839/IAM/pkg/log"
 	"github.com/spf13/viper"
@@ -29,6 +30,7 @@ type Config struct {
 	Jwt             *JwtInfo
 	Mode            string   // "debug" "release" "test"
 	Middlewares     []string // middlewares that need be enabled
+	CorsOrigins     []string // origins allowed by the cors middleware
 	Healthz         bool     // whether provide health-check(/healthz) api interface
 	EnableProfiling bool     // whether add /debug/pprof profile api interface
 	EnableMetrics   bool     // enable prometheus metrics exporter
@@ -81,6 +83,7 @@ func NewConfig() *Config {
 		Healthz:         true,
 		Mode:            gin.ReleaseMode,
 		Middlewares:     []string{},
+		CorsOrigins:     []string{"*"},
 		EnableProfiling: true,
 		EnableMetrics:   true,
 		Jwt: &JwtInfo{
@@ -119,6 +122,24 @@ func (c CompletedConfig) New() (*GenericAPIServer, error) {
 		Engine:              gin.New(),
 	}
 
+	// gin.Context falls back to the context of the http request, so that the services and
+	// stores given the gin.Context find the span of the request, the values stored under
+	// non string keys and its deadline. Their calls are thus canceled when the client goes
+	// away, the work which must outlive the request uses its own context.
+	s.Engine.ContextWithFallback = true
+
+	middleware.SetCorsOrigins(c.CorsOrigins)
+
+	// the certificate is loaded here, so that it can be shared before the server runs
+	if info := c.SecureServing; info != nil && info.BindPort != 0 &&
+		info.CertKey.CertFile != "" && info.CertKey.KeyFile != "" {
+		certificate, err := NewCertificate(info.CertKey)
+		if err != nil {
+			return nil, err
+		}
+		s.certificate = certificate
+	}
+
 	initGenericAPIServer(s)
 
 	return s, nil
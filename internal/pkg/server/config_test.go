@@ -0,0 +1,41 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fallbackKey struct{}
+
+func TestNew_ContextWithFallback(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Mode = gin.TestMode
+	cfg.Healthz = false
+	cfg.EnableMetrics = false
+	cfg.EnableProfiling = false
+
+	s, err := cfg.Complete().New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var value interface{}
+	var canceled bool
+	s.GET("/fallback", func(c *gin.Context) {
+		value = c.Value(fallbackKey{})
+		<-c.Done()
+		canceled = c.Err() == context.Canceled
+	})
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), fallbackKey{}, "request"))
+	cancel()
+	s.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fallback", nil).WithContext(ctx))
+
+	if value != "request" || !canceled {
+		t.Errorf("gin.Context value = %v, canceled = %v, want the value and cancellation of the request", value, canceled)
+	}
+}
@@ -0,0 +1,18 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/rose839/IAM/pkg/core"
+)
+
+// InstallConfigHandler installs GET /debug/config returning the effective configuration
+// given by config, which must have its secrets masked. The given handlers run first, they
+// must only let administrators through.
+func (s *GenericAPIServer) InstallConfigHandler(config func() (interface{}, error), handlers ...gin.HandlerFunc) {
+	g := s.Group("/debug/config", handlers...)
+	g.GET("", func(c *gin.Context) {
+		cfg, err := config()
+		core.WriteResponse(c, err, cfg)
+	})
+}
@@ -2,17 +2,22 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-contrib/pprof"
 	"github.com/gin-gonic/gin"
+	"github.com/rose839/IAM/internal/pkg/metrics"
 	"github.com/rose839/IAM/internal/pkg/middleware"
 	"github.com/rose839/IAM/pkg/core"
 	"github.com/rose839/IAM/pkg/log"
+	"github.com/rose839/IAM/pkg/sets"
 	"github.com/rose839/IAM/pkg/version"
 	ginprometheus "github.com/zsais/go-gin-prometheus"
 	"golang.org/x/sync/errgroup"
@@ -24,6 +29,9 @@ type GenericAPIServer struct {
 	// list of middleware name that need be installed.
 	middlewares []string
 
+	// enabledMiddlewares holds the set of enabled middlewares, see SetMiddlewares.
+	enabledMiddlewares atomic.Value // map[string]bool
+
 	// "release" or "debug" mode.
 	mode string
 
@@ -43,11 +51,20 @@ type GenericAPIServer struct {
 	// whether provide health-check api interface.
 	healthz bool
 
+	// health checks of /livez and /readyz, readiness also requires the startup to be completed.
+	healthMu     sync.RWMutex
+	livezChecks  []HealthChecker
+	readyzChecks []HealthChecker
+	started      int32
+
 	enableMetrics   bool
 	enableProfiling bool
 
 	// insecure and secure rest api server
 	insecureServer, secureServer *http.Server
+
+	// certificate of the secure server, nil when it is not serving.
+	certificate *Certificate
 }
 
 func initGenericAPIServer(s *GenericAPIServer) {
@@ -68,33 +85,60 @@ func (s *GenericAPIServer) Setup() {
 func (s *GenericAPIServer) InstallMiddlewares() {
 	// necessary middlewares
 	s.Use(middleware.RequestID())
+	s.Use(middleware.Tracing())
 	s.Use(middleware.Context())
-	//s.Use(middleware.Limit(50, 100))
 
-	// install custom middlewares
-	for _, m := range s.middlewares {
+	// install custom middlewares in the configured order, followed by the other registered
+	// middlewares, so that all of them can be enabled and disabled at runtime.
+	s.SetMiddlewares(s.middlewares)
+
+	names := sets.NewString(s.middlewares...)
+	ordered := append([]string{}, s.middlewares...)
+	ordered = append(ordered, sets.StringKeySet(middleware.Middlewares).Difference(names).List()...)
+	for _, m := range ordered {
 		mw, ok := middleware.Middlewares[m]
 		if !ok {
 			continue
 		}
 
-		s.Use(mw)
+		s.Use(s.toggle(m, mw))
+	}
+}
+
+// SetMiddlewares enables the given middlewares and disables the other ones at runtime. The
+// middlewares keep the order they were installed in, the configured order at startup.
+func (s *GenericAPIServer) SetMiddlewares(names []string) {
+	enabled := make(map[string]bool, len(names))
+	for _, name := range names {
+		enabled[name] = true
+	}
+
+	s.enabledMiddlewares.Store(enabled)
+}
+
+// toggle runs the middleware only while it is enabled.
+func (s *GenericAPIServer) toggle(name string, mw gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if enabled, _ := s.enabledMiddlewares.Load().(map[string]bool); !enabled[name] {
+			return
+		}
+
+		mw(c)
 	}
 }
 
 // InstallAPIs install generic apis.
 func (s *GenericAPIServer) InstallAPIs() {
-	// install healthz handler
+	// install health check handlers
 	if s.healthz {
-		s.GET("/healthz", func(c *gin.Context) {
-			core.WriteResponse(c, nil, map[string]string{"status": "ok"})
-		})
+		s.installHealthChecks()
 	}
 
-	// install metric handler
+	// install metric handler, exporting the http metrics together with the iam metrics
 	if s.enableMetrics {
 		prometheus := ginprometheus.NewPrometheus("gin")
-		prometheus.Use(s.Engine)
+		s.Use(prometheus.HandlerFunc())
+		s.GET(prometheus.MetricsPath, gin.WrapH(metrics.Handler()))
 	}
 
 	// install pprof handler
@@ -120,6 +164,18 @@ func (s *GenericAPIServer) Run() error {
 		Handler: s,
 	}
 
+	// the certificate is reloaded when its files change and on ReloadCertificate
+	if s.certificate != nil {
+		if err := s.certificate.Watch(); err != nil {
+			log.Warnf("Watch certificate files failed, they are reloaded with the configuration only: %s", err.Error())
+		}
+
+		s.secureServer.TLSConfig = &tls.Config{
+			GetCertificate: s.certificate.GetCertificate,
+			MinVersion:     tls.VersionTLS12,
+		}
+	}
+
 	var eg errgroup.Group
 
 	// Initializing the server in a goroutine so that
@@ -136,14 +192,13 @@ func (s *GenericAPIServer) Run() error {
 	})
 
 	eg.Go(func() error {
-		key, cert := s.SecureServingInfo.CertKey.KeyFile, s.SecureServingInfo.CertKey.CertFile
-		if cert == "" || key == "" || s.SecureServingInfo.BindPort == 0 {
+		if s.certificate == nil {
 			return nil
 		}
 
 		log.Infof("Start to listening the incoming requests on https address: %s", s.SecureServingInfo.Address())
 
-		if err := s.secureServer.ListenAndServeTLS(cert, key); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		if err := s.secureServer.ListenAndServeTLS("", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatal(err.Error())
 
 			return err
@@ -163,6 +218,9 @@ func (s *GenericAPIServer) Run() error {
 		}
 	}
 
+	// the router answers, so the startup is completed
+	s.MarkStarted()
+
 	if err := eg.Wait(); err != nil {
 		log.Fatal(err.Error())
 	}
@@ -170,6 +228,22 @@ func (s *GenericAPIServer) Run() error {
 	return nil
 }
 
+// Certificate returns the certificate of the secure server, nil when it is not serving.
+// Other listeners can share it to serve the same certificate.
+func (s *GenericAPIServer) Certificate() *Certificate {
+	return s.certificate
+}
+
+// ReloadCertificate loads the certificate of the secure server from the given files,
+// the next tls handshakes use it.
+func (s *GenericAPIServer) ReloadCertificate(certKey CertKey) error {
+	if s.certificate == nil {
+		return fmt.Errorf("secure server is not serving, can not reload its certificate")
+	}
+
+	return s.certificate.Reload(certKey)
+}
+
 // Close graceful shutdown the api server.
 func (s *GenericAPIServer) Close() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -186,9 +260,9 @@ func (s *GenericAPIServer) Close() {
 
 // ping pings the http server to make sure the router is working.
 func (s *GenericAPIServer) ping(ctx context.Context) error {
-	url := fmt.Sprintf("http://%s/healthz", s.InsecureServingInfo.Address)
+	url := fmt.Sprintf("http://%s/livez", s.InsecureServingInfo.Address)
 	if strings.Contains(s.InsecureServingInfo.Address, "0.0.0.0") {
-		url = fmt.Sprintf("http://127.0.0.1:%s/healthz", strings.Split(s.InsecureServingInfo.Address, ":")[1])
+		url = fmt.Sprintf("http://127.0.0.1:%s/livez", strings.Split(s.InsecureServingInfo.Address, ":")[1])
 	}
 
 	for {
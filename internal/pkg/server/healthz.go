@@ -0,0 +1,179 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rose839/IAM/pkg/log"
+)
+
+// healthCheckTimeout bounds the time a single health check can take.
+const healthCheckTimeout = 5 * time.Second
+
+// HealthChecker is a named check of the server or one of its dependencies.
+type HealthChecker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type healthCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+func (h *healthCheck) Name() string {
+	return h.name
+}
+
+func (h *healthCheck) Check(ctx context.Context) error {
+	return h.check(ctx)
+}
+
+// NamedCheck returns a health checker calling check.
+func NamedCheck(name string, check func(ctx context.Context) error) HealthChecker {
+	return &healthCheck{name: name, check: check}
+}
+
+// PingHealthz always succeeds, the server is alive as long as it answers.
+var PingHealthz = NamedCheck("ping", func(context.Context) error {
+	return nil
+})
+
+// HealthCheckResult is the outcome of a single health check.
+type HealthCheckResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// healthStatus is the body of the health endpoints, checks are only listed with ?verbose.
+type healthStatus struct {
+	Status string              `json:"status"`
+	Checks []HealthCheckResult `json:"checks,omitempty"`
+}
+
+// AddLivezChecks adds checks to /livez, a failing liveness check means the server must be restarted.
+func (s *GenericAPIServer) AddLivezChecks(checks ...HealthChecker) {
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+
+	s.livezChecks = append(s.livezChecks, checks...)
+}
+
+// AddReadyzChecks adds checks to /readyz, a failing readiness check means the server
+// must not receive traffic, e.g. because one of its dependencies is unavailable.
+func (s *GenericAPIServer) AddReadyzChecks(checks ...HealthChecker) {
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+
+	s.readyzChecks = append(s.readyzChecks, checks...)
+}
+
+// MarkStarted marks the startup of the server as completed, /readyz fails before.
+func (s *GenericAPIServer) MarkStarted() {
+	atomic.StoreInt32(&s.started, 1)
+}
+
+// Readyz runs the readiness checks and returns the failed ones as error.
+func (s *GenericAPIServer) Readyz(ctx context.Context) error {
+	var failed []string
+	for _, result := range runChecks(ctx, s.readinessChecks()) {
+		if result.Error != "" {
+			failed = append(failed, fmt.Sprintf("%s: %s", result.Name, result.Error))
+		}
+	}
+
+	if len(failed) > 0 {
+		return errors.New(strings.Join(failed, "; "))
+	}
+
+	return nil
+}
+
+func (s *GenericAPIServer) livenessChecks() []HealthChecker {
+	s.healthMu.RLock()
+	defer s.healthMu.RUnlock()
+
+	return append([]HealthChecker{PingHealthz}, s.livezChecks...)
+}
+
+// readinessChecks returns the liveness checks, the startup check and the readiness checks.
+func (s *GenericAPIServer) readinessChecks() []HealthChecker {
+	startup := NamedCheck("startup", func(context.Context) error {
+		if atomic.LoadInt32(&s.started) == 0 {
+			return errors.New("server has not finished starting")
+		}
+
+		return nil
+	})
+
+	s.healthMu.RLock()
+	defer s.healthMu.RUnlock()
+
+	checks := append([]HealthChecker{PingHealthz}, s.livezChecks...)
+	checks = append(checks, startup)
+
+	return append(checks, s.readyzChecks...)
+}
+
+// installHealthChecks installs /livez, /readyz and /healthz, which keeps answering 200
+// as long as the server is up for the existing probes.
+func (s *GenericAPIServer) installHealthChecks() {
+	s.GET("/livez", healthHandler("livez", s.livenessChecks))
+	s.GET("/readyz", healthHandler("readyz", s.readinessChecks))
+	s.GET("/healthz", healthHandler("healthz", func() []HealthChecker {
+		return []HealthChecker{PingHealthz}
+	}))
+}
+
+// healthHandler answers 200 when all checks pass and 503 otherwise, ?verbose lists every check.
+func healthHandler(endpoint string, checks func() []HealthChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		results := runChecks(c, checks())
+
+		status := healthStatus{Status: "ok"}
+		for _, result := range results {
+			if result.Error != "" {
+				status.Status = "failed"
+				log.L(c).Warnf("%s check %s failed: %s", endpoint, result.Name, result.Error)
+			}
+		}
+
+		if c.Request.URL.Query().Has("verbose") {
+			status.Checks = results
+		}
+
+		if status.Status != "ok" {
+			c.JSON(http.StatusServiceUnavailable, status)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, status)
+	}
+}
+
+func runChecks(ctx context.Context, checks []HealthChecker) []HealthCheckResult {
+	results := make([]HealthCheckResult, 0, len(checks))
+	for _, check := range checks {
+		result := HealthCheckResult{Name: check.Name(), Status: "ok"}
+
+		checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		if err := check.Check(checkCtx); err != nil {
+			result.Status = "failed"
+			result.Error = err.Error()
+		}
+		cancel()
+
+		results = append(results, result)
+	}
+
+	return results
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHealthChecks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		started    bool
+		readyErr   error
+		path       string
+		wantStatus int
+		wantChecks []string
+	}{
+		{"livez ignores readiness", false, errors.New("down"), "/livez", http.StatusOK, nil},
+		{"readyz before startup", false, nil, "/readyz", http.StatusServiceUnavailable, nil},
+		{"readyz with failing dependency", true, errors.New("down"), "/readyz", http.StatusServiceUnavailable, nil},
+		{"readyz ready", true, nil, "/readyz", http.StatusOK, nil},
+		{"healthz ignores readiness", false, errors.New("down"), "/healthz", http.StatusOK, nil},
+		{"healthz verbose", true, nil, "/healthz?verbose", http.StatusOK, []string{"ping"}},
+		{"livez verbose", false, nil, "/livez?verbose", http.StatusOK, []string{"ping"}},
+		{
+			"readyz verbose", true, errors.New("down"), "/readyz?verbose", http.StatusServiceUnavailable,
+			[]string{"ping", "startup", "mysql"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &GenericAPIServer{Engine: gin.New()}
+			s.AddReadyzChecks(NamedCheck("mysql", func(context.Context) error {
+				return tt.readyErr
+			}))
+			if tt.started {
+				s.MarkStarted()
+			}
+			s.installHealthChecks()
+
+			w := httptest.NewRecorder()
+			s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+
+			var status healthStatus
+			if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+				t.Fatalf("unmarshal body %s: %v", w.Body.String(), err)
+			}
+
+			if len(status.Checks) != len(tt.wantChecks) {
+				t.Fatalf("checks = %v, want %v", status.Checks, tt.wantChecks)
+			}
+			for i, check := range status.Checks {
+				if check.Name != tt.wantChecks[i] {
+					t.Errorf("check %d = %s, want %s", i, check.Name, tt.wantChecks[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rose839/IAM/internal/pkg/code"
+	"github.com/rose839/IAM/pkg/core"
+	"github.com/rose839/IAM/pkg/errors"
+	"github.com/rose839/IAM/pkg/log"
+)
+
+// LogLevelRequest changes the level of all loggers, or of a named logger and its descendants.
+type LogLevelRequest struct {
+	// Logger is the name of the logger, see log.WithName, empty for all loggers.
+	Logger string `json:"logger,omitempty"`
+
+	// Level is debug, info, warn, error, dpanic, panic or fatal. An empty level makes the
+	// named logger use the level of all loggers again.
+	Level string `json:"level"`
+
+	// Timeout reverts the change after the given duration, like 10m, when set.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// LogLevel is the level of a logger and the time it is reverted at.
+type LogLevel struct {
+	Level    string     `json:"level"`
+	RevertAt *time.Time `json:"revertAt,omitempty"`
+}
+
+// LogLevelStatus is the level of all loggers, the level given in the configuration and
+// the levels of the named loggers having their own level.
+type LogLevelStatus struct {
+	LogLevel        `json:",inline"`
+	ConfiguredLevel string              `json:"configuredLevel"`
+	Loggers         map[string]LogLevel `json:"loggers,omitempty"`
+}
+
+// logLevelReverts reverts the level changes made with a timeout, by logger name.
+type logLevelReverts struct {
+	mu      sync.Mutex
+	reverts map[string]*logLevelRevert
+}
+
+type logLevelRevert struct {
+	timer *time.Timer
+	at    time.Time
+}
+
+// InstallLogLevelHandler installs GET and PUT /debug/loglevel to read and change the log
+// levels at runtime. The given handlers run first, they must only let administrators through.
+func (s *GenericAPIServer) InstallLogLevelHandler(handlers ...gin.HandlerFunc) {
+	r := &logLevelReverts{reverts: map[string]*logLevelRevert{}}
+
+	g := s.Group("/debug/loglevel", handlers...)
+	g.GET("", r.get)
+	g.PUT("", r.put)
+}
+
+func (r *logLevelReverts) get(c *gin.Context) {
+	core.WriteResponse(c, nil, r.status())
+}
+
+func (r *logLevelReverts) put(c *gin.Context) {
+	var req LogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	var level log.Level
+	if err := level.UnmarshalText([]byte(req.Level)); err != nil || (req.Level == "" && req.Logger == "") {
+		core.WriteResponse(c, errors.WithCode(code.ErrValidation, "invalid log level %q", req.Level), nil)
+
+		return
+	}
+
+	var timeout time.Duration
+	if req.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(req.Timeout); err != nil || timeout <= 0 {
+			core.WriteResponse(c, errors.WithCode(code.ErrValidation, "invalid timeout %q", req.Timeout), nil)
+
+			return
+		}
+	}
+
+	r.set(req.Logger, req.Level, level, timeout)
+	log.L(c).Warnf("Log level of %s changed to %s, timeout %s", loggerName(req.Logger), req.Level, timeout)
+
+	core.WriteResponse(c, nil, r.status())
+}
+
+// set changes the level of a logger, and reverts it after the timeout when not zero. The
+// level of all loggers is reverted to the configured one, a named logger to the level of
+// its ancestors.
+func (r *logLevelReverts) set(logger, text string, level log.Level, timeout time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if revert, ok := r.reverts[logger]; ok {
+		revert.timer.Stop()
+		delete(r.reverts, logger)
+	}
+
+	switch {
+	case logger == "":
+		log.SetLevel(level)
+	case text == "":
+		log.UnsetNamedLevel(logger)
+	default:
+		log.SetNamedLevel(logger, level)
+	}
+
+	if timeout == 0 || (logger != "" && text == "") {
+		return
+	}
+
+	revert := &logLevelRevert{at: time.Now().Add(timeout)}
+	revert.timer = time.AfterFunc(timeout, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		// a later change replaced this revert
+		if r.reverts[logger] != revert {
+			return
+		}
+		delete(r.reverts, logger)
+
+		if logger == "" {
+			log.SetLevel(log.ConfiguredLevel())
+		} else {
+			log.UnsetNamedLevel(logger)
+		}
+		log.Warnf("Log level of %s reverted after %s", loggerName(logger), timeout)
+	})
+	r.reverts[logger] = revert
+}
+
+func (r *logLevelReverts) status() *LogLevelStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	revertAt := func(logger string) *time.Time {
+		if revert, ok := r.reverts[logger]; ok {
+			return &revert.at
+		}
+
+		return nil
+	}
+
+	status := &LogLevelStatus{
+		LogLevel:        LogLevel{Level: log.GetLevel().String(), RevertAt: revertAt("")},
+		ConfiguredLevel: log.ConfiguredLevel().String(),
+	}
+	for name, level := range log.NamedLevels() {
+		if status.Loggers == nil {
+			status.Loggers = map[string]LogLevel{}
+		}
+		status.Loggers[name] = LogLevel{Level: level.String(), RevertAt: revertAt(name)}
+	}
+
+	return status
+}
+
+func loggerName(logger string) string {
+	if logger == "" {
+		return "all loggers"
+	}
+
+	return "logger " + logger
+}
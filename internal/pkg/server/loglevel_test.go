@@ -0,0 +1,69 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rose839/IAM/pkg/log"
+)
+
+func TestLogLevelHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := &GenericAPIServer{Engine: gin.New()}
+	s.InstallLogLevelHandler()
+	t.Cleanup(func() {
+		log.SetLevel(log.ConfiguredLevel())
+		log.UnsetNamedLevel("cache")
+	})
+
+	put := func(body string) (int, *LogLevelStatus) {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/debug/loglevel", bytes.NewBufferString(body)))
+
+		status := &LogLevelStatus{}
+		_ = json.Unmarshal(w.Body.Bytes(), status)
+
+		return w.Code, status
+	}
+
+	if code, _ := put(`{"level": "verbose"}`); code != http.StatusBadRequest {
+		t.Errorf("invalid level answered %d, want 400", code)
+	}
+	if code, _ := put(`{"level": "debug", "timeout": "-1s"}`); code != http.StatusBadRequest {
+		t.Errorf("invalid timeout answered %d, want 400", code)
+	}
+
+	code, status := put(`{"level": "warn", "timeout": "50ms"}`)
+	if code != http.StatusOK || status.Level != "warn" || status.RevertAt == nil || log.GetLevel() != log.WarnLevel {
+		t.Fatalf("setting the level answered %d %+v, level is %s", code, status, log.GetLevel())
+	}
+
+	_, status = put(`{"logger": "cache", "level": "debug"}`)
+	if status.Loggers["cache"].Level != "debug" || status.Loggers["cache"].RevertAt != nil {
+		t.Errorf("setting the level of the cache logger answered %+v", status)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if log.GetLevel() != log.ConfiguredLevel() {
+		t.Errorf("level is %s after the timeout, want the configured level %s", log.GetLevel(), log.ConfiguredLevel())
+	}
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/loglevel", nil))
+	status = &LogLevelStatus{}
+	if err := json.Unmarshal(w.Body.Bytes(), status); err != nil || status.RevertAt != nil ||
+		status.Loggers["cache"].Level != "debug" {
+		t.Errorf("getting the levels answered %s", w.Body)
+	}
+
+	_, status = put(`{"logger": "cache", "level": ""}`)
+	if len(status.Loggers) != 0 {
+		t.Errorf("unsetting the level of the cache logger answered %+v", status)
+	}
+}
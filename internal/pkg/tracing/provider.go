@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rose839/IAM/pkg/version"
+)
+
+// Exporters the spans are written by.
+const (
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+// Config defines the tracer provider of a service.
+type Config struct {
+	// ServiceName is the name of the service reported with the spans.
+	ServiceName string
+
+	// Exporter is the exporter the spans are written by, stdout or file.
+	Exporter string
+
+	// File is the file spans are appended to by the file exporter.
+	File string
+
+	// SamplingRatio is the ratio of the traces started by the service which are sampled,
+	// the traces of callers are sampled as decided by the callers.
+	SamplingRatio float64
+}
+
+// NewTracerProvider creates a tracer provider which samples and exports spans as configured.
+// The spans are written as json, one object per line, and batched until the provider is
+// shut down.
+func NewTracerProvider(cfg *Config) (*sdktrace.TracerProvider, error) {
+	var w io.Writer
+	switch cfg.Exporter {
+	case ExporterStdout:
+		w = os.Stdout
+	case ExporterFile:
+		f, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, err
+		}
+		w = f
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter `%s`", cfg.Exporter)
+	}
+
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(w))
+	if err != nil {
+		return nil, err
+	}
+
+	res := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.ServiceVersion(version.Get().GitVersion),
+	)
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SamplingRatio))),
+	), nil
+}
+
+// SetTracerProvider registers tp as the global tracer provider, and the W3C trace context
+// and baggage as the propagators of the traces.
+func SetTracerProvider(tp trace.TracerProvider) {
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
@@ -0,0 +1,40 @@
+// Package tracing traces the requests served by the iam services with OpenTelemetry
+// and correlates them with the traces of their callers.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceParentHeader is the W3C trace context header carrying the trace of the caller,
+// also used as grpc metadata key.
+const TraceParentHeader = "traceparent"
+
+// invalidTraceID is the all zero trace id W3C reserves as invalid.
+var invalidTraceID = strings.Repeat("0", 32)
+
+// TraceID returns the trace id of the span of ctx. Without a span, e.g. when tracing is
+// disabled, it returns the trace id of a W3C traceparent, formatted as
+// version-traceid-parentid-flags, or a new random trace id if it is missing or malformed.
+func TraceID(ctx context.Context, traceparent string) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) >= 4 && len(parts[1]) == 32 && parts[1] != invalidTraceID {
+		if _, err := hex.DecodeString(parts[1]); err == nil {
+			return strings.ToLower(parts[1])
+		}
+	}
+
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
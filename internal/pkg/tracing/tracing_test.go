@@ -0,0 +1,94 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceID(t *testing.T) {
+	spanCtx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x0a, 0xf7, 0x65, 0x19, 0x16, 0xcd, 0x43, 0xdd, 0x84, 0x48, 0xeb, 0x21, 0x1c, 0x80, 0x31, 0x9c},
+		SpanID:  trace.SpanID{0xb7, 0xad, 0x6b, 0x71, 0x69, 0x20, 0x33, 0x31},
+	}))
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		traceparent string
+		want        string
+	}{
+		{name: "span", ctx: spanCtx, traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", want: "0af7651916cd43dd8448eb211c80319c"},
+		{name: "propagated", traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", want: "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{name: "upper case", traceparent: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", want: "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{name: "missing"},
+		{name: "invalid", traceparent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{name: "malformed", traceparent: "00-4bf92f3577b34da6-00f067aa0ba902b7-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			got := TraceID(ctx, tt.traceparent)
+			if tt.want != "" && got != tt.want {
+				t.Errorf("TraceID() = %q, want %q", got, tt.want)
+			}
+			if len(got) != 32 || got == invalidTraceID {
+				t.Errorf("TraceID() = %q, want 32 hex digits", got)
+			}
+		})
+	}
+}
+
+func TestNewTracerProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spans.json")
+	tp, err := NewTracerProvider(&Config{ServiceName: "iam-apiserver", Exporter: ExporterFile, File: path, SamplingRatio: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "GET /v1/users")
+	_, child := tp.Tracer("test").Start(ctx, "UserSrv.List")
+	child.End()
+	parent.End()
+
+	if err := tp.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type exportedSpan struct {
+		Name        string
+		SpanContext struct{ TraceID string }
+	}
+
+	var spans []exportedSpan
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for decoder.More() {
+		var span exportedSpan
+		if err := decoder.Decode(&span); err != nil {
+			t.Fatalf("decode span failed: %v", err)
+		}
+		spans = append(spans, span)
+	}
+
+	if len(spans) != 2 || spans[0].Name != "UserSrv.List" || spans[1].Name != "GET /v1/users" {
+		t.Fatalf("spans = %+v, want UserSrv.List and GET /v1/users", spans)
+	}
+	if spans[0].SpanContext.TraceID != spans[1].SpanContext.TraceID {
+		t.Errorf("trace ids = %s and %s, want the same", spans[0].SpanContext.TraceID, spans[1].SpanContext.TraceID)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/fatih/color"
+	"github.com/rose839/IAM/pkg/errors"
 	"github.com/rose839/IAM/pkg/log"
 	"github.com/rose839/IAM/pkg/term"
 	"github.com/rose839/IAM/pkg/version/verflag"
@@ -189,9 +190,17 @@ func (a *App) buildCommand() {
 		})
 	}
 
-	// Add "--config" flag
+	// Add "--config" flag, the configuration file is read before running the application
+	// and the sub commands reading its configuration
 	if !a.noConfig {
 		addConfigFlag(a.basename, namedFlagSets.FlagSet("global"))
+		cmd.PersistentPreRunE = func(c *cobra.Command, args []string) error {
+			if c.HasParent() && c.Annotations[configAnnotation] != "true" {
+				return nil
+			}
+
+			return readConfig(a.basename)
+		}
 	}
 
 	// Add "--version" flag
@@ -282,9 +291,9 @@ func (a *App) applyOptionRules() error {
 		}
 	}
 
-	// Validate config options
-	if errs := a.options.Validate(); len(errs) != 0 {
-		return errs[0]
+	// Validate config options, all the errors found are reported
+	if err := errors.NewAggregate(a.options.Validate()); err != nil {
+		return err
 	}
 
 	// Print config options
@@ -7,9 +7,15 @@ import (
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/rose839/IAM/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
 )
 
+// configAnnotation annotates the cobra commands reading the configuration file of the application.
+const configAnnotation = "iam.config"
+
 // Command is a sub command structure of a cli application.
 // It is recommended that a command be created with the app.NewCommand()
 // function.
@@ -17,6 +23,9 @@ type Command struct {
 	usage    string
 	desc     string
 	options  CliOptions
+	config   bool           // read the configuration file of the application into options
+	validate bool           // validate the options before running, the run func does otherwise
+	flags    *pflag.FlagSet // flags of the cobra command, set when it is built
 	commands []*Command     // nested sub command
 	runFunc  RunCommandFunc // user-defined sub-command main func
 }
@@ -33,6 +42,14 @@ func WithCommandOptions(opt CliOptions) CommandOption {
 	}
 }
 
+// WithCommandConfig reads the configuration file of the application, given by the
+// "--config" flag, into the options of the command. Flags of the command override it.
+func WithCommandConfig() CommandOption {
+	return func(c *Command) {
+		c.config = true
+	}
+}
+
 // RunCommandFunc defines the application's command startup callback function.
 type RunCommandFunc func(args []string) error
 
@@ -48,8 +65,9 @@ func WithCommandRunFunc(run RunCommandFunc) CommandOption {
 // and other options.
 func NewCommand(usage string, desc string, opts ...CommandOption) *Command {
 	c := &Command{
-		usage: usage,
-		desc:  desc,
+		usage:    usage,
+		desc:     desc,
+		validate: true,
 	}
 
 	for _, o := range opts {
@@ -92,18 +110,44 @@ func (c *Command) CobraCommand() *cobra.Command {
 	}
 
 	// Add flagset to command
+	var namedFlagSets NamedFlagSets
 	if c.options != nil {
-		for _, f := range c.options.Flags().FlagSets {
-			cmd.Flags().AddFlagSet(f)
+		namedFlagSets = c.options.Flags()
+	}
+
+	// Add "--config" flag shared with the application
+	if c.config {
+		cmd.Annotations = map[string]string{configAnnotation: "true"}
+		if f := pflag.Lookup(configFlagName); f != nil {
+			namedFlagSets.FlagSet("global").AddFlag(f)
 		}
 	}
 
-	addHelpCommandFlag(c.usage, cmd.Flags())
+	addHelpCommandFlag(c.usage, namedFlagSets.FlagSet("global"))
+
+	for _, f := range namedFlagSets.FlagSets {
+		cmd.Flags().AddFlagSet(f)
+	}
+	c.flags = cmd.Flags()
+
+	// print the flag sections of the command instead of the ones inherited from the application
+	cmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s\n\nUsage:\n  %s\n", cmd.Short, cmd.UseLine())
+		if cmd.HasAvailableSubCommands() {
+			fmt.Fprintf(cmd.OutOrStdout(), "  %s [command]\n", cmd.CommandPath())
+		}
+		PrintSections(cmd.OutOrStdout(), namedFlagSets, 0)
+	})
 
 	return cmd
 }
 
 func (c *Command) runCommand(cmd *cobra.Command, args []string) {
+	if err := c.applyOptions(cmd); err != nil {
+		fmt.Printf("%v %v\n", color.RedString("Error:"), err)
+		os.Exit(1)
+	}
+
 	if c.runFunc != nil {
 		if err := c.runFunc(args); err != nil {
 			fmt.Printf("%v %v\n", color.RedString("Error:"), err)
@@ -112,6 +156,29 @@ func (c *Command) runCommand(cmd *cobra.Command, args []string) {
 	}
 }
 
+// applyOptions reads the configuration into the options of the command and validates them.
+func (c *Command) applyOptions(cmd *cobra.Command) error {
+	if c.options == nil {
+		return nil
+	}
+
+	if c.config {
+		if err := viper.BindPFlags(cmd.Flags()); err != nil {
+			return err
+		}
+
+		if err := viper.Unmarshal(c.options); err != nil {
+			return err
+		}
+	}
+
+	if !c.validate {
+		return nil
+	}
+
+	return errors.NewAggregate(c.options.Validate())
+}
+
 // AddCommand adds sub command to the application.
 func (a *App) AddCommand(cmd *Command) {
 	a.commands = append(a.commands, cmd)
@@ -2,20 +2,22 @@ package app
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 
-	"github.com/gosuri/uitable"
 	"github.com/rose839/IAM/pkg/homedir"
-	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
 const configFlagName = "config"
 
-var cfgFile string
+var (
+	cfgFile string
+
+	// envPrefix prefixes the environment variables overriding config items, like IAM_APISERVER.
+	envPrefix string
+)
 
 func init() {
 	// add "-c" flag to default flagset
@@ -29,43 +31,31 @@ func addConfigFlag(basename string, fs *pflag.FlagSet) {
 	// Add "--config" flag from default flagset to specified flagset
 	fs.AddFlag(pflag.Lookup(configFlagName))
 
+	envPrefix = strings.Replace(strings.ToUpper(basename), "-", "_", -1)
+
 	viper.AutomaticEnv()
-	viper.SetEnvPrefix(strings.Replace(strings.ToUpper(basename), "-", "_", -1))
+	viper.SetEnvPrefix(envPrefix)
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+}
 
-	// set cobra initial func: read config from config file by viper
-	cobra.OnInitialize(func() {
-		if cfgFile != "" {
-			viper.SetConfigFile(cfgFile)
-		} else {
-			viper.AddConfigPath(".")
-
-			if names := strings.Split(basename, "-"); len(names) > 0 {
-				viper.AddConfigPath(filepath.Join(homedir.HomeDir(), "."+names[0]))
-			}
+// readConfig reads the configuration file given by the "--config" flag, or found by
+// basename in the working directory and the home directory, by viper.
+func readConfig(basename string) error {
+	if cfgFile != "" {
+		viper.SetConfigFile(cfgFile)
+	} else {
+		viper.AddConfigPath(".")
 
-			viper.SetConfigName(basename)
+		if names := strings.Split(basename, "-"); len(names) > 0 {
+			viper.AddConfigPath(filepath.Join(homedir.HomeDir(), "."+names[0]))
 		}
 
-		if err := viper.ReadInConfig(); err != nil {
-			_, _ = fmt.Fprintf(os.Stderr, "Error: failed to read configuration file(%s): %v\n", cfgFile, err)
-			os.Exit(1)
-		}
-	})
-}
+		viper.SetConfigName(basename)
+	}
 
-//lint:ignore U1000 ignore unused func
-func printConfig() {
-	keys := viper.AllKeys()
-	if len(keys) > 0 {
-		fmt.Printf("%v Configuration items:\n", progressMessage)
-		table := uitable.New()
-		table.Separator = " "
-		table.MaxColWidth = 80
-		table.RightAlign(0)
-		for _, k := range keys {
-			table.AddRow(fmt.Sprintf("%s:", k), viper.Get(k))
-		}
-		fmt.Printf("%v", table)
+	if err := viper.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read configuration file(%s): %w", cfgFile, err)
 	}
+
+	return nil
 }
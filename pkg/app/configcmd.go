@@ -0,0 +1,212 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	yamlv3 "gopkg.in/yaml.v3"
+	"sigs.k8s.io/yaml"
+
+	"github.com/rose839/IAM/pkg/errors"
+)
+
+// flagKeyRegexp matches the config item an error of a Validate method is about, which
+// starts with its flag like --secure.bind-port.
+var flagKeyRegexp = regexp.MustCompile(`^--([a-z0-9][a-z0-9.-]*)`)
+
+// NewConfigCommand returns the config subcommand of an application with the given options,
+// which are read the same way the application reads its own: from the configuration file
+// given by "--config", the environment variables and the flags.
+//
+// "config validate" completes and validates the options without starting the application
+// and reports every error found with the source of the item. "config view" prints the
+// effective options with the secrets masked. "config sample" and "config schema" print a
+// commented sample configuration and a JSON Schema of the configuration of the application
+// named basename, generated from the options and their flags.
+func NewConfigCommand(basename string, opts CliOptions) *Command {
+	cmd := NewCommand("config", "Validate, view and generate the configuration")
+	cmd.AddCommands(
+		newConfigValidateCommand(opts),
+		newConfigViewCommand(opts),
+		NewCommand("sample", "Print a sample configuration with every item set to its default",
+			WithCommandRunFunc(func(args []string) error {
+				return writeGenerated(GenerateSampleConfig(opts))
+			})),
+		NewCommand("schema", "Print the JSON Schema of the configuration",
+			WithCommandRunFunc(func(args []string) error {
+				return writeGenerated(GenerateConfigSchema(opts, basename+" configuration"))
+			})),
+	)
+
+	return cmd
+}
+
+func writeGenerated(data []byte, err error) error {
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stdout.Write(data)
+
+	return err
+}
+
+func newConfigValidateCommand(opts CliOptions) *Command {
+	c := NewCommand(
+		"validate",
+		"Validate the configuration without starting the server",
+		WithCommandOptions(opts),
+		WithCommandConfig(),
+	)
+	c.validate = false
+	c.runFunc = func(args []string) error {
+		if err := completeOptions(opts); err != nil {
+			return err
+		}
+
+		errs := errors.NewAggregate(opts.Validate())
+		if errs == nil {
+			fmt.Printf("%v Configuration `%s` is valid\n", progressMessage, viper.ConfigFileUsed())
+
+			return nil
+		}
+
+		fmt.Printf("Configuration `%s` is not valid:\n", viper.ConfigFileUsed())
+		for _, err := range errs.Errors() {
+			if source := configSource(c.flags, configKeyOf(err)); source != "" {
+				fmt.Printf("  %s: %v\n", source, err)
+			} else {
+				fmt.Printf("  %v\n", err)
+			}
+		}
+
+		return fmt.Errorf("%d configuration errors found", len(errs.Errors()))
+	}
+
+	return c
+}
+
+func newConfigViewCommand(opts CliOptions) *Command {
+	c := NewCommand(
+		"view",
+		"Print the effective configuration from the file, environment and flags, secrets masked",
+		WithCommandOptions(opts),
+		WithCommandConfig(),
+	)
+	c.validate = false
+	c.runFunc = func(args []string) error {
+		if err := completeOptions(opts); err != nil {
+			return err
+		}
+
+		items, err := Redact(opts)
+		if err != nil {
+			return err
+		}
+
+		data, err := yaml.Marshal(items)
+		if err != nil {
+			return err
+		}
+
+		_, err = os.Stdout.Write(data)
+
+		return err
+	}
+
+	return c
+}
+
+func completeOptions(opts CliOptions) error {
+	if completeableOptions, ok := opts.(CompleteableOptions); ok {
+		return completeableOptions.Complete()
+	}
+
+	return nil
+}
+
+// configKeyOf returns the key of the config item the error is about, empty if unknown.
+func configKeyOf(err error) string {
+	if match := flagKeyRegexp.FindStringSubmatch(err.Error()); match != nil {
+		return match[1]
+	}
+
+	return ""
+}
+
+// configSource describes where the value of the config item with the given key comes from,
+// by order of precedence: a flag, an environment variable or a line of the configuration
+// file. It returns empty when the item has its default value.
+func configSource(fs *pflag.FlagSet, key string) string {
+	if key == "" {
+		return ""
+	}
+
+	if fs != nil {
+		if f := fs.Lookup(key); f != nil && f.Changed {
+			return "flag --" + key
+		}
+	}
+
+	env := strings.NewReplacer(".", "_", "-", "_").Replace(strings.ToUpper(key))
+	if envPrefix != "" {
+		env = envPrefix + "_" + env
+	}
+	if _, ok := os.LookupEnv(env); ok {
+		return "env " + env
+	}
+
+	file := viper.ConfigFileUsed()
+	if file == "" || !viper.InConfig(key) {
+		return ""
+	}
+
+	if ext := filepath.Ext(file); ext == ".yaml" || ext == ".yml" {
+		if line := yamlLine(file, key); line > 0 {
+			return fmt.Sprintf("%s:%d", file, line)
+		}
+	}
+
+	return file
+}
+
+// yamlLine returns the line of the item with the dotted key in a yaml file, 0 if not found.
+func yamlLine(file string, key string) int {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return 0
+	}
+
+	var node yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &node); err != nil || len(node.Content) == 0 {
+		return 0
+	}
+
+	line := 0
+	current := node.Content[0]
+	for _, name := range strings.Split(key, ".") {
+		if current.Kind != yamlv3.MappingNode {
+			return 0
+		}
+
+		var next *yamlv3.Node
+		for i := 0; i+1 < len(current.Content); i += 2 {
+			if strings.EqualFold(current.Content[i].Value, name) {
+				line, next = current.Content[i].Line, current.Content[i+1]
+
+				break
+			}
+		}
+		if next == nil {
+			return 0
+		}
+		current = next
+	}
+
+	return line
+}
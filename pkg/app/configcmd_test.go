@@ -0,0 +1,57 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestConfigSource(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "iam-test.yaml")
+	data := "server:\n  mode: debug\n\nsecure:\n  bind-port: 70000\n  tls:\n    pair-name: iam\n"
+	if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	viper.Reset()
+	defer viper.Reset()
+	viper.SetConfigFile(file)
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	envPrefix = "IAM_TEST"
+	defer func() { envPrefix = "" }()
+	t.Setenv("IAM_TEST_SERVER_MIDDLEWARES", "recovery")
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("server.mode", "release", "")
+	fs.Int("secure.bind-port", 8443, "")
+	fs.String("server.middlewares", "", "")
+	fs.Bool("audit.enabled", true, "")
+	if err := fs.Parse([]string{"--server.mode=test"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{errors.New("--server.mode must be one of debug, test or release"), "flag --server.mode"},
+		{errors.New("--server.middlewares: unknown middleware `bogus`"), "env IAM_TEST_SERVER_MIDDLEWARES"},
+		{errors.New("--secure.bind-port 70000 must be between 1 and 65535"), file + ":5"},
+		{errors.New("--secure.tls.pair-name is required"), file + ":7"},
+		{errors.New("--audit.enabled is not set"), ""},
+		{errors.New("Not a valid log format"), ""},
+	}
+
+	for _, tt := range tests {
+		if got := configSource(fs, configKeyOf(tt.err)); got != tt.want {
+			t.Errorf("configSource(%q) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
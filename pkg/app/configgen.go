@@ -0,0 +1,289 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// commentWidth is the width the comments of the sample configuration are wrapped at.
+const commentWidth = 100
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// configItem is a config item of an options struct, a section when it has items.
+type configItem struct {
+	key        string // dotted key, like mysql.host
+	name       string // last part of the key
+	value      reflect.Value
+	usage      string
+	deprecated string // what to use instead of a deprecated item
+	items      []*configItem
+}
+
+// configItemsOf walks the options struct and returns its config items, named by their
+// mapstructure tags, or json tags when there are none, with the usage of their flags. The
+// fields without tags can not be configured and are skipped. The fields with a deprecated
+// tag are still accepted in the configurations, but left out of the samples.
+func configItemsOf(opts CliOptions) []*configItem {
+	fss := opts.Flags()
+
+	return structItems(reflect.ValueOf(opts), "", fss)
+}
+
+func structItems(v reflect.Value, prefix string, fss NamedFlagSets) []*configItem {
+	v = indirect(v)
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var items []*configItem
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name := tagName(field)
+		if name == "" || !field.IsExported() {
+			continue
+		}
+
+		item := &configItem{
+			key:        prefix + name,
+			name:       name,
+			value:      indirect(v.Field(i)),
+			deprecated: field.Tag.Get("deprecated"),
+		}
+		if isSection(item.value.Type()) {
+			item.items = structItems(item.value, item.key+".", fss)
+			if prefix == "" {
+				item.usage = sectionName(fss, item.key)
+			}
+		} else {
+			item.usage = flagUsage(fss, item.key)
+			if item.usage == "" {
+				item.usage = field.Tag.Get("description")
+			}
+		}
+
+		items = append(items, item)
+	}
+
+	return items
+}
+
+// indirect returns the value v points to, the zero value of its type when v is nil.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Zero(v.Type().Elem())
+		}
+		v = v.Elem()
+	}
+
+	return v
+}
+
+func isSection(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{})
+}
+
+func tagName(field reflect.StructField) string {
+	for _, tag := range []string{"mapstructure", "json"} {
+		if name := strings.Split(field.Tag.Get(tag), ",")[0]; name != "" {
+			if name == "-" {
+				continue
+			}
+
+			return name
+		}
+	}
+
+	return ""
+}
+
+// flagUsage returns the usage of the flag named by the key, empty if there is none.
+func flagUsage(fss NamedFlagSets, key string) string {
+	for _, name := range fss.Order {
+		if flag := fss.FlagSets[name].Lookup(key); flag != nil {
+			_, usage := pflag.UnquoteUsage(flag)
+
+			return usage
+		}
+	}
+
+	return ""
+}
+
+// sectionName returns the name of the flag set holding the flags of the section.
+func sectionName(fss NamedFlagSets, key string) string {
+	for _, name := range fss.Order {
+		found := false
+		fss.FlagSets[name].VisitAll(func(flag *pflag.Flag) {
+			found = found || strings.HasPrefix(flag.Name, key+".")
+		})
+		if found {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// GenerateSampleConfig generates a yaml configuration of the options with every config item
+// set to its current value, the defaults for new options, and commented with the usage of its
+// flag.
+func GenerateSampleConfig(opts CliOptions) ([]byte, error) {
+	root, err := sampleNode(configItemsOf(opts))
+	if err != nil {
+		return nil, err
+	}
+	doc := &yamlv3.Node{
+		Kind:        yamlv3.DocumentNode,
+		HeadComment: "Sample configuration, generated from the options and flags. Every item is set to its default value.",
+		Content:     []*yamlv3.Node{root},
+	}
+
+	buf := bytes.Buffer{}
+	encoder := yamlv3.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func sampleNode(items []*configItem) (*yamlv3.Node, error) {
+	node := &yamlv3.Node{Kind: yamlv3.MappingNode}
+	for _, item := range items {
+		if item.deprecated != "" {
+			continue
+		}
+
+		key := &yamlv3.Node{Kind: yamlv3.ScalarNode, Value: item.name, HeadComment: wrapComment(item.usage)}
+
+		var value *yamlv3.Node
+		var err error
+		if item.items != nil {
+			value, err = sampleNode(item.items)
+		} else {
+			value = &yamlv3.Node{}
+			err = value.Encode(sampleValue(item.value))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("encode %s: %w", item.key, err)
+		}
+
+		node.Content = append(node.Content, key, value)
+	}
+
+	return node, nil
+}
+
+// sampleValue returns the value as written in a configuration, durations like 1h0m0s.
+func sampleValue(v reflect.Value) interface{} {
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+
+	return v.Interface()
+}
+
+func wrapComment(text string) string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > commentWidth {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// GenerateConfigSchema generates a JSON Schema of the configuration of the options, which
+// editors validate configuration files with. The config items take the types viper decodes:
+// lists can also be given as comma separated strings and durations as nanoseconds.
+func GenerateConfigSchema(opts CliOptions, title string) ([]byte, error) {
+	schema := schemaOf(configItemsOf(opts))
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = title
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+func schemaOf(items []*configItem) map[string]interface{} {
+	properties := map[string]interface{}{}
+	for _, item := range items {
+		var property map[string]interface{}
+		if item.items != nil {
+			property = schemaOf(item.items)
+		} else {
+			property = typeSchema(item.value.Type())
+			property["default"] = sampleValue(item.value)
+		}
+		if item.usage != "" {
+			property["description"] = item.usage
+		}
+		if item.deprecated != "" {
+			property["description"] = "Deprecated, " + item.deprecated + "."
+		}
+
+		properties[item.name] = property
+	}
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+func typeSchema(t reflect.Type) map[string]interface{} {
+	if t == durationType {
+		return map[string]interface{}{
+			"type":    []string{"string", "integer"},
+			"pattern": `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`,
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": []string{"array", "string"}, "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Ptr:
+		return typeSchema(t.Elem())
+	}
+
+	return map[string]interface{}{}
+}
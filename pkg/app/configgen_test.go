@@ -0,0 +1,101 @@
+package app
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testServerOptions struct {
+	Mode        string        `json:"mode"        mapstructure:"mode"`
+	Timeout     time.Duration `json:"timeout"     mapstructure:"timeout"`
+	Middlewares []string      `json:"middlewares" mapstructure:"middlewares"`
+	Port        int           `json:"port"        description:"Port to listen on."`
+	Required    bool
+	Listen      *int `json:"listen,omitempty" mapstructure:"listen" deprecated:"use port instead"`
+}
+
+type testOptions struct {
+	Server *testServerOptions `json:"server" mapstructure:"server"`
+}
+
+func (o *testOptions) Flags() (fss NamedFlagSets) {
+	fs := fss.FlagSet("generic")
+	fs.StringVar(&o.Server.Mode, "server.mode", o.Server.Mode, "Server `MODE`, one of debug, test and release.")
+	fs.DurationVar(&o.Server.Timeout, "server.timeout", o.Server.Timeout, "Request timeout.")
+
+	return fss
+}
+
+func (o *testOptions) Validate() []error {
+	return nil
+}
+
+func newTestOptions() *testOptions {
+	return &testOptions{Server: &testServerOptions{Mode: "release", Timeout: time.Minute, Port: 8080}}
+}
+
+func TestGenerateSampleConfig(t *testing.T) {
+	data, err := GenerateSampleConfig(newTestOptions())
+	if err != nil {
+		t.Fatalf("GenerateSampleConfig() error = %v", err)
+	}
+
+	sample := string(data)
+	for _, want := range []string{
+		"# generic\nserver:\n",
+		"  # Server MODE, one of debug, test and release.\n  mode: release\n",
+		"  # Request timeout.\n  timeout: 1m0s\n",
+		"  middlewares: []\n",
+		"  # Port to listen on.\n  port: 8080\n",
+	} {
+		if !strings.Contains(sample, want) {
+			t.Errorf("GenerateSampleConfig() = %s, want it to contain %q", sample, want)
+		}
+	}
+	if strings.Contains(strings.ToLower(sample), "required") {
+		t.Errorf("GenerateSampleConfig() = %s, want the fields without tags skipped", sample)
+	}
+	if strings.Contains(sample, "listen:") {
+		t.Errorf("GenerateSampleConfig() = %s, want the deprecated fields skipped", sample)
+	}
+}
+
+func TestGenerateConfigSchema(t *testing.T) {
+	data, err := GenerateConfigSchema(newTestOptions(), "test configuration")
+	if err != nil {
+		t.Fatalf("GenerateConfigSchema() error = %v", err)
+	}
+
+	var schema struct {
+		Title      string `json:"title"`
+		Properties map[string]struct {
+			Properties map[string]struct {
+				Type        interface{} `json:"type"`
+				Default     interface{} `json:"default"`
+				Description string      `json:"description"`
+			} `json:"properties"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	server := schema.Properties["server"].Properties
+	if schema.Title != "test configuration" || len(server) != 5 {
+		t.Fatalf("GenerateConfigSchema() = %s", data)
+	}
+	if p := server["mode"]; p.Type != "string" || p.Default != "release" || p.Description == "" {
+		t.Errorf("mode = %+v", p)
+	}
+	if p := server["timeout"]; p.Default != "1m0s" {
+		t.Errorf("timeout = %+v", p)
+	}
+	if p := server["port"]; p.Type != "integer" || p.Description != "Port to listen on." {
+		t.Errorf("port = %+v", p)
+	}
+	if p := server["listen"]; p.Type != "integer" || p.Description != "Deprecated, use port instead." {
+		t.Errorf("listen = %+v", p)
+	}
+}
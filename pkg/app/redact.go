@@ -0,0 +1,94 @@
+package app
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// RedactedValue replaces the values of the sensitive config items.
+const RedactedValue = "******"
+
+// sensitiveWords are the last words of the config keys holding secrets, like password
+// and smtp-password. The key item is sensitive on its own only, redis-key names a redis key.
+var sensitiveWords = []string{"password", "secret", "token", "tokens", "passphrase"}
+
+// Redact returns the config items of the given options, as marshaled to json, with the
+// values of the sensitive items masked. Empty values are kept to show they are not set.
+func Redact(opts interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	items := map[string]interface{}{}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+
+	redactItems(items)
+
+	return items, nil
+}
+
+// IsSensitiveKey reports whether the config item with the given key holds a secret.
+func IsSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		key = key[i+1:]
+	}
+	if key == "key" {
+		return true
+	}
+
+	if i := strings.LastIndexAny(key, "-_"); i >= 0 {
+		key = key[i+1:]
+	}
+	for _, word := range sensitiveWords {
+		if key == word {
+			return true
+		}
+	}
+
+	return false
+}
+
+func redactItems(items map[string]interface{}) {
+	for key, value := range items {
+		if IsSensitiveKey(key) {
+			items[key] = redactValue(value)
+
+			continue
+		}
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			redactItems(v)
+		case []interface{}:
+			for _, elem := range v {
+				if m, ok := elem.(map[string]interface{}); ok {
+					redactItems(m)
+				}
+			}
+		}
+	}
+}
+
+// redactValue masks a value, the keys of maps like the tokens by caller name are kept.
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		if v == "" {
+			return v
+		}
+	case map[string]interface{}:
+		for key, elem := range v {
+			v[key] = redactValue(elem)
+		}
+
+		return v
+	}
+
+	return RedactedValue
+}
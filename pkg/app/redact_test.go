@@ -0,0 +1,60 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIsSensitiveKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"mysql.password", true},
+		{"smtp-password", true},
+		{"jwt.key", true},
+		{"secret_token", true},
+		{"server.tokens", true},
+		{"redis.redis-key", false},
+		{"mysql.username", false},
+		{"passwords-file", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsSensitiveKey(tt.key); got != tt.want {
+			t.Errorf("IsSensitiveKey(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+	type mysql struct {
+		Host     string `json:"host"`
+		Password string `json:"password"`
+	}
+	opts := struct {
+		MySQL  mysql             `json:"mysql"`
+		Key    string            `json:"key"`
+		Empty  string            `json:"smtp-password"`
+		Tokens map[string]string `json:"tokens"`
+	}{
+		MySQL:  mysql{Host: "127.0.0.1:3306", Password: "iam59!z$"},
+		Key:    "dfVpOK8LZeJLZHYmHdb1VdyRrACKpqoo",
+		Tokens: map[string]string{"authz": "abc"},
+	}
+
+	got, err := Redact(opts)
+	if err != nil {
+		t.Fatalf("Redact() error = %v", err)
+	}
+
+	want := map[string]interface{}{
+		"mysql":         map[string]interface{}{"host": "127.0.0.1:3306", "password": RedactedValue},
+		"key":           RedactedValue,
+		"smtp-password": "",
+		"tokens":        map[string]interface{}{"authz": RedactedValue},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Redact() = %v, want %v", got, want)
+	}
+}
@@ -19,6 +19,13 @@ func Compare(hashedPassword, password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 }
 
+// IsEncrypted reports whether the text is already a bcrypt hash.
+func IsEncrypted(text string) bool {
+	_, err := bcrypt.Cost([]byte(text))
+
+	return err == nil
+}
+
 // Sign issue a jwt token based on secretID, secretKey, iss and aud.
 func Sign(secretID string, secretKey string, iss, aud string) string {
 	claims := jwt.MapClaims{
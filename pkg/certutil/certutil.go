@@ -0,0 +1,168 @@
+// Package certutil generates self-signed certificates for development environments
+// and reads and writes them as pem encoded files.
+package certutil
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// caValidity is the validity of the generated certificate authorities.
+	caValidity = 10 * 365 * 24 * time.Hour
+
+	// certValidity is the validity of the generated serving certificates.
+	certValidity = 365 * 24 * time.Hour
+)
+
+// GenerateSelfSignedCertKey creates a self-signed certificate authority and a serving
+// certificate signed by it, which is valid for host and the alternate ips and dns names.
+// It returns the pem encoded serving certificate followed by the authority, the private
+// key of the serving certificate and the authority on its own, clients trust it.
+func GenerateSelfSignedCertKey(host string, alternateIPs []net.IP, alternateDNS []string) ([]byte, []byte, []byte, error) {
+	now := time.Now()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	caTemplate := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: fmt.Sprintf("%s-ca@%d", host, now.Unix())},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := createCertificate(caTemplate, caTemplate, caKey.Public(), caKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: fmt.Sprintf("%s@%d", host, now.Unix())},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(certValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		template.IPAddresses = append(template.IPAddresses, ip)
+	} else {
+		template.DNSNames = append(template.DNSNames, host)
+	}
+	template.IPAddresses = append(template.IPAddresses, alternateIPs...)
+	template.DNSNames = append(template.DNSNames, alternateDNS...)
+
+	certDER, err := createCertificate(template, ca, key.Public(), caKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// the serving certificate is followed by the authority to make up the chain
+	certBuffer := bytes.Buffer{}
+	if err := pem.Encode(&certBuffer, &pem.Block{Type: "CERTIFICATE", Bytes: certDER}); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := pem.Encode(&certBuffer, &pem.Block{Type: "CERTIFICATE", Bytes: caDER}); err != nil {
+		return nil, nil, nil, err
+	}
+
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+
+	return certBuffer.Bytes(), keyPEM, caPEM, nil
+}
+
+func createCertificate(template, parent *x509.Certificate, pub crypto.PublicKey, priv crypto.Signer) ([]byte, error) {
+	serial, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt64))
+	if err != nil {
+		return nil, err
+	}
+	template.SerialNumber = serial
+
+	return x509.CreateCertificate(rand.Reader, template, parent, pub, priv)
+}
+
+// CanReadCertAndKey reports whether the certificate and key files exist and are readable.
+func CanReadCertAndKey(certPath, keyPath string) (bool, error) {
+	certReadable := canReadFile(certPath)
+	keyReadable := canReadFile(keyPath)
+
+	if !certReadable && !keyReadable {
+		return false, nil
+	}
+
+	if !certReadable {
+		return false, fmt.Errorf("error reading %s, certificate and key must be supplied as a pair", certPath)
+	}
+
+	if !keyReadable {
+		return false, fmt.Errorf("error reading %s, certificate and key must be supplied as a pair", keyPath)
+	}
+
+	return true, nil
+}
+
+func canReadFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	return true
+}
+
+// WriteCert writes the pem encoded certificate data to certPath, creating its directory.
+func WriteCert(certPath string, data []byte) error {
+	return writeFile(certPath, data, os.FileMode(0o644))
+}
+
+// WriteKey writes the pem encoded key data to keyPath, creating its directory. Only the
+// owner can read it.
+func WriteKey(keyPath string, data []byte) error {
+	return writeFile(keyPath, data, os.FileMode(0o600))
+}
+
+func writeFile(path string, data []byte, perm os.FileMode) error {
+	if len(data) == 0 {
+		return errors.New("no data to write to " + path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), os.FileMode(0o755)); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, perm)
+}
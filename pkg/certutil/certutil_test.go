@@ -0,0 +1,66 @@
+package certutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateSelfSignedCertKey(t *testing.T) {
+	cert, key, ca, err := GenerateSelfSignedCertKey("localhost", []net.IP{net.ParseIP("127.0.0.1")}, []string{"iam.local"})
+	if err != nil {
+		t.Fatalf("GenerateSelfSignedCertKey() error = %v", err)
+	}
+
+	pair, err := tls.X509KeyPair(cert, key)
+	if err != nil {
+		t.Fatalf("tls.X509KeyPair() error = %v", err)
+	}
+	if len(pair.Certificate) != 2 {
+		t.Fatalf("certificate chain length = %d, want 2", len(pair.Certificate))
+	}
+
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(ca) {
+		t.Fatal("no certificate authority found")
+	}
+
+	for _, host := range []string{"localhost", "127.0.0.1", "iam.local"} {
+		if _, err := leaf.Verify(x509.VerifyOptions{DNSName: host, Roots: roots}); err != nil {
+			t.Errorf("Verify(%s) error = %v", host, err)
+		}
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{DNSName: "example.com", Roots: roots}); err == nil {
+		t.Error("Verify(example.com) succeeded")
+	}
+}
+
+func TestCanReadCertAndKey(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath := filepath.Join(dir, "certs", "iam.crt"), filepath.Join(dir, "certs", "iam.key")
+
+	if ok, err := CanReadCertAndKey(certPath, keyPath); ok || err != nil {
+		t.Errorf("CanReadCertAndKey() = %v, %v, want false, nil without files", ok, err)
+	}
+
+	if err := WriteCert(certPath, []byte("cert")); err != nil {
+		t.Fatalf("WriteCert() error = %v", err)
+	}
+	if ok, err := CanReadCertAndKey(certPath, keyPath); ok || err == nil {
+		t.Errorf("CanReadCertAndKey() = %v, %v, want an error without key", ok, err)
+	}
+
+	if err := WriteKey(keyPath, []byte("key")); err != nil {
+		t.Fatalf("WriteKey() error = %v", err)
+	}
+	if ok, err := CanReadCertAndKey(certPath, keyPath); !ok || err != nil {
+		t.Errorf("CanReadCertAndKey() = %v, %v, want true, nil", ok, err)
+	}
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+
+	"github.com/rose839/IAM/pkg/log"
+)
+
+// slowThreshold is the latency from which a sql statement is logged as slow.
+const slowThreshold = 200 * time.Millisecond
+
+// gormLogger writes the gorm logs with log.L, so that the sql statements carry the
+// request fields of the context they are executed with, e.g. db.WithContext(ctx).
+type gormLogger struct {
+	level logger.LogLevel
+}
+
+// NewLogger returns a gorm logger which logs through log.L with the given gorm log level,
+// from 1 (silent) to 4 (info), warn when it is zero.
+func NewLogger(level int) logger.Interface {
+	if level == 0 {
+		level = int(logger.Warn)
+	}
+
+	return &gormLogger{level: logger.LogLevel(level)}
+}
+
+// LogMode returns a copy of the logger with the given log level.
+func (l *gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return &gormLogger{level: level}
+}
+
+// Info logs an info message.
+func (l *gormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Info {
+		log.L(ctx).Infof(msg, data...)
+	}
+}
+
+// Warn logs a warning message.
+func (l *gormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Warn {
+		log.L(ctx).Warnf(msg, data...)
+	}
+}
+
+// Error logs an error message.
+func (l *gormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Error {
+		log.L(ctx).Errorf(msg, data...)
+	}
+}
+
+// ParamsFilter drops the values of the sql statements, which hold passwords, tokens and
+// other personal data, their placeholders are logged instead.
+func (l *gormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
+
+// Trace logs a sql statement: failed ones from the error level, slow ones from the
+// warn level and all of them at the info level.
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	fields := func() []log.Field {
+		sql, rows := fc()
+
+		return []log.Field{
+			log.String("caller", utils.FileWithLineNum()),
+			log.String("sql", sql),
+			log.Int64("rows", rows),
+			log.Duration("latency", elapsed),
+		}
+	}
+
+	switch {
+	case err != nil && l.level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		log.L(ctx).Error("sql failed", append(fields(), log.Err(err))...)
+	case elapsed > slowThreshold && l.level >= logger.Warn:
+		log.L(ctx).Warn(fmt.Sprintf("slow sql >= %v", slowThreshold), fields()...)
+	case l.level >= logger.Info:
+		log.L(ctx).Info("sql", fields()...)
+	}
+}
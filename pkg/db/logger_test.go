@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"github.com/rose839/IAM/pkg/log"
+)
+
+func TestLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "iam.log")
+	opts := log.NewOptions()
+	opts.OutputPaths = []string{path}
+	t.Cleanup(func() { log.Init(log.NewOptions()) })
+	log.Init(opts)
+
+	tests := []struct {
+		name    string
+		level   int
+		wantSQL bool
+	}{
+		{name: "default", level: 0, wantSQL: false},
+		{name: "info", level: 4, wantSQL: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := gorm.Open(mysql.New(mysql.Config{DSN: "iam:iam@tcp(127.0.0.1:3306)/iam", SkipInitializeWithVersion: true}),
+				&gorm.Config{DryRun: true, DisableAutomaticPing: true, Logger: NewLogger(tt.level)})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var users []user
+			db.WithContext(context.Background()).Where("name = ?", tt.name+"-secret").Find(&users)
+			log.Flush()
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(data), tt.name+"-secret") {
+				t.Errorf("logged the values of the statement: %s", data)
+			}
+			if got := strings.Contains(string(data), "WHERE name = ?"); got != tt.wantSQL {
+				t.Errorf("logged the statement = %v, want %v: %s", got, tt.wantSQL, data)
+			}
+		})
+	}
+}
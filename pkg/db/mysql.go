@@ -32,6 +32,10 @@ func New(opts *Options) (*gorm.DB, error) {
 		true,
 		"Local")
 
+	if opts.Logger == nil {
+		opts.Logger = NewLogger(opts.LogLevel)
+	}
+
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
 		Logger: opts.Logger,
 	})
@@ -39,6 +43,10 @@ func New(opts *Options) (*gorm.DB, error) {
 		return nil, err
 	}
 
+	if err := db.Use(NewTracingPlugin()); err != nil {
+		return nil, err
+	}
+
 	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, err
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	tracerName = "github.com/rose839/IAM/pkg/db"
+
+	// spanKey is the key of the span of a statement in the instance settings of gorm.
+	spanKey = "otel:span"
+)
+
+// tracingPlugin is a gorm plugin which starts a client span for every sql statement
+// executed with a context of a trace, e.g. db.WithContext(ctx) within a request.
+// Statements outside of a trace, like the ones of background jobs, are not traced.
+type tracingPlugin struct {
+	tracer trace.Tracer
+}
+
+// statementSpan is the span of a statement and the context it replaced in the statement.
+type statementSpan struct {
+	span   trace.Span
+	parent context.Context
+}
+
+// NewTracingPlugin returns a gorm plugin which traces the sql statements.
+func NewTracingPlugin() gorm.Plugin {
+	return &tracingPlugin{tracer: otel.Tracer(tracerName)}
+}
+
+// Name returns the name of the plugin.
+func (p *tracingPlugin) Name() string {
+	return "otel"
+}
+
+// Initialize registers the callbacks starting and ending the spans around the statements.
+func (p *tracingPlugin) Initialize(db *gorm.DB) error {
+	type register func(name string, fn func(*gorm.DB)) error
+
+	cb := db.Callback()
+	for _, hook := range []struct {
+		operation     string
+		before, after register
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	} {
+		if err := hook.before("otel:before_"+hook.operation, p.before(hook.operation)); err != nil {
+			return err
+		}
+		if err := hook.after("otel:after_"+hook.operation, p.after); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *tracingPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		parent := db.Statement.Context
+		if parent == nil || !trace.SpanContextFromContext(parent).IsValid() {
+			return
+		}
+
+		ctx, span := p.tracer.Start(parent, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemMySQL, semconv.DBOperationKey.String(operation)),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(spanKey, &statementSpan{span: span, parent: parent})
+	}
+}
+
+func (p *tracingPlugin) after(db *gorm.DB) {
+	v, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	s, _ := v.(*statementSpan)
+	if s == nil {
+		return
+	}
+
+	// statements chained from the same gorm.DB share it, restore the context of the caller.
+	db.Statement.Context = s.parent
+	db.InstanceSet(spanKey, (*statementSpan)(nil))
+
+	s.span.SetAttributes(
+		semconv.DBStatementKey.String(db.Statement.SQL.String()),
+		semconv.DBSQLTable(db.Statement.Table),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		s.span.RecordError(db.Error)
+		s.span.SetStatus(codes.Error, db.Error.Error())
+	}
+	s.span.End()
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+type user struct {
+	ID   uint64
+	Name string
+}
+
+func TestTracingPlugin(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	// dry run statements are built and traced, but not sent to the database.
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "iam:iam@tcp(127.0.0.1:3306)/iam", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true, Logger: NewLogger(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(&tracingPlugin{tracer: tp.Tracer("test")}); err != nil {
+		t.Fatal(err)
+	}
+
+	// statements outside of a trace are not traced
+	var users []user
+	db.WithContext(context.Background()).Where("name = ?", "colin").Find(&users)
+	if spans := recorder.Ended(); len(spans) != 0 {
+		t.Fatalf("spans outside of a trace = %d, want 0", len(spans))
+	}
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "GET /v1/users")
+	d := db.WithContext(ctx).Where("name = ?", "colin")
+	d.Find(&users)
+	d.Count(new(int64))
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("spans = %d, want 3", len(spans))
+	}
+
+	for _, span := range spans[:2] {
+		if span.Name() != "gorm.query" {
+			t.Errorf("span name = %s, want gorm.query", span.Name())
+		}
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("parent of %s = %s, want %s", span.Name(), span.Parent().SpanID(), parent.SpanContext().SpanID())
+		}
+
+		attrs := map[string]string{}
+		for _, attr := range span.Attributes() {
+			attrs[string(attr.Key)] = attr.Value.Emit()
+		}
+		if attrs["db.system"] != "mysql" || attrs["db.sql.table"] != "users" || attrs["db.statement"] == "" {
+			t.Errorf("attributes of %s = %v", span.Name(), attrs)
+		}
+	}
+}
@@ -10,6 +10,25 @@ const (
 	logContextKey key = iota
 )
 
+// fieldKey is the key of a log field stored in a context by WithField.
+type fieldKey string
+
+// WithField returns a copy of ctx holding a field which L attaches to the logged lines,
+// key is one of the Key constants, e.g. KeyRequestID.
+func WithField(ctx context.Context, key string, value interface{}) context.Context {
+	return context.WithValue(ctx, fieldKey(key), value)
+}
+
+// FieldFrom returns the value of a log field of ctx stored by WithField. The contexts
+// keeping their values by plain string keys, like gin.Context, are looked up by key.
+func FieldFrom(ctx context.Context, key string) interface{} {
+	if value := ctx.Value(fieldKey(key)); value != nil {
+		return value
+	}
+
+	return ctx.Value(key)
+}
+
 // WithContext returns a copy of context in which the log value is set.
 func WithContext(ctx context.Context) context.Context {
 	return std.WithContext(ctx)
@@ -0,0 +1,114 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// keysContext keeps its values by plain string keys, like gin.Context.
+type keysContext struct {
+	context.Context
+	keys map[string]interface{}
+}
+
+func (c keysContext) Value(key interface{}) interface{} {
+	if key, ok := key.(string); ok {
+		return c.keys[key]
+	}
+
+	return c.Context.Value(key)
+}
+
+func TestLWithContext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "iam.log")
+	opts := NewOptions()
+	opts.Format = jsonFormat
+	opts.OutputPaths = []string{path}
+	t.Cleanup(func() { Init(NewOptions()) })
+	Init(opts)
+
+	ctx := context.Background()
+	for key, value := range map[string]string{
+		KeyRequestID: "f4b0c3b0-0f3a-4b8e-9a7e-3c1f0d3b9e21",
+		KeyUsername:  "colin",
+		KeyTraceID:   "4bf92f3577b34da6a3ce929d0e0e4736",
+		KeyRoute:     "/v1/users/:name",
+	} {
+		ctx = WithField(ctx, key, value)
+	}
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		fields map[string]interface{}
+	}{
+		{
+			name: "request",
+			ctx:  ctx,
+			fields: map[string]interface{}{
+				KeyRequestID: "f4b0c3b0-0f3a-4b8e-9a7e-3c1f0d3b9e21",
+				KeyUsername:  "colin",
+				KeyTraceID:   "4bf92f3577b34da6a3ce929d0e0e4736",
+				KeyRoute:     "/v1/users/:name",
+			},
+		},
+		{
+			name: "plain keys",
+			ctx:  keysContext{Context: context.Background(), keys: map[string]interface{}{KeyUsername: "admin"}},
+			fields: map[string]interface{}{
+				KeyRequestID: nil,
+				KeyUsername:  "admin",
+			},
+		},
+		{
+			name: "background",
+			ctx:  context.Background(),
+			fields: map[string]interface{}{
+				KeyRequestID: nil,
+				KeyUsername:  nil,
+				KeyTraceID:   nil,
+				KeyRoute:     nil,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		L(tt.ctx).Info(tt.name)
+	}
+	Flush()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := map[string]map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for decoder.More() {
+		line := map[string]interface{}{}
+		if err := decoder.Decode(&line); err != nil {
+			t.Fatalf("decode log line failed: %v", err)
+		}
+		msg, _ := line["message"].(string)
+		lines[msg] = line
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, ok := lines[tt.name]
+			if !ok {
+				t.Fatalf("no log line %q in %s", tt.name, data)
+			}
+
+			for key, want := range tt.fields {
+				if got := line[key]; got != want {
+					t.Errorf("field %s = %v, want %v", key, got, want)
+				}
+			}
+		})
+	}
+}
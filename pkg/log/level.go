@@ -0,0 +1,181 @@
+package log
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// levels holds the level of the loggers built with it, which can be changed at runtime,
+// and the levels of the named loggers overriding it.
+type levels struct {
+	mu         sync.Mutex
+	configured zapcore.Level
+	level      zap.AtomicLevel
+
+	// overridden is set while the level is changed at runtime, configuring does not reset it.
+	overridden bool
+
+	// named holds a namedLevels, which is replaced on every change so logging never locks.
+	named atomic.Value
+}
+
+// namedLevels are the levels of named loggers and the lowest of them.
+type namedLevels struct {
+	levels map[string]zapcore.Level
+	min    zapcore.Level
+}
+
+// lvls are the levels of the global logger, configured by Init and changed at runtime.
+var lvls = newLevels(zapcore.InfoLevel)
+
+func newLevels(level zapcore.Level) *levels {
+	l := &levels{configured: level, level: zap.NewAtomicLevelAt(level)}
+	l.named.Store(&namedLevels{})
+
+	return l
+}
+
+// configure sets the level given in the options the loggers are built with, a level
+// changed at runtime is kept.
+func (l *levels) configure(level zapcore.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.configured = level
+	if !l.overridden {
+		l.level.SetLevel(level)
+	}
+}
+
+// set changes the level at runtime, setting the configured level ends the override.
+func (l *levels) set(level zapcore.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.overridden = level != l.configured
+	l.level.SetLevel(level)
+}
+
+func (l *levels) setNamed(name string, level *zapcore.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	named := &namedLevels{levels: map[string]zapcore.Level{}, min: zapcore.FatalLevel}
+	for n, lvl := range l.named.Load().(*namedLevels).levels {
+		named.levels[n] = lvl
+	}
+
+	if level != nil {
+		named.levels[name] = *level
+	} else {
+		delete(named.levels, name)
+	}
+
+	for _, lvl := range named.levels {
+		if lvl < named.min {
+			named.min = lvl
+		}
+	}
+
+	l.named.Store(named)
+}
+
+// enabled reports whether the logger with the given name logs at the given level. The level
+// of the closest named ancestor is used, like the one of a for a.b, else the level of all loggers.
+func (l *levels) enabled(name string, level zapcore.Level) bool {
+	named := l.named.Load().(*namedLevels)
+	for len(named.levels) > 0 && name != "" {
+		if lvl, ok := named.levels[name]; ok {
+			return lvl.Enabled(level)
+		}
+
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+
+	return l.level.Enabled(level)
+}
+
+// anyEnabled reports whether any logger logs at the given level.
+func (l *levels) anyEnabled(level zapcore.Level) bool {
+	named := l.named.Load().(*namedLevels)
+
+	return l.level.Enabled(level) || (len(named.levels) > 0 && named.min.Enabled(level))
+}
+
+// levelCore filters the entries of a core by the levels of the loggers, the core itself
+// must be enabled at all levels.
+type levelCore struct {
+	zapcore.Core
+	levels *levels
+}
+
+func newLevelCore(levels *levels) zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &levelCore{Core: core, levels: levels}
+	})
+}
+
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	return c.levels.anyEnabled(level)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), levels: c.levels}
+}
+
+func (c *levelCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.levels.enabled(entry.LoggerName, entry.Level) {
+		return ce
+	}
+
+	return c.Core.Check(entry, ce)
+}
+
+// SetLevel changes the level of the global logger at runtime, except the named loggers
+// with their own level. The level is kept when Init configures another one, until it is
+// set to the configured level again.
+func SetLevel(level Level) {
+	lvls.set(level)
+}
+
+// GetLevel returns the level of the global logger.
+func GetLevel() Level {
+	return lvls.level.Level()
+}
+
+// ConfiguredLevel returns the level given in the options the global logger was initialized with.
+func ConfiguredLevel() Level {
+	lvls.mu.Lock()
+	defer lvls.mu.Unlock()
+
+	return lvls.configured
+}
+
+// SetNamedLevel changes the level of the logger with the given name, see WithName, and of
+// its descendants at runtime.
+func SetNamedLevel(name string, level Level) {
+	lvls.setNamed(name, &level)
+}
+
+// UnsetNamedLevel makes the logger with the given name use the level of its ancestors again.
+func UnsetNamedLevel(name string) {
+	lvls.setNamed(name, nil)
+}
+
+// NamedLevels returns the levels of the named loggers having their own level.
+func NamedLevels() map[string]Level {
+	named := map[string]Level{}
+	for name, level := range lvls.named.Load().(*namedLevels).levels {
+		named[name] = level
+	}
+
+	return named
+}
@@ -0,0 +1,103 @@
+package log
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSetNamedLevel(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "log")
+	opts := NewOptions()
+	opts.OutputPaths = []string{out}
+	opts.Format = jsonFormat
+	Init(opts)
+	t.Cleanup(func() {
+		UnsetNamedLevel("cache")
+		SetLevel(ConfiguredLevel())
+		Init(NewOptions())
+	})
+
+	WithName("cache").Debug("cache debug")
+	SetNamedLevel("cache", DebugLevel)
+	WithName("cache").WithName("redis").Debug("redis debug")
+	Debug("debug")
+
+	SetLevel(WarnLevel)
+	Info("info")
+	WithName("cache").Info("cache info")
+	WithName("cached").Info("cached info")
+
+	UnsetNamedLevel("cache")
+	WithName("cache").Info("cache info after unset")
+	Flush()
+
+	if GetLevel() != WarnLevel || ConfiguredLevel() != InfoLevel {
+		t.Errorf("level = %s, configured level = %s, want warn and info", GetLevel(), ConfiguredLevel())
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var messages []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var entry struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		messages = append(messages, entry.Message)
+	}
+
+	if want := []string{"redis debug", "cache info"}; !reflect.DeepEqual(messages, want) {
+		t.Errorf("logged %v, want %v", messages, want)
+	}
+}
+
+func TestInitKeepsLevel(t *testing.T) {
+	opts := NewOptions()
+	opts.Level = "debug"
+	Init(opts)
+	t.Cleanup(func() {
+		SetLevel(ConfiguredLevel())
+		Init(NewOptions())
+	})
+
+	// building a logger does not configure the global level
+	opts.Level = "error"
+	if logger := New(opts); logger.zapLogger.Core().Enabled(WarnLevel) || GetLevel() != DebugLevel {
+		t.Errorf("new logger enables warn or global level = %s, want debug", GetLevel())
+	}
+
+	tests := []struct {
+		name           string
+		set            Level
+		init           string
+		wantLevel      Level
+		wantConfigured Level
+	}{
+		{name: "not overridden", set: DebugLevel, init: "info", wantLevel: InfoLevel, wantConfigured: InfoLevel},
+		{name: "overridden", set: ErrorLevel, init: "warn", wantLevel: ErrorLevel, wantConfigured: WarnLevel},
+		{name: "override reverted", set: WarnLevel, init: "debug", wantLevel: DebugLevel, wantConfigured: DebugLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetLevel(tt.set)
+			opts := NewOptions()
+			opts.Level = tt.init
+			Init(opts)
+
+			if GetLevel() != tt.wantLevel || ConfiguredLevel() != tt.wantConfigured {
+				t.Errorf("level = %s, configured level = %s, want %s and %s",
+					GetLevel(), ConfiguredLevel(), tt.wantLevel, tt.wantConfigured)
+			}
+		})
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"reflect"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -152,30 +154,77 @@ func handleFields(l *zap.Logger, args []interface{}, additional ...zap.Field) []
 }
 
 var (
-	std = New(NewOptions())
-	mu  sync.Mutex
+	std      = New(NewOptions())
+	stdOpts  = NewOptions()
+	closeStd = func() {}
+	mu       sync.Mutex
 )
 
-// Init initializes logger with specified options.
+// Init initializes logger with specified options, the levels changed at runtime are kept.
 func Init(opts *Options) {
+	if opts == nil {
+		opts = NewOptions()
+	}
+
 	mu.Lock()
 	defer mu.Unlock()
-	std = New(opts)
+
+	lvls.configure(opts.level())
+	replaceStd(opts)
+
+	if opts.DisableColor != nil {
+		std.Warn("The log.disable-color configuration is deprecated, use log.enable-color instead")
+	}
+}
+
+// Reload applies the changed options to the global logger. The level is changed in
+// place, the logger is only rebuilt when the other options change, e.g. its output
+// paths. The levels changed at runtime are kept.
+func Reload(opts *Options) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	lvls.configure(opts.level())
+
+	current, reloaded := *stdOpts, *opts
+	current.Level, reloaded.Level = "", ""
+	if reflect.DeepEqual(current, reloaded) {
+		stdOpts.Level = opts.Level
+
+		return
+	}
+
+	replaceStd(opts)
+}
+
+// replaceStd replaces the global logger and closes the sinks of the previous one.
+func replaceStd(opts *Options) {
+	logger, closeSinks := newLogger(opts, lvls)
+	current := *opts
+	std, stdOpts = logger, &current
+
+	closeStd()
+	closeStd = closeSinks
 }
 
-// New create logger by opts which can custmoized by command arguments.
+// New create logger by opts which can custmoized by command arguments. Its level is
+// not changed by SetLevel and SetNamedLevel, which change the level of the global logger.
 func New(opts *Options) *zapLogger {
 	if opts == nil {
 		opts = NewOptions()
 	}
 
-	var zapLevel zapcore.Level
-	if err := zapLevel.UnmarshalText([]byte(opts.Level)); err != nil {
-		zapLevel = zapcore.InfoLevel
-	}
+	logger, _ := newLogger(opts, newLevels(opts.level()))
+
+	return logger
+}
+
+// newLogger creates a logger whose entries are filtered by levels, it returns the function
+// closing its sinks.
+func newLogger(opts *Options, levels *levels) (*zapLogger, func()) {
 	encodeLevel := zapcore.CapitalLevelEncoder
 	// when output to local path, with color is forbidden
-	if opts.Format == consoleFormat && opts.EnableColor {
+	if opts.Format == consoleFormat && opts.colored() {
 		encodeLevel = zapcore.CapitalColorLevelEncoder
 	}
 
@@ -193,26 +242,38 @@ func New(opts *Options) *zapLogger {
 		EncodeCaller:   zapcore.ShortCallerEncoder,
 	}
 
-	loggerConfig := &zap.Config{
-		Level:             zap.NewAtomicLevelAt(zapLevel),
-		Development:       opts.Development,
-		DisableCaller:     opts.DisableCaller,
-		DisableStacktrace: opts.DisableStacktrace,
-		Sampling: &zap.SamplingConfig{
-			Initial:    100,
-			Thereafter: 100,
-		},
-		Encoding:         opts.Format,
-		EncoderConfig:    encoderConfig,
-		OutputPaths:      opts.OutputPaths,
-		ErrorOutputPaths: opts.ErrorOutputPaths,
+	encoder := zapcore.NewConsoleEncoder(encoderConfig)
+	if opts.Format == jsonFormat {
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
 	}
 
-	var err error
-	l, err := loggerConfig.Build(zap.AddStacktrace(zapcore.PanicLevel), zap.AddCallerSkip(1))
+	// the sinks are opened here rather than by zap.Config, so that they can be closed
+	out, closeOut, err := zap.Open(rotatePaths(opts.OutputPaths, opts)...)
 	if err != nil {
 		panic(err)
 	}
+	errOut, closeErrOut, err := zap.Open(rotatePaths(opts.ErrorOutputPaths, opts)...)
+	if err != nil {
+		closeOut()
+		panic(err)
+	}
+
+	// the level is checked by the level core, which allows to change it at runtime
+	core := zapcore.NewSamplerWithOptions(zapcore.NewCore(encoder, out, zapcore.DebugLevel), time.Second, 100, 100)
+	zapOpts := []zap.Option{
+		zap.ErrorOutput(errOut),
+		zap.AddStacktrace(zapcore.PanicLevel),
+		zap.AddCallerSkip(1),
+		newLevelCore(levels),
+	}
+	if opts.Development {
+		zapOpts = append(zapOpts, zap.Development())
+	}
+	if !opts.DisableCaller {
+		zapOpts = append(zapOpts, zap.AddCaller())
+	}
+
+	l := zap.New(core, zapOpts...)
 	logger := &zapLogger{
 		zapLogger: l.Named(opts.Name),
 		infoLogger: infoLogger{
@@ -223,7 +284,10 @@ func New(opts *Options) *zapLogger {
 
 	zap.RedirectStdLog(l)
 
-	return logger
+	return logger, func() {
+		closeOut()
+		closeErrOut()
+	}
 }
 
 // SugaredLogger returns global sugared logger.
@@ -506,15 +570,24 @@ func L(ctx context.Context) *zapLogger {
 func (l *zapLogger) L(ctx context.Context) *zapLogger {
 	lg := l.clone()
 
-	if requestID := ctx.Value(KeyRequestID); requestID != nil {
+	if requestID := FieldFrom(ctx, KeyRequestID); requestID != nil {
 		lg.zapLogger = lg.zapLogger.With(zap.Any(KeyRequestID, requestID))
 	}
-	if username := ctx.Value(KeyUsername); username != nil {
+	if username := FieldFrom(ctx, KeyUsername); username != nil {
 		lg.zapLogger = lg.zapLogger.With(zap.Any(KeyUsername, username))
 	}
-	if watcherName := ctx.Value(KeyWatcherName); watcherName != nil {
+	if operator := FieldFrom(ctx, KeyOperator); operator != nil && operator != "" {
+		lg.zapLogger = lg.zapLogger.With(zap.Any(KeyOperator, operator))
+	}
+	if watcherName := FieldFrom(ctx, KeyWatcherName); watcherName != nil {
 		lg.zapLogger = lg.zapLogger.With(zap.Any(KeyWatcherName, watcherName))
 	}
+	if traceID := FieldFrom(ctx, KeyTraceID); traceID != nil && traceID != "" {
+		lg.zapLogger = lg.zapLogger.With(zap.Any(KeyTraceID, traceID))
+	}
+	if route := FieldFrom(ctx, KeyRoute); route != nil && route != "" {
+		lg.zapLogger = lg.zapLogger.With(zap.Any(KeyRoute, route))
+	}
 
 	return lg
 }
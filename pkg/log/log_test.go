@@ -0,0 +1,105 @@
+package log
+
+import (
+	"io"
+	"net/url"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// closeSink discards the logs and counts its closes, it is opened by the urls of the close
+// scheme, like close://name.
+type closeSink struct {
+	zapcore.WriteSyncer
+	name string
+}
+
+var (
+	closesMu sync.Mutex
+	closes   = map[string]int{}
+)
+
+func (s closeSink) Close() error {
+	closesMu.Lock()
+	defer closesMu.Unlock()
+
+	closes[s.name]++
+
+	return nil
+}
+
+func init() {
+	if err := zap.RegisterSink("close", func(u *url.URL) (zap.Sink, error) {
+		return closeSink{WriteSyncer: zapcore.AddSync(io.Discard), name: u.Host}, nil
+	}); err != nil {
+		panic(err)
+	}
+}
+
+func TestReload(t *testing.T) {
+	closed := func(name string) int {
+		closesMu.Lock()
+		defer closesMu.Unlock()
+
+		return closes[name]
+	}
+
+	opts := NewOptions()
+	opts.OutputPaths = []string{"close://first"}
+	Init(opts)
+	t.Cleanup(func() {
+		SetLevel(ConfiguredLevel())
+		Init(NewOptions())
+	})
+	SetLevel(ErrorLevel)
+
+	// a level change keeps the logger, its sinks and the runtime level
+	logger := std
+	reloaded := *opts
+	reloaded.Level = "warn"
+	Reload(&reloaded)
+	if std != logger || closed("first") != 0 {
+		t.Errorf("logger rebuilt on a level change, first sink closed %d times", closed("first"))
+	}
+	if GetLevel() != ErrorLevel || ConfiguredLevel() != WarnLevel {
+		t.Errorf("level = %s, configured level = %s, want error and warn", GetLevel(), ConfiguredLevel())
+	}
+
+	// other output paths rebuild the logger and close the sinks of the previous one
+	reloaded.OutputPaths = []string{"close://second"}
+	Reload(&reloaded)
+	if std == logger || closed("first") != 1 || closed("second") != 0 {
+		t.Errorf("logger not rebuilt on an output change, sinks closed %d and %d times", closed("first"), closed("second"))
+	}
+	if GetLevel() != ErrorLevel {
+		t.Errorf("level = %s after rebuild, want error", GetLevel())
+	}
+}
+
+func TestOptionsColored(t *testing.T) {
+	enabled, disabled := true, false
+	tests := []struct {
+		name         string
+		enableColor  bool
+		disableColor *bool
+		want         bool
+	}{
+		{name: "default", want: false},
+		{name: "enable-color", enableColor: true, want: true},
+		{name: "deprecated disable-color enabling the colors", disableColor: &enabled, want: true},
+		{name: "deprecated disable-color", disableColor: &disabled, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := NewOptions()
+			opts.EnableColor, opts.DisableColor = tt.enableColor, tt.disableColor
+			if got := opts.colored(); got != tt.want {
+				t.Errorf("colored() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -23,9 +23,20 @@ type Options struct {
 	Format            string   `json:"format"             mapstructure:"format"`
 	DisableCaller     bool     `json:"disable-caller"     mapstructure:"disable-caller"`
 	DisableStacktrace bool     `json:"disable-stacktrace" mapstructure:"disable-stacktrace"`
-	EnableColor       bool     `json:"disable-color"      mapstructure:"disable-color"`
+	EnableColor       bool     `json:"enable-color"       mapstructure:"enable-color"`
 	Development       bool     `json:"development"        mapstructure:"development"`
 	Name              string   `json:"name"               mapstructure:"name"`
+
+	// DisableColor is the deprecated key EnableColor was read from, its value still enables
+	// the colors as it did.
+	DisableColor *bool `json:"disable-color,omitempty" mapstructure:"disable-color" deprecated:"use enable-color instead"`
+
+	// Rotation of the file output paths, disabled when MaxSize is zero.
+	MaxSize    int  `json:"max-size"    mapstructure:"max-size"`
+	MaxAge     int  `json:"max-age"     mapstructure:"max-age"`
+	MaxBackups int  `json:"max-backups" mapstructure:"max-backups"`
+	Compress   bool `json:"compress"    mapstructure:"compress"`
+	LocalTime  bool `json:"local-time"  mapstructure:"local-time"`
 }
 
 // NewOptions creates a Options object with default parameters.
@@ -39,6 +50,10 @@ func NewOptions() *Options {
 		DisableStacktrace: false,
 		EnableColor:       false,
 		Development:       false,
+		MaxSize:           0,
+		MaxAge:            30,
+		MaxBackups:        10,
+		LocalTime:         true,
 	}
 }
 
@@ -56,6 +71,10 @@ func (o *Options) Validate() []error {
 		errs = append(errs, fmt.Errorf("Not a valid log format: %q", o.Format))
 	}
 
+	if o.MaxSize < 0 || o.MaxAge < 0 || o.MaxBackups < 0 {
+		errs = append(errs, fmt.Errorf("--log.max-size, --log.max-age and --log.max-backups can not be negative"))
+	}
+
 	return errs
 }
 
@@ -77,6 +96,16 @@ func (o *Options) AddFlags(fs *pflag.FlagSet) {
 			"the behavior of DPanicLevel and takes stacktraces more liberally.",
 	)
 	fs.StringVar(&o.Name, "log.name", o.Name, "The name of the logger.")
+	fs.IntVar(&o.MaxSize, "log.max-size", o.MaxSize, ""+
+		"Maximum size in megabytes of the log files before they are rotated. Set to zero to disable rotation. "+
+		"Output paths with the rotate:// scheme are always rotated, by the options in their query.")
+	fs.IntVar(&o.MaxAge, "log.max-age", o.MaxAge, ""+
+		"Maximum number of days to keep the rotated log files. Set to zero to keep them regardless of age.")
+	fs.IntVar(&o.MaxBackups, "log.max-backups", o.MaxBackups, ""+
+		"Maximum number of rotated log files to keep. Set to zero to keep all of them.")
+	fs.BoolVar(&o.Compress, "log.compress", o.Compress, "Compress the rotated log files with gzip.")
+	fs.BoolVar(&o.LocalTime, "log.local-time", o.LocalTime, ""+
+		"Use the local time instead of UTC in the timestamps of the rotated log file names.")
 }
 
 func (o *Options) String() string {
@@ -87,18 +116,13 @@ func (o *Options) String() string {
 
 // Build constructs a global zap logger from the Config and Options.
 func (o *Options) Build() error {
-	var zapLevel zapcore.Level
-	if err := zapLevel.UnmarshalText([]byte(o.Level)); err != nil {
-		zapLevel = zapcore.InfoLevel
-	}
-
 	encodeLevel := zapcore.CapitalLevelEncoder
-	if o.Format == consoleFormat && o.EnableColor {
+	if o.Format == consoleFormat && o.colored() {
 		encodeLevel = zapcore.CapitalColorLevelEncoder
 	}
 
 	zc := &zap.Config{
-		Level:             zap.NewAtomicLevelAt(zapLevel),
+		Level:             zap.NewAtomicLevelAt(zapcore.DebugLevel),
 		Development:       o.Development,
 		DisableCaller:     o.DisableCaller,
 		DisableStacktrace: o.DisableStacktrace,
@@ -121,11 +145,12 @@ func (o *Options) Build() error {
 			EncodeCaller:   zapcore.ShortCallerEncoder,
 			EncodeName:     zapcore.FullNameEncoder,
 		},
-		OutputPaths:      o.OutputPaths,
-		ErrorOutputPaths: o.ErrorOutputPaths,
+		OutputPaths:      rotatePaths(o.OutputPaths, o),
+		ErrorOutputPaths: rotatePaths(o.ErrorOutputPaths, o),
 	}
 
-	logger, err := zc.Build(zap.AddStacktrace(zapcore.PanicLevel))
+	// the level is checked by the level core, the global levels are only configured by Init
+	logger, err := zc.Build(zap.AddStacktrace(zapcore.PanicLevel), newLevelCore(newLevels(o.level())))
 	if err != nil {
 		return err
 	}
@@ -134,3 +159,18 @@ func (o *Options) Build() error {
 
 	return nil
 }
+
+// level returns the zap level of the options, info when it is invalid.
+func (o *Options) level() zapcore.Level {
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(o.Level)); err != nil {
+		return zapcore.InfoLevel
+	}
+
+	return level
+}
+
+// colored reports whether the levels are colored, by enable-color or the deprecated disable-color.
+func (o *Options) colored() bool {
+	return o.EnableColor || (o.DisableColor != nil && *o.DisableColor)
+}
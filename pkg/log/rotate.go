@@ -0,0 +1,156 @@
+package log
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"go.uber.org/zap"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// rotateScheme is the scheme of the output paths written to files rotated by size and
+// pruned by age and count, like rotate:///var/log/iam.log?max-size=100&max-backups=10.
+// The query parameters are max-size in megabytes, max-age in days, max-backups, compress
+// and local-time, see lumberjack.Logger.
+const rotateScheme = "rotate"
+
+var (
+	rotateMu    sync.Mutex
+	rotateSinks = map[string]*rotateSink{} // by file name
+)
+
+func init() {
+	if err := zap.RegisterSink(rotateScheme, newRotateSink); err != nil {
+		panic(err)
+	}
+}
+
+// rotateSink writes to a rotated file. Sinks are shared by file name, as the loggers built
+// for the same file must not rotate it behind the back of each other.
+type rotateSink struct {
+	mu     sync.Mutex
+	logger *lumberjack.Logger
+}
+
+func (s *rotateSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.logger.Write(p)
+}
+
+// Sync does nothing, lumberjack does not buffer writes.
+func (s *rotateSink) Sync() error {
+	return nil
+}
+
+// Close closes the file, which is opened again by the next write.
+func (s *rotateSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.logger.Close()
+}
+
+// replace closes the file written with other rotation options, the next writes use the
+// new logger.
+func (s *rotateSink) replace(logger *lumberjack.Logger) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old := s.logger
+	if old.MaxSize == logger.MaxSize && old.MaxAge == logger.MaxAge && old.MaxBackups == logger.MaxBackups &&
+		old.Compress == logger.Compress && old.LocalTime == logger.LocalTime {
+		return nil
+	}
+
+	s.logger = logger
+
+	return old.Close()
+}
+
+func newRotateSink(u *url.URL) (zap.Sink, error) {
+	logger := &lumberjack.Logger{Filename: u.Host + u.Path}
+	if logger.Filename == "" {
+		return nil, fmt.Errorf("no file in log output path %s", u)
+	}
+
+	query := u.Query()
+	ints := map[string]*int{"max-size": &logger.MaxSize, "max-age": &logger.MaxAge, "max-backups": &logger.MaxBackups}
+	for name, value := range ints {
+		if v := query.Get(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid %s %q in log output path %s", name, v, u)
+			}
+			*value = n
+		}
+	}
+
+	bools := map[string]*bool{"compress": &logger.Compress, "local-time": &logger.LocalTime}
+	for name, value := range bools {
+		if v := query.Get(name); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q in log output path %s", name, v, u)
+			}
+			*value = b
+		}
+	}
+
+	rotateMu.Lock()
+	defer rotateMu.Unlock()
+
+	// the loggers built before write with the new options too
+	if sink, ok := rotateSinks[logger.Filename]; ok {
+		if err := sink.replace(logger); err != nil {
+			return nil, fmt.Errorf("close log file %s: %w", logger.Filename, err)
+		}
+
+		return sink, nil
+	}
+
+	sink := &rotateSink{logger: logger}
+	rotateSinks[logger.Filename] = sink
+
+	return sink, nil
+}
+
+// rotatePaths returns the output paths with the file paths replaced by rotate urls holding
+// the rotation options. The paths are returned unchanged when rotation is disabled.
+func rotatePaths(paths []string, opts *Options) []string {
+	if opts.MaxSize == 0 {
+		return paths
+	}
+
+	query := url.Values{}
+	query.Set("max-size", strconv.Itoa(opts.MaxSize))
+	query.Set("max-age", strconv.Itoa(opts.MaxAge))
+	query.Set("max-backups", strconv.Itoa(opts.MaxBackups))
+	query.Set("compress", strconv.FormatBool(opts.Compress))
+	query.Set("local-time", strconv.FormatBool(opts.LocalTime))
+
+	rotated := make([]string, 0, len(paths))
+	for _, path := range paths {
+		file := path
+		if u, err := url.Parse(path); err == nil && u.Scheme == "file" {
+			file = u.Path
+		} else if path == "stdout" || path == "stderr" || (err == nil && u.Scheme != "") {
+			rotated = append(rotated, path)
+
+			continue
+		}
+
+		if abs, err := filepath.Abs(file); err == nil {
+			file = abs
+		}
+
+		u := url.URL{Scheme: rotateScheme, Path: filepath.ToSlash(file), RawQuery: query.Encode()}
+		rotated = append(rotated, u.String())
+	}
+
+	return rotated
+}
@@ -0,0 +1,114 @@
+package log
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatePaths(t *testing.T) {
+	opts := NewOptions()
+	if paths := rotatePaths([]string{"/var/log/iam.log"}, opts); paths[0] != "/var/log/iam.log" {
+		t.Errorf("rotatePaths() by default = %v", paths)
+	}
+
+	opts.MaxSize = 100
+	paths := rotatePaths([]string{"stdout", "/var/log/iam.log", "file:///var/log/iam.error.log", "rotate:///tmp/a.log"}, opts)
+
+	want := []string{
+		"stdout",
+		"rotate:///var/log/iam.log?compress=false&local-time=true&max-age=30&max-backups=10&max-size=100",
+		"rotate:///var/log/iam.error.log?compress=false&local-time=true&max-age=30&max-backups=10&max-size=100",
+		"rotate:///tmp/a.log",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("rotatePaths() = %v, want %v", paths, want)
+	}
+
+}
+
+func TestNewRotateSink(t *testing.T) {
+	file := filepath.ToSlash(filepath.Join(t.TempDir(), "iam.log"))
+	open := func(query string) *rotateSink {
+		t.Helper()
+
+		u, err := url.Parse("rotate://" + file + "?" + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sink, err := newRotateSink(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return sink.(*rotateSink)
+	}
+
+	first := open("max-size=1&max-backups=2")
+	t.Cleanup(func() { _ = first.Close() })
+	if _, err := first.Write([]byte("first\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	// the same options in another order share the logger
+	logger := first.logger
+	if second := open("max-backups=2&max-size=1"); second != first || second.logger != logger {
+		t.Error("sink of the same file and options is not shared")
+	}
+
+	// other options replace the logger of the shared sink
+	if third := open("max-size=2"); third != first || third.logger == logger || third.logger.MaxSize != 2 {
+		t.Errorf("sink of the same file and other options is not replaced, max size = %d", third.logger.MaxSize)
+	}
+	if _, err := first.Write([]byte("third\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "first\nthird\n" {
+		t.Errorf("log file holds %q", data)
+	}
+}
+
+func TestRotateSink(t *testing.T) {
+	dir := t.TempDir()
+	opts := NewOptions()
+	opts.OutputPaths = []string{filepath.Join(dir, "iam.log")}
+	opts.MaxSize = 1
+	opts.MaxBackups = 2
+	t.Cleanup(func() { Init(NewOptions()) })
+	Init(opts)
+
+	// 4 megabytes of distinct logs, which are not sampled, rotate the file thrice
+	line := strings.Repeat("x", 1024)
+	for i := 0; i < 4*1024; i++ {
+		Infof("%d %s", i, line)
+	}
+	Flush()
+
+	// only 2 rotated files are kept, they are removed in the background
+	var files []os.DirEntry
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		var err error
+		if files, err = os.ReadDir(dir); err != nil {
+			t.Fatal(err)
+		}
+		if len(files) == 3 {
+			break
+		}
+	}
+	if len(files) != 3 {
+		var names []string
+		for _, f := range files {
+			names = append(names, f.Name())
+		}
+		t.Errorf("log dir holds %v, want the log file and 2 rotated files", names)
+	}
+}
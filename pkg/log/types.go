@@ -17,7 +17,10 @@ import (
 const (
 	KeyRequestID   = "requestID"
 	KeyUsername    = "username"
+	KeyOperator    = "operator"
 	KeyWatcherName = "watcher"
+	KeyTraceID     = "traceID"
+	KeyRoute       = "route"
 )
 
 // Field is an alias for the field structure in the underlying log frame.
@@ -0,0 +1,61 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/rose839/IAM/pkg/log"
+)
+
+// FileNotifier appends messages to a file as JSON lines.
+type FileNotifier struct {
+	mu   sync.Mutex
+	path string
+}
+
+var _ Notifier = &FileNotifier{}
+
+// NewFileNotifier create a file notifier which writes to path.
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{path: path}
+}
+
+// Notify appends msg to the file.
+func (f *FileNotifier) Notify(ctx context.Context, msg *Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(data, '\n'))
+
+	return err
+}
+
+// LogNotifier writes messages to the log, with their secrets masked.
+type LogNotifier struct{}
+
+var _ Notifier = &LogNotifier{}
+
+// NewLogNotifier create a log notifier.
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+// Notify writes msg to the log.
+func (l *LogNotifier) Notify(ctx context.Context, msg *Message) error {
+	log.L(ctx).Infow("Notification", "to", msg.To, "subject", msg.Subject, "body", msg.RedactedBody())
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFileNotifier_Notify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.log")
+	n := NewFileNotifier(path)
+
+	msgs := []*Message{
+		{To: []string{"colin@foxmail.com"}, Subject: "first", Body: "token: abc\nbye"},
+		{To: []string{"a@foxmail.com", "b@foxmail.com"}, Subject: "second", Body: "token: def"},
+	}
+	for _, msg := range msgs {
+		if err := n.Notify(context.Background(), msg); err != nil {
+			t.Fatalf("Notify() error = %v", err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var got []*Message
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		msg := &Message{}
+		if err := json.Unmarshal(scanner.Bytes(), msg); err != nil {
+			t.Fatalf("unmarshal %q: %v", scanner.Text(), err)
+		}
+		got = append(got, msg)
+	}
+
+	if !reflect.DeepEqual(got, msgs) {
+		t.Errorf("got %+v, want %+v", got, msgs)
+	}
+}
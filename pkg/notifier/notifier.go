@@ -0,0 +1,37 @@
+// Package notifier delivers messages such as password reset and email verification
+// tokens to users. Notifiers are pluggable, SMTP is used in production while the file
+// and log implementations are useful in development and tests.
+package notifier
+
+import (
+	"context"
+	"strings"
+)
+
+// Message is a notification sent to one or more recipients.
+type Message struct {
+	To      []string `json:"to"`
+	Subject string   `json:"subject"`
+	Body    string   `json:"body"`
+
+	// Secrets are the values of the body only the recipients may read, like tokens.
+	Secrets []string `json:"-"`
+}
+
+// RedactedBody returns the body with the secrets masked, for notifiers which do not
+// deliver the message to its recipients only.
+func (m *Message) RedactedBody() string {
+	body := m.Body
+	for _, secret := range m.Secrets {
+		if secret != "" {
+			body = strings.ReplaceAll(body, secret, "******")
+		}
+	}
+
+	return body
+}
+
+// Notifier defines the interface to deliver a message.
+type Notifier interface {
+	Notify(ctx context.Context, msg *Message) error
+}
@@ -0,0 +1,22 @@
+package notifier
+
+import "testing"
+
+func TestMessage_RedactedBody(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  *Message
+		want string
+	}{
+		{"no secrets", &Message{Body: "token: abc"}, "token: abc"},
+		{"secret", &Message{Body: "token: abc\nagain abc", Secrets: []string{"abc"}}, "token: ******\nagain ******"},
+		{"empty secret", &Message{Body: "token: abc", Secrets: []string{""}}, "token: abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.msg.RedactedBody(); got != tt.want {
+				t.Errorf("RedactedBody() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+)
+
+// SMTPConfig defines the configuration of the smtp notifier.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPNotifier delivers messages as plain text emails. STARTTLS is used when the
+// server supports it.
+type SMTPNotifier struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+var _ Notifier = &SMTPNotifier{}
+
+// NewSMTPNotifier create a smtp notifier with the given config.
+func NewSMTPNotifier(config *SMTPConfig) *SMTPNotifier {
+	var auth smtp.Auth
+	if config.Username != "" {
+		auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
+	}
+
+	return &SMTPNotifier{
+		addr: net.JoinHostPort(config.Host, strconv.Itoa(config.Port)),
+		auth: auth,
+		from: config.From,
+	}
+}
+
+// Notify sends msg as an email.
+func (s *SMTPNotifier) Notify(ctx context.Context, msg *Message) error {
+	if len(msg.To) == 0 {
+		return fmt.Errorf("no recipient")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	return smtp.SendMail(s.addr, s.auth, s.from, msg.To, []byte(b.String()))
+}
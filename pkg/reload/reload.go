@@ -0,0 +1,165 @@
+/*
+Package reload Providing reload callbacks for applying configuration changes without restart
+
+# Example - posix signals
+
+Graceful reload will listen for posix SIGHUP signals.
+When they are received it will run all callbacks in the order they were added.
+
+	package main
+
+	import (
+		"fmt"
+		"time"
+
+		"github.com/rose839/IAM/pkg/reload"
+		"github.com/rose839/IAM/pkg/reload/reloadmanagers/posixsignal"
+	)
+
+	func main() {
+		// initialize reload
+		gr := reload.New()
+
+		// add posix reload manager
+		gr.AddReloadManager(posixsignal.NewPosixSignalManager())
+
+		// add your tasks that implement ReloadCallback
+		gr.AddReloadCallback(reload.ReloadFunc(func(string) error {
+			fmt.Println("Reload callback")
+			return nil
+		}))
+
+		// start reload managers
+		if err := gr.Start(); err != nil {
+			fmt.Println("Start:", err)
+			return
+		}
+
+		// do other stuff
+		time.Sleep(time.Hour)
+	}
+*/
+package reload
+
+import (
+	"sync"
+)
+
+// ReloadCallback is an interface you have to implement for callbacks.
+// OnReload will be called when reload is requested. The parameter
+// is the name of the ReloadManager that requested reload.
+type ReloadCallback interface {
+	OnReload(string) error
+}
+
+// ReloadFunc is a helper type, so you can easily provide anonymous functions
+// as ReloadCallbacks.
+type ReloadFunc func(string) error
+
+// OnReload defines the action needed to run when reload triggered.
+func (rf ReloadFunc) OnReload(reloadManagerName string) error {
+	return rf(reloadManagerName)
+}
+
+// ReloadManager is an interface implemented by ReloadManagers.
+// GetName returns the name of ReloadManager.
+// ReloadManagers start listening for reload requests in Start,
+// and call StartReload on RSInterface for every request.
+type ReloadManager interface {
+	GetName() string
+	Start(rs RSInterface) error
+}
+
+// ErrorHandler is an interface you can pass to SetErrorHandler to
+// handle asynchronous errors.
+type ErrorHandler interface {
+	OnError(err error)
+}
+
+// ErrorFunc is a helper type, so you can easily provide anonymous functions
+// as ErrorHandlers.
+type ErrorFunc func(err error)
+
+// OnError defines the action needed to run when error occurred.
+func (f ErrorFunc) OnError(err error) {
+	f(err)
+}
+
+// RSInterface is an interface implemented by GracefulReload,
+// that gets passed to ReloadManager to call StartReload when reload
+// is requested.
+type RSInterface interface {
+	StartReload(rm ReloadManager)
+	ReportError(err error)
+}
+
+// GracefulReload is main struct that handles ReloadCallbacks and
+// ReloadManagers. Initialize it with New.
+type GracefulReload struct {
+	// mu serializes the reloads requested by different managers.
+	mu           sync.Mutex
+	callbacks    []ReloadCallback
+	managers     []ReloadManager
+	errorHandler ErrorHandler
+}
+
+// New initializes GracefulReload.
+func New() *GracefulReload {
+	return &GracefulReload{
+		callbacks: make([]ReloadCallback, 0, 10),
+		managers:  make([]ReloadManager, 0, 3),
+	}
+}
+
+// Start calls Start on all added ReloadManagers. The ReloadManagers
+// start to listen to reload requests. Returns an error if any ReloadManagers
+// return an error.
+func (gr *GracefulReload) Start() error {
+	for _, manager := range gr.managers {
+		if err := manager.Start(gr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddReloadManager adds a ReloadManager that will listen to reload requests.
+func (gr *GracefulReload) AddReloadManager(manager ReloadManager) {
+	gr.managers = append(gr.managers, manager)
+}
+
+// AddReloadCallback adds a ReloadCallback that will be called when
+// reload is requested.
+func (gr *GracefulReload) AddReloadCallback(reloadCallback ReloadCallback) {
+	gr.mu.Lock()
+	defer gr.mu.Unlock()
+
+	gr.callbacks = append(gr.callbacks, reloadCallback)
+}
+
+// SetErrorHandler sets an ErrorHandler that will be called when an error
+// is encountered in ReloadCallback or in ReloadManager.
+func (gr *GracefulReload) SetErrorHandler(errorHandler ErrorHandler) {
+	gr.errorHandler = errorHandler
+}
+
+// StartReload is called from a ReloadManager and will initiate reload.
+// The ReloadCallbacks are called one after the other in the order they were added,
+// a reload requested while another one is running waits for it to finish.
+func (gr *GracefulReload) StartReload(rm ReloadManager) {
+	gr.mu.Lock()
+	defer gr.mu.Unlock()
+
+	for _, reloadCallback := range gr.callbacks {
+		gr.ReportError(reloadCallback.OnReload(rm.GetName()))
+	}
+}
+
+// ReportError is a function that can be used to report errors to
+// ErrorHandler. It is used in ReloadManagers.
+func (gr *GracefulReload) ReportError(err error) {
+	if err != nil && gr.errorHandler != nil {
+		gr.errorHandler.OnError(err)
+	}
+}
@@ -0,0 +1,91 @@
+package reload
+
+import (
+	"errors"
+	"testing"
+)
+
+type RMStartFunc func(rs RSInterface) error
+
+func (f RMStartFunc) GetName() string {
+	return "test-rm"
+}
+
+func (f RMStartFunc) Start(rs RSInterface) error {
+	return f(rs)
+}
+
+func TestCallbacksCalledInOrder(t *testing.T) {
+	gr := New()
+
+	var calls []int
+	for i := 0; i < 3; i++ {
+		i := i
+		gr.AddReloadCallback(ReloadFunc(func(name string) error {
+			if name != "test-rm" {
+				t.Errorf("reload manager name = %s, want test-rm", name)
+			}
+			calls = append(calls, i)
+
+			return nil
+		}))
+	}
+
+	gr.AddReloadManager(RMStartFunc(func(rs RSInterface) error {
+		rs.StartReload(RMStartFunc(nil))
+
+		return nil
+	}))
+
+	if err := gr.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	if len(calls) != 3 || calls[0] != 0 || calls[1] != 1 || calls[2] != 2 {
+		t.Errorf("callbacks called in order %v, want [0 1 2]", calls)
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	gr := New()
+
+	var reported []error
+	gr.SetErrorHandler(ErrorFunc(func(err error) {
+		reported = append(reported, err)
+	}))
+
+	callbackErr := errors.New("callback")
+	calls := 0
+	gr.AddReloadCallback(ReloadFunc(func(string) error {
+		calls++
+
+		return callbackErr
+	}))
+	gr.AddReloadCallback(ReloadFunc(func(string) error {
+		calls++
+
+		return nil
+	}))
+
+	gr.StartReload(RMStartFunc(nil))
+
+	if calls != 2 {
+		t.Errorf("callbacks called %d times, want 2", calls)
+	}
+	if len(reported) != 1 || reported[0] != callbackErr {
+		t.Errorf("reported errors = %v, want [%v]", reported, callbackErr)
+	}
+}
+
+func TestStartError(t *testing.T) {
+	gr := New()
+
+	startErr := errors.New("start")
+	gr.AddReloadManager(RMStartFunc(func(rs RSInterface) error {
+		return startErr
+	}))
+
+	if err := gr.Start(); err != startErr {
+		t.Errorf("Start() error = %v, want %v", err, startErr)
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Package filewatcher provides a listener for the changes of a file requesting a reload.
+The directory of the file is watched, so that files replaced by editors and the files
+of mounted kubernetes config maps, which are symlinks swapped on update, are followed.
+*/
+package filewatcher
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/rose839/IAM/pkg/reload"
+)
+
+// Name defines reload manager name.
+const Name = "FileWatcherManager"
+
+// defaultDelay coalesces the events of a single change of the file, like the remove,
+// create and write events of an editor saving it.
+const defaultDelay = 100 * time.Millisecond
+
+// FileWatcherManager implements ReloadManager interface that is added
+// to GracefulReload. Initialize with NewFileWatcherManager.
+type FileWatcherManager struct {
+	file  string
+	delay time.Duration
+
+	mu      sync.Mutex
+	watcher *fsnotify.Watcher
+}
+
+// NewFileWatcherManager initializes the FileWatcherManager watching the given file.
+func NewFileWatcherManager(file string) *FileWatcherManager {
+	return &FileWatcherManager{
+		file:  filepath.Clean(file),
+		delay: defaultDelay,
+	}
+}
+
+// GetName returns name of this ReloadManager.
+func (fileWatcherManager *FileWatcherManager) GetName() string {
+	return Name
+}
+
+// Start requests a reload every time the file is changed.
+func (fileWatcherManager *FileWatcherManager) Start(rs reload.RSInterface) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	if err := watcher.Add(filepath.Dir(fileWatcherManager.file)); err != nil {
+		_ = watcher.Close()
+
+		return err
+	}
+
+	fileWatcherManager.mu.Lock()
+	fileWatcherManager.watcher = watcher
+	fileWatcherManager.mu.Unlock()
+
+	// resolved before returning, so that the symlinks swapped from now on are noticed
+	realFile, _ := filepath.EvalSymlinks(fileWatcherManager.file)
+	go fileWatcherManager.watch(watcher, realFile, rs)
+
+	return nil
+}
+
+// Stop stops watching the file, no reload is requested afterwards. Stopping a
+// FileWatcherManager which is not started does nothing.
+func (fileWatcherManager *FileWatcherManager) Stop() error {
+	fileWatcherManager.mu.Lock()
+	defer fileWatcherManager.mu.Unlock()
+
+	if fileWatcherManager.watcher == nil {
+		return nil
+	}
+
+	err := fileWatcherManager.watcher.Close()
+	fileWatcherManager.watcher = nil
+
+	return err
+}
+
+func (fileWatcherManager *FileWatcherManager) watch(watcher *fsnotify.Watcher, realFile string, rs reload.RSInterface) {
+	file := fileWatcherManager.file
+
+	var timer *time.Timer
+	defer func() {
+		// the change noticed before stopping is not reloaded
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+
+			// the file itself is changed, or the symlink it resolves through is swapped
+			currentFile, _ := filepath.EvalSymlinks(file)
+			changed := filepath.Clean(event.Name) == file &&
+				event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0
+			if !changed && currentFile == realFile {
+				continue
+			}
+			realFile = currentFile
+
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.AfterFunc(fileWatcherManager.delay, func() {
+				rs.StartReload(fileWatcherManager)
+			})
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+
+			rs.ReportError(err)
+		}
+	}
+}
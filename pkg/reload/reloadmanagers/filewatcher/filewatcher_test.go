@@ -0,0 +1,131 @@
+package filewatcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rose839/IAM/pkg/reload"
+)
+
+type startReloadFunc func(rm reload.ReloadManager)
+
+func (f startReloadFunc) StartReload(rm reload.ReloadManager) {
+	f(rm)
+}
+
+func (f startReloadFunc) ReportError(err error) {
+
+}
+
+func waitReload(t *testing.T, c <-chan int) {
+	select {
+	case <-c:
+	case <-time.After(2 * time.Second):
+		t.Error("Timeout waiting for StartReload.")
+	}
+}
+
+func start(t *testing.T, file string) <-chan int {
+	c := make(chan int, 100)
+
+	fwm := NewFileWatcherManager(file)
+	if err := fwm.Start(startReloadFunc(func(rm reload.ReloadManager) {
+		c <- 1
+	})); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	return c
+}
+
+func writeFile(t *testing.T, file, content string) {
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStartReloadCalledOnChange(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "iam-apiserver.yaml")
+	writeFile(t, file, "a: 1\n")
+
+	c := start(t, file)
+
+	// other files in the directory are ignored
+	writeFile(t, filepath.Join(dir, "other.yaml"), "b: 1\n")
+	time.Sleep(3 * defaultDelay)
+	select {
+	case <-c:
+		t.Error("StartReload called for another file")
+	default:
+	}
+
+	writeFile(t, file, "a: 2\n")
+	waitReload(t, c)
+
+	// a file saved by an editor, replacing the old one
+	writeFile(t, file+".tmp", "a: 3\n")
+	if err := os.Rename(file+".tmp", file); err != nil {
+		t.Fatal(err)
+	}
+	waitReload(t, c)
+}
+
+func TestStartReloadCalledOnSymlinkSwap(t *testing.T) {
+	// the layout of a mounted kubernetes config map
+	dir := t.TempDir()
+	data := filepath.Join(dir, "..data")
+	for _, version := range []string{"v1", "v2"} {
+		if err := os.Mkdir(filepath.Join(dir, version), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, filepath.Join(dir, version, "iam-apiserver.yaml"), "version: "+version+"\n")
+	}
+	if err := os.Symlink("v1", data); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "iam-apiserver.yaml")
+	if err := os.Symlink(filepath.Join("..data", "iam-apiserver.yaml"), file); err != nil {
+		t.Fatal(err)
+	}
+
+	c := start(t, file)
+
+	if err := os.Symlink("v2", data+"_tmp"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(data+"_tmp", data); err != nil {
+		t.Fatal(err)
+	}
+	waitReload(t, c)
+}
+
+func TestStop(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "iam-apiserver.yaml")
+	writeFile(t, file, "a: 1\n")
+
+	c := make(chan int, 100)
+	fwm := NewFileWatcherManager(file)
+	if err := fwm.Stop(); err != nil {
+		t.Fatalf("Stop() before Start() error = %v", err)
+	}
+	if err := fwm.Start(startReloadFunc(func(rm reload.ReloadManager) {
+		c <- 1
+	})); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := fwm.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	writeFile(t, file, "a: 2\n")
+	time.Sleep(3 * defaultDelay)
+	select {
+	case <-c:
+		t.Error("StartReload called after Stop")
+	default:
+	}
+}
@@ -0,0 +1,54 @@
+/*
+Package posixsignal provides a listener for a posix signal requesting a reload. By default
+it listens for SIGHUP, but others can be chosen in NewPosixSignalManager.
+*/
+package posixsignal
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rose839/IAM/pkg/reload"
+)
+
+// Name defines reload manager name.
+const Name = "PosixSignalManager"
+
+// PosixSignalManager implements ReloadManager interface that is added
+// to GracefulReload. Initialize with NewPosixSignalManager.
+type PosixSignalManager struct {
+	signals []os.Signal
+}
+
+// NewPosixSignalManager initializes the PosixSignalManager.
+// As arguments you can provide os.Signal-s to listen to, if none are given,
+// it will default to SIGHUP.
+func NewPosixSignalManager(sig ...os.Signal) *PosixSignalManager {
+	if len(sig) == 0 {
+		sig = []os.Signal{syscall.SIGHUP}
+	}
+
+	return &PosixSignalManager{
+		signals: sig,
+	}
+}
+
+// GetName returns name of this ReloadManager.
+func (posixSignalManager *PosixSignalManager) GetName() string {
+	return Name
+}
+
+// Start requests a reload for every signal received.
+func (posixSignalManager *PosixSignalManager) Start(rs reload.RSInterface) error {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, posixSignalManager.signals...)
+
+	go func() {
+		for range c {
+			rs.StartReload(posixSignalManager)
+		}
+	}()
+
+	return nil
+}
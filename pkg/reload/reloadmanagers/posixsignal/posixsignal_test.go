@@ -0,0 +1,45 @@
+package posixsignal
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/rose839/IAM/pkg/reload"
+)
+
+type startReloadFunc func(rm reload.ReloadManager)
+
+func (f startReloadFunc) StartReload(rm reload.ReloadManager) {
+	f(rm)
+}
+
+func (f startReloadFunc) ReportError(err error) {
+
+}
+
+func waitSig(t *testing.T, c <-chan int) {
+	select {
+	case <-c:
+	case <-time.After(1 * time.Second):
+		t.Error("Timeout waiting for StartReload.")
+	}
+}
+
+func TestStartReloadCalledOnDefaultSignal(t *testing.T) {
+	c := make(chan int, 100)
+
+	psm := NewPosixSignalManager()
+	psm.Start(startReloadFunc(func(rm reload.ReloadManager) {
+		c <- 1
+	}))
+
+	time.Sleep(time.Millisecond)
+
+	// every signal requests a reload
+	syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	waitSig(t, c)
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	waitSig(t, c)
+}
@@ -99,8 +99,7 @@ func connectSingleton(config *Config) bool {
 
 func clusterConnectionIsOpen() bool {
 	c := singleton()
-	uuidNew, _ := uuid.NewV4()
-	testKey := "redis-test-" + uuid.Must(uuidNew, nil).String()
+	testKey := "redis-test-" + uuid.NewV4().String()
 	if err := c.Set(testKey, "test", time.Second).Err(); err != nil {
 		log.Warnf("Error trying to set test key: %s", err.Error())
 		return false
@@ -148,6 +147,9 @@ func ConnectToRedis(ctx context.Context, config *Config) {
 type RedisCluster struct {
 	KeyPrefix string
 	HashKeys  bool
+
+	// ctx is the context the commands are executed with, see WithContext.
+	ctx context.Context
 }
 
 func getRedisAddrs(config *Config) (addrs []string) {
@@ -210,6 +212,8 @@ func NewRedisClusterPool(config *Config) redis.UniversalClient {
 		client = redis.NewClient(opts.Simple())
 	}
 
+	client.AddHook(newTracingHook())
+
 	return client
 }
 
@@ -218,8 +222,29 @@ func (r *RedisCluster) Connect() bool {
 	return true
 }
 
+// WithContext returns a copy of the storage manager which executes the commands with ctx,
+// so that they are traced as part of the trace of ctx.
+func (r *RedisCluster) WithContext(ctx context.Context) *RedisCluster {
+	rc := *r
+	rc.ctx = ctx
+
+	return &rc
+}
+
 func (r *RedisCluster) singleton() redis.UniversalClient {
-	return singleton()
+	client := singleton()
+	if r.ctx == nil {
+		return client
+	}
+
+	switch c := client.(type) {
+	case *redis.Client:
+		return c.WithContext(r.ctx)
+	case *redis.ClusterClient:
+		return c.WithContext(r.ctx)
+	default:
+		return client
+	}
 }
 
 func (r *RedisCluster) hashKey(in string) string {
@@ -829,6 +854,16 @@ func (r *RedisCluster) AppendToSet(keyName, value string) {
 	}
 }
 
+// AppendToList append a value to the list keyName, like AppendToSet, but returns the error.
+func (r *RedisCluster) AppendToList(keyName, value string) error {
+	fixedKey := r.fixKey(keyName)
+	if err := r.up(); err != nil {
+		return err
+	}
+
+	return r.singleton().RPush(fixedKey, value).Err()
+}
+
 // Exists check if keyName exists.
 func (r *RedisCluster) Exists(keyName string) (bool, error) {
 	fixedKey := r.fixKey(keyName)
@@ -1120,6 +1155,62 @@ func (r RedisCluster) GetRollingWindow(keyName string, per int64, pipeline bool)
 	return intVal, result
 }
 
+// takeRollingWindowScript drops the values which left the window and adds a value when
+// less than the limit are left, atomically. It returns the number of values, whether
+// the value was added and the score of the oldest value.
+var takeRollingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local per = tonumber(ARGV[2])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - per)
+
+local count = redis.call('ZCARD', KEYS[1])
+local added = 0
+if count < tonumber(ARGV[3]) then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	redis.call('PEXPIRE', KEYS[1], math.ceil(per / 1000000))
+	count = count + 1
+	added = 1
+end
+
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+return {count, added, oldest[2] or tostring(now)}
+`)
+
+// TakeRollingWindow adds a value to a timed window of values in a sorted set if it holds
+// less than limit values, the check and the add are atomic. It returns the number of
+// values in the window, including the added one, the time of the oldest one and whether
+// the value was added.
+func (r *RedisCluster) TakeRollingWindow(keyName string, per time.Duration, limit int) (int, time.Time, bool, error) {
+	if err := r.up(); err != nil {
+		return 0, time.Time{}, false, err
+	}
+
+	now := time.Now()
+	member := strconv.FormatInt(now.UnixNano(), 10) + "-" + uuid.NewV4().String()
+
+	res, err := takeRollingWindowScript.Run(r.singleton(), []string{keyName},
+		now.UnixNano(), per.Nanoseconds(), limit, member).Result()
+	if err != nil {
+		return 0, time.Time{}, false, err
+	}
+
+	values, ok := res.([]interface{})
+	if !ok || len(values) != 3 {
+		return 0, time.Time{}, false, fmt.Errorf("unexpected rolling window result %v", res)
+	}
+
+	count, _ := values[0].(int64)
+	added, _ := values[1].(int64)
+	score, _ := values[2].(string)
+
+	oldest, err := strconv.ParseFloat(score, 64)
+	if err != nil {
+		return 0, time.Time{}, false, fmt.Errorf("invalid rolling window score %q", score)
+	}
+
+	return int(count), time.Unix(0, int64(oldest)), added == 1, nil
+}
+
 // GetKeyPrefix returns storage key prefix.
 func (r *RedisCluster) GetKeyPrefix() string {
 	return r.KeyPrefix
@@ -70,8 +70,7 @@ const defaultHashAlgorithm = "murmur64"
 // GenerateToken generate token, if hashing algorithm is empty, use legacy key generation.
 func GenerateToken(orgID, keyID, hashAlgorithm string) (string, error) {
 	if keyID == "" {
-		uuidNew, _ := uuid.NewV4()
-		keyID = strings.ReplaceAll(uuid.Must(uuidNew, nil).String(), "-", "")
+		keyID = strings.ReplaceAll(uuid.NewV4().String(), "-", "")
 	}
 
 	if hashAlgorithm != "" {
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-redis/redis/v7"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/rose839/IAM/pkg/storage"
+
+type spanKey struct{}
+
+// tracingHook is a redis hook which starts a client span for every command and pipeline
+// executed with a context of a trace, see RedisCluster.WithContext. Commands outside of
+// a trace, like the connection checks, are not traced.
+type tracingHook struct {
+	tracer trace.Tracer
+}
+
+var _ redis.Hook = &tracingHook{}
+
+func newTracingHook() *tracingHook {
+	return &tracingHook{tracer: otel.Tracer(tracerName)}
+}
+
+func (h *tracingHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return h.start(ctx, "redis."+cmd.Name(), cmd.Name()), nil
+}
+
+func (h *tracingHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	h.end(ctx, cmd.Err())
+
+	return nil
+}
+
+func (h *tracingHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	names := make([]string, 0, len(cmds))
+	for _, cmd := range cmds {
+		names = append(names, cmd.Name())
+	}
+
+	return h.start(ctx, "redis.pipeline", strings.Join(names, " ")), nil
+}
+
+func (h *tracingHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if cmd.Err() != nil && cmd.Err() != redis.Nil {
+			err = cmd.Err()
+
+			break
+		}
+	}
+	h.end(ctx, err)
+
+	return nil
+}
+
+func (h *tracingHook) start(ctx context.Context, name, operation string) context.Context {
+	if ctx == nil || !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+
+	ctx, span := h.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemRedis, semconv.DBOperationKey.String(operation)),
+	)
+
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+func (h *tracingHook) end(ctx context.Context, err error) {
+	span, ok := ctx.Value(spanKey{}).(trace.Span)
+	if !ok {
+		return
+	}
+
+	if err != nil && err != redis.Nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
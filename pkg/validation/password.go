@@ -0,0 +1,169 @@
+package validation
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rose839/IAM/pkg/sets"
+)
+
+const (
+	minPassLength = 8
+	maxPassLength = 16
+)
+
+// PasswordPolicy defines the rules a password must satisfy.
+type PasswordPolicy struct {
+	// MinLength and MaxLength bound the number of characters of a password.
+	MinLength int
+	MaxLength int
+
+	RequireUpper   bool
+	RequireLower   bool
+	RequireNumber  bool
+	RequireSpecial bool
+
+	// DisallowUsername rejects passwords containing the username, case insensitive.
+	DisallowUsername bool
+
+	// Denylist holds lower cased common passwords which are rejected.
+	Denylist sets.String
+
+	// HistorySize is the number of previous passwords which can not be reused and
+	// MaxAge is how long a password stays valid. Both are enforced by the caller,
+	// zero disables them.
+	HistorySize int
+	MaxAge      time.Duration
+}
+
+// DefaultPasswordPolicy returns the password policy used when none is configured.
+func DefaultPasswordPolicy() *PasswordPolicy {
+	return &PasswordPolicy{
+		MinLength:        minPassLength,
+		MaxLength:        maxPassLength,
+		RequireUpper:     true,
+		RequireLower:     true,
+		RequireNumber:    true,
+		RequireSpecial:   true,
+		DisallowUsername: true,
+		Denylist:         sets.NewString(),
+	}
+}
+
+var passwordPolicy atomic.Pointer[PasswordPolicy]
+
+// SetPasswordPolicy sets the password policy used by IsValidPassword and IsValidUserPassword.
+func SetPasswordPolicy(policy *PasswordPolicy) {
+	passwordPolicy.Store(policy)
+}
+
+// GetPasswordPolicy returns the current password policy.
+func GetPasswordPolicy() *PasswordPolicy {
+	if policy := passwordPolicy.Load(); policy != nil {
+		return policy
+	}
+
+	return DefaultPasswordPolicy()
+}
+
+// IsValidPassword validate password with the current password policy.
+func IsValidPassword(password string) error {
+	return GetPasswordPolicy().Validate("", password)
+}
+
+// IsValidUserPassword validate password of the given user with the current password policy.
+func IsValidUserPassword(username, password string) error {
+	return GetPasswordPolicy().Validate(username, password)
+}
+
+// Validate checks password of the given user against the policy, username may be empty.
+func (p *PasswordPolicy) Validate(username, password string) error {
+	var hasUpper bool
+	var hasLower bool
+	var hasNumber bool
+	var hasSpecial bool
+	var errorString string
+
+	for _, ch := range password {
+		switch {
+		case unicode.IsNumber(ch):
+			hasNumber = true
+		case unicode.IsUpper(ch):
+			hasUpper = true
+		case unicode.IsLower(ch):
+			hasLower = true
+		case unicode.IsPunct(ch) || unicode.IsSymbol(ch):
+			hasSpecial = true
+		}
+	}
+
+	appendError := func(err string) {
+		if len(strings.TrimSpace(errorString)) != 0 {
+			errorString += ", " + err
+		} else {
+			errorString = err
+		}
+	}
+	if p.RequireLower && !hasLower {
+		appendError("lowercase letter missing")
+	}
+	if p.RequireUpper && !hasUpper {
+		appendError("uppercase letter missing")
+	}
+	if p.RequireNumber && !hasNumber {
+		appendError("at least one numeric character required")
+	}
+	if p.RequireSpecial && !hasSpecial {
+		appendError("special character missing")
+	}
+	if passLen := utf8.RuneCountInString(password); !(p.MinLength <= passLen && passLen <= p.MaxLength) {
+		appendError(
+			fmt.Sprintf("password length must be between %d to %d characters long", p.MinLength, p.MaxLength),
+		)
+	}
+	if p.DisallowUsername && username != "" && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		appendError("password must not contain the username")
+	}
+	if p.Denylist.Has(strings.ToLower(password)) {
+		appendError("password is too common")
+	}
+
+	if len(errorString) != 0 {
+		return fmt.Errorf(errorString)
+	}
+
+	return nil
+}
+
+// LoadPasswordDenylist reads common passwords from file, one password per line.
+// Empty lines and lines starting with '#' are ignored.
+func LoadPasswordDenylist(file string) (sets.String, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	denylist := sets.NewString()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		denylist.Insert(strings.ToLower(line))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return denylist, nil
+}
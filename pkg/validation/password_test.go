@@ -0,0 +1,37 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/rose839/IAM/pkg/sets"
+)
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	policy := DefaultPasswordPolicy()
+	policy.Denylist = sets.NewString("passw0rd!abc")
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantErr  bool
+	}{
+		{"valid", "colin", "Secret@1234", false},
+		{"too short", "colin", "Se@1", true},
+		{"too long", "colin", "Secret@1234567890", true},
+		{"missing uppercase", "colin", "secret@1234", true},
+		{"missing lowercase", "colin", "SECRET@1234", true},
+		{"missing number", "colin", "Secret@abcd", true},
+		{"missing special", "colin", "Secret1234", true},
+		{"contains username", "colin", "Colin@1234", true},
+		{"empty username", "", "Colin@1234", false},
+		{"denylisted", "colin", "Passw0rd!abc", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := policy.Validate(tt.username, tt.password); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -20,8 +20,8 @@ source "${env_file}"
 # Disable undefined variable reporting errors.
 set +u
 
-# check whether some config was not set.
-for env in $(sed -n 's/^[^#].*${\(.*\)}.*/\1/p' ${template_file}); do
+# check whether some config was not set, commented out configs are not required.
+for env in $(sed -n 's/^[[:space:]]*[^#[:space:]].*${\(.*\)}.*/\1/p' ${template_file}); do
     if [ -z "$(eval echo \$${env})" ]; then
         iam::log::error "environment variable '${env}' not set"
         missing=true
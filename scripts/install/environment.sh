@@ -42,6 +42,9 @@ readonly CA_FILE=${CA_FILE:-${IAM_CONFIG_DIR}/cert/ca.pem} # CA
 readonly IAM_APISERVER_HOST=${IAM_APISERVER_HOST:-127.0.0.1} # iam-apiserver deployment machine IP address
 readonly IAM_APISERVER_GRPC_BIND_ADDRESS=${IAM_APISERVER_GRPC_BIND_ADDRESS:-0.0.0.0}
 readonly IAM_APISERVER_GRPC_BIND_PORT=${IAM_APISERVER_GRPC_BIND_PORT:-8081}
+# bearer token iam-authz-server calls the grpc services with, at least 16 characters. There is no
+# default, genconfig.sh fails when it is not set. Generate one with: export IAM_AUTHZ_SERVER_GRPC_TOKEN=$(openssl rand -hex 32)
+readonly IAM_AUTHZ_SERVER_GRPC_TOKEN=${IAM_AUTHZ_SERVER_GRPC_TOKEN:-}
 readonly IAM_APISERVER_INSECURE_BIND_ADDRESS=${IAM_APISERVER_INSECURE_BIND_ADDRESS:-127.0.0.1}
 readonly IAM_APISERVER_INSECURE_BIND_PORT=${IAM_APISERVER_INSECURE_BIND_PORT:-8080}
 readonly IAM_APISERVER_SECURE_BIND_ADDRESS=${IAM_APISERVER_SECURE_BIND_ADDRESS:-0.0.0.0}